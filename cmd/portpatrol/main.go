@@ -36,7 +36,7 @@ func run(ctx context.Context, getEnv func(string) string, output io.Writer) erro
 		return fmt.Errorf("failed to initialize checker: %w", err)
 	}
 
-	return runner.LoopUntilReady(ctx, cfg.CheckInterval, targetChecker, logger)
+	return runner.LoopUntilReady(ctx, cfg.CheckInterval, cfg.TraceAfter, targetChecker, logger)
 }
 
 func main() {
@@ -0,0 +1,350 @@
+package checker
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+const (
+	envAMQPVHost        string = "AMQP_VHOST"
+	envAMQPUsernameFile string = "AMQP_USERNAME_FILE"
+	envAMQPPasswordFile string = "AMQP_PASSWORD_FILE"
+
+	defaultAMQPVHost    string = "/"
+	defaultAMQPUsername string = "guest"
+	defaultAMQPPassword string = "guest"
+
+	amqpFrameMethod    byte = 1
+	amqpFrameHeartbeat byte = 8
+	amqpFrameEnd       byte = 0xce
+	amqpMaxFrameSize   int  = 1 << 20 // The maximum accepted frame size (1 MiB).
+
+	amqpClassConnection   uint16 = 10
+	amqpMethodStart       uint16 = 10
+	amqpMethodStartOk     uint16 = 11
+	amqpMethodTune        uint16 = 30
+	amqpMethodTuneOk      uint16 = 31
+	amqpMethodOpen        uint16 = 40
+	amqpMethodOpenOk      uint16 = 41
+	amqpMethodClose       uint16 = 50
+	amqpMethodCloseOk     uint16 = 51
+	amqpReplySuccess      uint16 = 200
+	amqpMechanismPlain    string = "PLAIN"
+	amqpDefaultLocale     string = "en_US"
+	amqpClientProductName string = "portpatrol"
+)
+
+// amqpProtocolHeader is sent by the client to start an AMQP 0-9-1 connection.
+var amqpProtocolHeader = []byte{'A', 'M', 'Q', 'P', 0, 0, 9, 1}
+
+// AMQPChecker implements the Checker interface for AMQP 0-9-1 (e.g. RabbitMQ) checks.
+type AMQPChecker struct {
+	Name         string        // The name of the checker.
+	Address      string        // The address of the target.
+	VHost        string        // The virtual host to open.
+	UsernameFile string        // The file containing the username. If empty, the default username is used.
+	PasswordFile string        // The file containing the password. If empty, the default password is used.
+	dialer       contextDialer // The dialer to use for the connection.
+	timeout      time.Duration // The timeout for the whole exchange with the server.
+}
+
+// String returns the name of the checker.
+func (c *AMQPChecker) String() string {
+	return c.Name
+}
+
+// NewAMQPChecker creates a new AMQPChecker.
+func NewAMQPChecker(name, address string, timeout time.Duration, getEnv func(string) string) (Checker, error) {
+	// The "amqp://" prefix is used to identify the check type and is not needed for further processing,
+	// so it must be removed before passing the address to other functions.
+	address = strings.TrimPrefix(address, "amqp://")
+
+	// Connections originate from the configured source address and interface and go through the configured proxy
+	dialer, err := newProxyDialer(timeout, getEnv)
+	if err != nil {
+		return nil, err
+	}
+
+	checker := AMQPChecker{
+		Name:         name,
+		Address:      address,
+		VHost:        defaultAMQPVHost,
+		UsernameFile: getEnv(envAMQPUsernameFile),
+		PasswordFile: getEnv(envAMQPPasswordFile),
+		dialer:       dialer,
+		timeout:      timeout,
+	}
+
+	// Override the default virtual host if specified
+	if vhost := getEnv(envAMQPVHost); vhost != "" {
+		checker.VHost = vhost
+	}
+
+	return &checker, nil
+}
+
+// Check performs the AMQP 0-9-1 connection handshake and opens the configured virtual host.
+func (c *AMQPChecker) Check(ctx context.Context) error {
+	// Read the credentials on every check, so rotated or late-mounted secrets are picked up
+	username, password := defaultAMQPUsername, defaultAMQPPassword
+	if c.UsernameFile != "" {
+		u, err := readCredentialFile(c.UsernameFile)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", envAMQPUsernameFile, err)
+		}
+		username = u
+	}
+	if c.PasswordFile != "" {
+		p, err := readCredentialFile(c.PasswordFile)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", envAMQPPasswordFile, err)
+		}
+		password = p
+	}
+
+	conn, err := c.dialer.DialContext(ctx, "tcp", c.Address)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// The broker must complete the handshake within the timeout
+	stop, err := deadlineConn(ctx, conn, c.timeout)
+	if err != nil {
+		return err
+	}
+	defer stop()
+
+	if _, err := conn.Write(amqpProtocolHeader); err != nil {
+		return fmt.Errorf("failed to send protocol header: %w", err)
+	}
+
+	// Connection.Start
+	args, err := amqpExpectMethod(conn, amqpMethodStart)
+	if err != nil {
+		return err
+	}
+	if err := amqpValidateStart(args); err != nil {
+		return err
+	}
+
+	// Connection.Start-Ok
+	startOk := &amqpWriter{}
+	startOk.table(map[string]string{"product": amqpClientProductName})
+	startOk.shortString(amqpMechanismPlain)
+	startOk.longString("\x00" + username + "\x00" + password)
+	startOk.shortString(amqpDefaultLocale)
+	if err := amqpWriteMethod(conn, amqpMethodStartOk, startOk.buf); err != nil {
+		return err
+	}
+
+	// Connection.Tune
+	args, err = amqpExpectMethod(conn, amqpMethodTune)
+	if err != nil {
+		return err
+	}
+	if len(args) < 8 {
+		return fmt.Errorf("malformed Connection.Tune method")
+	}
+
+	// Connection.Tune-Ok accepts the server limits and disables heartbeats
+	tuneOk := &amqpWriter{buf: append([]byte(nil), args[:6]...)}
+	tuneOk.uint16(0)
+	if err := amqpWriteMethod(conn, amqpMethodTuneOk, tuneOk.buf); err != nil {
+		return err
+	}
+
+	// Connection.Open
+	open := &amqpWriter{}
+	open.shortString(c.VHost)
+	open.shortString("") // reserved-1
+	open.buf = append(open.buf, 0)
+	if err := amqpWriteMethod(conn, amqpMethodOpen, open.buf); err != nil {
+		return err
+	}
+
+	if _, err := amqpExpectMethod(conn, amqpMethodOpenOk); err != nil {
+		return fmt.Errorf("failed to open virtual host %q: %w", c.VHost, err)
+	}
+
+	// Close the connection gracefully; failures at this point do not affect readiness
+	closeMsg := &amqpWriter{}
+	closeMsg.uint16(amqpReplySuccess)
+	closeMsg.shortString("portpatrol check done")
+	closeMsg.uint16(0)
+	closeMsg.uint16(0)
+	if err := amqpWriteMethod(conn, amqpMethodClose, closeMsg.buf); err == nil {
+		_, _ = amqpExpectMethod(conn, amqpMethodCloseOk)
+	}
+
+	return nil
+}
+
+// amqpValidateStart checks that the server speaks AMQP 0-9-1 and supports the PLAIN mechanism.
+func amqpValidateStart(args []byte) error {
+	if len(args) < 2 || args[0] != 0 || args[1] != 9 {
+		return fmt.Errorf("unsupported AMQP version in Connection.Start")
+	}
+
+	r := bytes.NewReader(args[2:])
+	if _, err := amqpReadLongString(r); err != nil { // server-properties
+		return fmt.Errorf("malformed Connection.Start method: %w", err)
+	}
+	mechanisms, err := amqpReadLongString(r)
+	if err != nil {
+		return fmt.Errorf("malformed Connection.Start method: %w", err)
+	}
+
+	for _, mechanism := range strings.Fields(string(mechanisms)) {
+		if mechanism == amqpMechanismPlain {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("server does not support the %s mechanism (offered: %s)", amqpMechanismPlain, mechanisms)
+}
+
+// amqpExpectMethod reads frames until a method frame arrives and ensures it is the expected
+// Connection method. A Connection.Close sent by the server is turned into an error containing its reply.
+func amqpExpectMethod(conn net.Conn, method uint16) ([]byte, error) {
+	for {
+		frameType, payload, err := amqpReadFrame(conn)
+		if err != nil {
+			return nil, err
+		}
+		if frameType == amqpFrameHeartbeat {
+			continue
+		}
+		if frameType != amqpFrameMethod || len(payload) < 4 {
+			return nil, fmt.Errorf("unexpected AMQP frame type %d", frameType)
+		}
+
+		classID := binary.BigEndian.Uint16(payload[0:2])
+		methodID := binary.BigEndian.Uint16(payload[2:4])
+		args := payload[4:]
+
+		if classID == amqpClassConnection && methodID == amqpMethodClose && method != amqpMethodClose {
+			return nil, amqpCloseError(args)
+		}
+		if classID != amqpClassConnection || methodID != method {
+			return nil, fmt.Errorf("unexpected AMQP method %d.%d, expected %d.%d", classID, methodID, amqpClassConnection, method)
+		}
+
+		return args, nil
+	}
+}
+
+// amqpCloseError converts the arguments of a Connection.Close method into an error.
+func amqpCloseError(args []byte) error {
+	if len(args) < 3 {
+		return fmt.Errorf("connection closed by server")
+	}
+
+	code := binary.BigEndian.Uint16(args[0:2])
+	textLen := int(args[2])
+	text := ""
+	if len(args) >= 3+textLen {
+		text = string(args[3 : 3+textLen])
+	}
+
+	return fmt.Errorf("connection closed by server: %d %s", code, text)
+}
+
+// amqpReadFrame reads a single AMQP frame and returns its type and payload.
+func amqpReadFrame(conn net.Conn) (byte, []byte, error) {
+	header := make([]byte, 7)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return 0, nil, fmt.Errorf("failed to read AMQP frame: %w", err)
+	}
+
+	// The server answers with its own protocol header if it does not support the requested version
+	if bytes.HasPrefix(header, []byte("AMQP")) {
+		return 0, nil, fmt.Errorf("server does not support AMQP 0-9-1")
+	}
+
+	size := int(binary.BigEndian.Uint32(header[3:7]))
+	if size > amqpMaxFrameSize {
+		return 0, nil, fmt.Errorf("AMQP frame too large: %d bytes", size)
+	}
+
+	payload := make([]byte, size+1) // payload followed by the frame-end octet
+	if _, err := io.ReadFull(conn, payload); err != nil {
+		return 0, nil, fmt.Errorf("failed to read AMQP frame: %w", err)
+	}
+	if payload[size] != amqpFrameEnd {
+		return 0, nil, fmt.Errorf("invalid AMQP frame end")
+	}
+
+	return header[0], payload[:size], nil
+}
+
+// amqpWriteMethod writes a Connection method frame on channel 0.
+func amqpWriteMethod(conn net.Conn, method uint16, args []byte) error {
+	payload := &amqpWriter{}
+	payload.uint16(amqpClassConnection)
+	payload.uint16(method)
+	payload.buf = append(payload.buf, args...)
+
+	frame := []byte{amqpFrameMethod, 0, 0}
+	frame = binary.BigEndian.AppendUint32(frame, uint32(len(payload.buf)))
+	frame = append(frame, payload.buf...)
+	frame = append(frame, amqpFrameEnd)
+
+	if _, err := conn.Write(frame); err != nil {
+		return fmt.Errorf("failed to write AMQP frame: %w", err)
+	}
+
+	return nil
+}
+
+// amqpReadLongString reads a long string (32-bit length prefix). Field tables use the same encoding.
+func amqpReadLongString(r *bytes.Reader) ([]byte, error) {
+	var size uint32
+	if err := binary.Read(r, binary.BigEndian, &size); err != nil {
+		return nil, err
+	}
+	if int64(size) > int64(r.Len()) {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	data := make([]byte, size)
+	_, err := io.ReadFull(r, data)
+	return data, err
+}
+
+// amqpWriter encodes AMQP 0-9-1 method arguments.
+type amqpWriter struct {
+	buf []byte
+}
+
+func (w *amqpWriter) uint16(v uint16) {
+	w.buf = binary.BigEndian.AppendUint16(w.buf, v)
+}
+
+func (w *amqpWriter) shortString(v string) {
+	w.buf = append(w.buf, byte(len(v)))
+	w.buf = append(w.buf, v...)
+}
+
+func (w *amqpWriter) longString(v string) {
+	w.buf = binary.BigEndian.AppendUint32(w.buf, uint32(len(v)))
+	w.buf = append(w.buf, v...)
+}
+
+// table encodes a field table with long string values.
+func (w *amqpWriter) table(fields map[string]string) {
+	entries := &amqpWriter{}
+	for key, value := range fields {
+		entries.shortString(key)
+		entries.buf = append(entries.buf, 'S')
+		entries.longString(value)
+	}
+	w.buf = binary.BigEndian.AppendUint32(w.buf, uint32(len(entries.buf)))
+	w.buf = append(w.buf, entries.buf...)
+}
@@ -0,0 +1,378 @@
+package checker
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeAMQPServer is a minimal AMQP 0-9-1 server which accepts a single user and virtual host.
+type fakeAMQPServer struct {
+	username   string
+	password   string
+	vhost      string
+	mechanisms string
+}
+
+// readMethod reads a method frame from the client and returns its method ID and arguments.
+func (s *fakeAMQPServer) readMethod(conn net.Conn) (uint16, []byte, error) {
+	frameType, payload, err := amqpReadFrame(conn)
+	if err != nil {
+		return 0, nil, err
+	}
+	if frameType != amqpFrameMethod {
+		return 0, nil, fmt.Errorf("unexpected frame type %d", frameType)
+	}
+	return binary.BigEndian.Uint16(payload[2:4]), payload[4:], nil
+}
+
+// closeWith sends a Connection.Close with the given reply.
+func (s *fakeAMQPServer) closeWith(conn net.Conn, code uint16, text string) {
+	w := &amqpWriter{}
+	w.uint16(code)
+	w.shortString(text)
+	w.uint16(0)
+	w.uint16(0)
+	_ = amqpWriteMethod(conn, amqpMethodClose, w.buf)
+}
+
+func (s *fakeAMQPServer) serve(conn net.Conn) {
+	defer conn.Close()
+
+	header := make([]byte, 8)
+	if _, err := io.ReadFull(conn, header); err != nil || !bytes.Equal(header, amqpProtocolHeader) {
+		_, _ = conn.Write([]byte{'A', 'M', 'Q', 'P', 0, 0, 9, 1})
+		return
+	}
+
+	start := &amqpWriter{buf: []byte{0, 9}}
+	start.table(map[string]string{"product": "fake"})
+	start.longString(s.mechanisms)
+	start.longString("en_US")
+	if err := amqpWriteMethod(conn, amqpMethodStart, start.buf); err != nil {
+		return
+	}
+
+	method, args, err := s.readMethod(conn)
+	if err != nil || method != amqpMethodStartOk {
+		return
+	}
+	r := bytes.NewReader(args)
+	if _, err := amqpReadLongString(r); err != nil { // client-properties
+		return
+	}
+	mechanismLen, _ := r.ReadByte()
+	_, _ = r.Seek(int64(mechanismLen), io.SeekCurrent)
+	response, err := amqpReadLongString(r)
+	if err != nil {
+		return
+	}
+	if string(response) != "\x00"+s.username+"\x00"+s.password {
+		s.closeWith(conn, 403, "ACCESS_REFUSED - Login was refused")
+		return
+	}
+
+	// Heartbeats may arrive at any time and must be ignored by the client
+	_, _ = conn.Write([]byte{amqpFrameHeartbeat, 0, 0, 0, 0, 0, 0, amqpFrameEnd})
+
+	tune := &amqpWriter{}
+	tune.uint16(2047)
+	tune.buf = binary.BigEndian.AppendUint32(tune.buf, 131072)
+	tune.uint16(60)
+	if err := amqpWriteMethod(conn, amqpMethodTune, tune.buf); err != nil {
+		return
+	}
+
+	if method, _, err := s.readMethod(conn); err != nil || method != amqpMethodTuneOk {
+		return
+	}
+
+	method, args, err = s.readMethod(conn)
+	if err != nil || method != amqpMethodOpen {
+		return
+	}
+	if vhost := string(args[1 : 1+int(args[0])]); vhost != s.vhost {
+		s.closeWith(conn, 530, fmt.Sprintf("NOT_ALLOWED - vhost %s not found", vhost))
+		return
+	}
+
+	if err := amqpWriteMethod(conn, amqpMethodOpenOk, []byte{0}); err != nil {
+		return
+	}
+
+	if method, _, err := s.readMethod(conn); err == nil && method == amqpMethodClose {
+		_ = amqpWriteMethod(conn, amqpMethodCloseOk, nil)
+	}
+}
+
+// startFakeAMQPServer starts the fake AMQP server on a random port.
+func startFakeAMQPServer(t *testing.T, server *fakeAMQPServer) net.Listener {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to start fake AMQP server: %q", err)
+	}
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn)
+		}
+	}()
+
+	return ln
+}
+
+// writeCredential writes a credential file with a trailing newline and returns its path.
+func writeCredential(t *testing.T, name, value string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(value+"\n"), 0o600); err != nil {
+		t.Fatalf("failed to write credential file: %q", err)
+	}
+
+	return path
+}
+
+func TestNewAMQPChecker(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Valid AMQP checker config", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envAMQPVHost:        "orders",
+				envAMQPUsernameFile: "/secrets/username",
+				envAMQPPasswordFile: "/secrets/password",
+			}
+			return env[key]
+		}
+
+		checker, err := NewAMQPChecker("example", "amqp://rabbitmq:5672", 1*time.Second, mockEnv)
+		if err != nil {
+			t.Fatalf("failed to create AMQPChecker: %q", err)
+		}
+
+		amqpChecker := checker.(*AMQPChecker)
+
+		expected := "rabbitmq:5672"
+		if amqpChecker.Address != expected {
+			t.Errorf("expected Address to be %q, got %q", expected, amqpChecker.Address)
+		}
+
+		expected = "orders"
+		if amqpChecker.VHost != expected {
+			t.Errorf("expected VHost to be %q, got %q", expected, amqpChecker.VHost)
+		}
+
+		expected = "/secrets/username"
+		if amqpChecker.UsernameFile != expected {
+			t.Errorf("expected UsernameFile to be %q, got %q", expected, amqpChecker.UsernameFile)
+		}
+
+		expected = "/secrets/password"
+		if amqpChecker.PasswordFile != expected {
+			t.Errorf("expected PasswordFile to be %q, got %q", expected, amqpChecker.PasswordFile)
+		}
+	})
+
+	t.Run("Default virtual host", func(t *testing.T) {
+		t.Parallel()
+
+		checker, err := NewAMQPChecker("example", "rabbitmq:5672", 1*time.Second, func(string) string { return "" })
+		if err != nil {
+			t.Fatalf("failed to create AMQPChecker: %q", err)
+		}
+
+		if checker.(*AMQPChecker).VHost != defaultAMQPVHost {
+			t.Errorf("expected VHost to be %q, got %q", defaultAMQPVHost, checker.(*AMQPChecker).VHost)
+		}
+	})
+}
+
+func TestAMQPChecker(t *testing.T) {
+	t.Parallel()
+
+	server := &fakeAMQPServer{username: "app", password: "s3cr3t", vhost: "orders", mechanisms: "AMQPLAIN PLAIN"}
+	ln := startFakeAMQPServer(t, server)
+	t.Cleanup(func() { ln.Close() })
+
+	usernameFile := writeCredential(t, "username", "app")
+	passwordFile := writeCredential(t, "password", "s3cr3t")
+
+	t.Run("Valid AMQP check", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envAMQPVHost:        "orders",
+				envAMQPUsernameFile: usernameFile,
+				envAMQPPasswordFile: passwordFile,
+			}
+			return env[key]
+		}
+
+		checker, err := NewAMQPChecker("example", ln.Addr().String(), 1*time.Second, mockEnv)
+		if err != nil {
+			t.Fatalf("failed to create AMQPChecker: %q", err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+
+		if err := checker.Check(ctx); err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+	})
+
+	t.Run("Invalid credentials", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envAMQPVHost: "orders",
+			}
+			return env[key]
+		}
+
+		checker, err := NewAMQPChecker("example", ln.Addr().String(), 1*time.Second, mockEnv)
+		if err != nil {
+			t.Fatalf("failed to create AMQPChecker: %q", err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+
+		err = checker.Check(ctx)
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := "connection closed by server: 403 ACCESS_REFUSED - Login was refused"
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Unknown virtual host", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envAMQPVHost:        "missing",
+				envAMQPUsernameFile: usernameFile,
+				envAMQPPasswordFile: passwordFile,
+			}
+			return env[key]
+		}
+
+		checker, err := NewAMQPChecker("example", ln.Addr().String(), 1*time.Second, mockEnv)
+		if err != nil {
+			t.Fatalf("failed to create AMQPChecker: %q", err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+
+		err = checker.Check(ctx)
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := `failed to open virtual host "missing": connection closed by server: 530 NOT_ALLOWED - vhost missing not found`
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Missing password file", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envAMQPPasswordFile: "/does/not/exist",
+			}
+			return env[key]
+		}
+
+		checker, err := NewAMQPChecker("example", ln.Addr().String(), 1*time.Second, mockEnv)
+		if err != nil {
+			t.Fatalf("failed to create AMQPChecker: %q", err)
+		}
+
+		err = checker.Check(context.Background())
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := fmt.Sprintf("failed to read %s: failed to read credential file: open /does/not/exist: no such file or directory", envAMQPPasswordFile)
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("PLAIN mechanism not supported", func(t *testing.T) {
+		t.Parallel()
+
+		server := &fakeAMQPServer{mechanisms: "EXTERNAL"}
+		ln := startFakeAMQPServer(t, server)
+		defer ln.Close()
+
+		checker, err := NewAMQPChecker("example", ln.Addr().String(), 1*time.Second, func(string) string { return "" })
+		if err != nil {
+			t.Fatalf("failed to create AMQPChecker: %q", err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+
+		err = checker.Check(ctx)
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := "server does not support the PLAIN mechanism (offered: EXTERNAL)"
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Not an AMQP server", func(t *testing.T) {
+		t.Parallel()
+
+		ln := startTCPLineServer(t, "SSH-2.0-OpenSSH_9.6\r\n", "")
+		defer ln.Close()
+
+		checker, err := NewAMQPChecker("example", ln.Addr().String(), 1*time.Second, func(string) string { return "" })
+		if err != nil {
+			t.Fatalf("failed to create AMQPChecker: %q", err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+		defer cancel()
+
+		err = checker.Check(ctx)
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := "AMQP frame"
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected error containing %q, got %q", expected, err.Error())
+		}
+	})
+}
@@ -3,6 +3,7 @@ package checker
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"strings"
 	"time"
 )
@@ -11,14 +12,30 @@ import (
 type CheckType int
 
 const (
-	TCP  CheckType = iota // TCP represents a check over the TCP protocol.
-	HTTP                  // HTTP represents a check over the HTTP protocol.
-	ICMP                  // ICMP represents a check using the ICMP protocol (ping).
+	TCP       CheckType = iota // TCP represents a check over the TCP protocol.
+	HTTP                       // HTTP represents a check over the HTTP protocol.
+	ICMP                       // ICMP represents a check using the ICMP protocol (ping).
+	UDP                        // UDP represents a request/response check over the UDP protocol.
+	TLS                        // TLS represents a TLS handshake and certificate validity check.
+	KAFKA                      // KAFKA represents a Kafka broker readiness check.
+	AMQP                       // AMQP represents an AMQP 0-9-1 (e.g. RabbitMQ) connection handshake check.
+	MONGODB                    // MONGODB represents a MongoDB check using the hello command.
+	UNIX                       // UNIX represents a check of a Unix domain socket, optionally speaking HTTP over it.
+	FILE                       // FILE represents a check that a path exists and optionally satisfies content conditions.
+	EXEC                       // EXEC represents a check running a command, which is ready if it exits with code 0.
+	K8S                        // K8S represents a check of the readiness of a Kubernetes resource.
+	WEBSOCKET                  // WEBSOCKET represents a check performing the WebSocket handshake.
+	SMTP                       // SMTP represents a check of an SMTP server, optionally upgrading the connection with STARTTLS.
+	LDAP                       // LDAP represents a check binding to an LDAP directory.
+	ETCD                       // ETCD represents a check of the health endpoint of an etcd member.
+	CONSUL                     // CONSUL represents a check verifying that a Consul cluster has elected a leader.
+	ZOOKEEPER                  // ZOOKEEPER represents a check using the four letter words of ZooKeeper.
+	NATS                       // NATS represents a check of a NATS server using CONNECT and PING.
 )
 
 // String returns the string representation of the CheckType.
 func (c CheckType) String() string {
-	return [...]string{"TCP", "HTTP", "ICMP"}[c]
+	return [...]string{"TCP", "HTTP", "ICMP", "UDP", "TLS", "KAFKA", "AMQP", "MONGODB", "UNIX", "FILE", "EXEC", "K8S", "WEBSOCKET", "SMTP", "LDAP", "ETCD", "CONSUL", "ZOOKEEPER", "NATS"}[c]
 }
 
 // Checker is an interface that defines methods to perform a check.
@@ -27,15 +44,57 @@ type Checker interface {
 	String() string                  // String returns the name of the checker.
 }
 
+// StatsReporter is implemented by checkers which collect statistics during a check, e.g. round-trip times.
+type StatsReporter interface {
+	Stats() []slog.Attr // Stats returns the statistics of the last check as log attributes.
+}
+
+// Tracer is implemented by checkers which can trace the network path to the target, e.g. to find where packets stop.
+type Tracer interface {
+	Trace(ctx context.Context) ([]Hop, error) // Trace probes the path to the target with increasing TTLs and returns the hops.
+}
+
 // Factory function that returns the appropriate Checker based on checkType
 func NewChecker(checkType CheckType, name, address string, timeout time.Duration, getEnv func(string) string) (Checker, error) {
 	switch checkType {
 	case HTTP: // HTTP and HTTPS checkers may need environment variables for proxy settings, etc.
 		return NewHTTPChecker(name, address, timeout, getEnv)
-	case TCP: // TCP checkers may not need environment variables
-		return NewTCPChecker(name, address, timeout)
+	case TCP: // TCP checkers may need environment variables for send/expect settings
+		return NewTCPChecker(name, address, timeout, getEnv)
 	case ICMP: // ICMP checkers may have a different timeout logic
 		return NewICMPChecker(name, address, timeout, getEnv)
+	case UDP: // UDP checkers need environment variables for the payload and expected reply
+		return NewUDPChecker(name, address, timeout, getEnv)
+	case TLS: // TLS checkers need environment variables for certificate validation
+		return NewTLSChecker(name, address, timeout, getEnv)
+	case KAFKA: // Kafka checkers may need environment variables for the topic to check
+		return NewKafkaChecker(name, address, timeout, getEnv)
+	case AMQP: // AMQP checkers need environment variables for the virtual host and credentials
+		return NewAMQPChecker(name, address, timeout, getEnv)
+	case MONGODB: // MongoDB checkers may need environment variables for replica set requirements
+		return NewMongoDBChecker(name, address, timeout, getEnv)
+	case UNIX: // Unix checkers may need environment variables to speak HTTP over the socket
+		return NewUnixChecker(name, address, timeout, getEnv)
+	case FILE: // File checkers may need environment variables for content settings
+		return NewFileChecker(name, address, timeout, getEnv)
+	case EXEC: // Exec checkers may need environment variables for shell settings
+		return NewExecChecker(name, address, timeout, getEnv)
+	case K8S: // Kubernetes checkers need environment variables for the API server and credentials
+		return NewK8sChecker(name, address, timeout, getEnv)
+	case WEBSOCKET: // WebSocket checkers may need environment variables for headers and messages
+		return NewWebSocketChecker(name, address, timeout, getEnv)
+	case SMTP: // SMTP checkers may need environment variables for STARTTLS and certificate validation
+		return NewSMTPChecker(name, address, timeout, getEnv)
+	case LDAP: // LDAP checkers may need environment variables for credentials and the base DN
+		return NewLDAPChecker(name, address, timeout, getEnv)
+	case ETCD: // etcd checkers may need environment variables for TLS and client certificates
+		return NewEtcdChecker(name, address, timeout, getEnv)
+	case CONSUL: // Consul checkers may need environment variables for TLS
+		return NewConsulChecker(name, address, timeout, getEnv)
+	case ZOOKEEPER: // ZooKeeper checkers may need environment variables for the accepted server modes
+		return NewZooKeeperChecker(name, address, timeout, getEnv)
+	case NATS: // NATS checkers may need environment variables for credentials, TLS and JetStream
+		return NewNATSChecker(name, address, timeout, getEnv)
 	default:
 		return nil, fmt.Errorf("unsupported check type: %d", checkType)
 	}
@@ -50,6 +109,38 @@ func GetCheckTypeFromString(checkTypeStr string) (CheckType, error) {
 		return TCP, nil
 	case "icmp":
 		return ICMP, nil
+	case "udp":
+		return UDP, nil
+	case "tls":
+		return TLS, nil
+	case "kafka":
+		return KAFKA, nil
+	case "amqp":
+		return AMQP, nil
+	case "mongodb":
+		return MONGODB, nil
+	case "unix":
+		return UNIX, nil
+	case "file":
+		return FILE, nil
+	case "exec":
+		return EXEC, nil
+	case "k8s":
+		return K8S, nil
+	case "ws", "wss":
+		return WEBSOCKET, nil
+	case "smtp", "smtps":
+		return SMTP, nil
+	case "ldap", "ldaps":
+		return LDAP, nil
+	case "etcd":
+		return ETCD, nil
+	case "consul":
+		return CONSUL, nil
+	case "zookeeper", "zk":
+		return ZOOKEEPER, nil
+	case "nats":
+		return NATS, nil
 	default:
 		return -1, fmt.Errorf("unsupported check type: %s", checkTypeStr)
 	}
@@ -56,17 +56,276 @@ func TestNewChecker(t *testing.T) {
 		}
 	})
 
+	t.Run("Valid UDP checker", func(t *testing.T) {
+		t.Parallel()
+
+		check, err := NewChecker(UDP, "example", "example.com:53", 5*time.Second, func(s string) string {
+			return ""
+		})
+		if err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+
+		expected := "example"
+		if check.String() != expected {
+			t.Fatalf("expected name to be %q got %q", expected, check.String())
+		}
+	})
+
+	t.Run("Valid TLS checker", func(t *testing.T) {
+		t.Parallel()
+
+		check, err := NewChecker(TLS, "example", "example.com:443", 5*time.Second, func(s string) string {
+			return ""
+		})
+		if err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+
+		expected := "example"
+		if check.String() != expected {
+			t.Fatalf("expected name to be %q got %q", expected, check.String())
+		}
+	})
+
+	t.Run("Valid Kafka checker", func(t *testing.T) {
+		t.Parallel()
+
+		check, err := NewChecker(KAFKA, "example", "example.com:9092", 5*time.Second, func(s string) string {
+			return ""
+		})
+		if err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+
+		expected := "example"
+		if check.String() != expected {
+			t.Fatalf("expected name to be %q got %q", expected, check.String())
+		}
+	})
+
+	t.Run("Valid AMQP checker", func(t *testing.T) {
+		t.Parallel()
+
+		check, err := NewChecker(AMQP, "example", "example.com:5672", 5*time.Second, func(s string) string {
+			return ""
+		})
+		if err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+
+		expected := "example"
+		if check.String() != expected {
+			t.Fatalf("expected name to be %q got %q", expected, check.String())
+		}
+	})
+
+	t.Run("Valid MongoDB checker", func(t *testing.T) {
+		t.Parallel()
+
+		check, err := NewChecker(MONGODB, "example", "example.com:27017", 5*time.Second, func(s string) string {
+			return ""
+		})
+		if err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+
+		expected := "example"
+		if check.String() != expected {
+			t.Fatalf("expected name to be %q got %q", expected, check.String())
+		}
+	})
+
+	t.Run("Valid Unix checker", func(t *testing.T) {
+		t.Parallel()
+
+		check, err := NewChecker(UNIX, "example", "unix:///var/run/app.sock", 5*time.Second, func(s string) string {
+			return ""
+		})
+		if err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+
+		expected := "example"
+		if check.String() != expected {
+			t.Fatalf("expected name to be %q got %q", expected, check.String())
+		}
+	})
+
+	t.Run("Valid File checker", func(t *testing.T) {
+		t.Parallel()
+
+		check, err := NewChecker(FILE, "example", "file:///tmp/ready", 5*time.Second, func(s string) string {
+			return ""
+		})
+		if err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+
+		expected := "example"
+		if check.String() != expected {
+			t.Fatalf("expected name to be %q got %q", expected, check.String())
+		}
+	})
+
+	t.Run("Valid Exec checker", func(t *testing.T) {
+		t.Parallel()
+
+		check, err := NewChecker(EXEC, "example", "exec://true", 5*time.Second, func(s string) string {
+			return ""
+		})
+		if err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+
+		expected := "example"
+		if check.String() != expected {
+			t.Fatalf("expected name to be %q got %q", expected, check.String())
+		}
+	})
+
+	t.Run("Valid Kubernetes checker", func(t *testing.T) {
+		t.Parallel()
+
+		check, err := NewChecker(K8S, "example", "k8s://deployment/api", 5*time.Second, func(s string) string {
+			if s == envK8sAPIServer {
+				return "https://kubernetes.default.svc"
+			}
+			return ""
+		})
+		if err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+
+		expected := "example"
+		if check.String() != expected {
+			t.Fatalf("expected name to be %q got %q", expected, check.String())
+		}
+	})
+
+	t.Run("Valid WebSocket checker", func(t *testing.T) {
+		t.Parallel()
+
+		check, err := NewChecker(WEBSOCKET, "example", "ws://example.com/socket", 5*time.Second, func(s string) string {
+			return ""
+		})
+		if err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+
+		expected := "example"
+		if check.String() != expected {
+			t.Fatalf("expected name to be %q got %q", expected, check.String())
+		}
+	})
+
+	t.Run("Valid SMTP checker", func(t *testing.T) {
+		t.Parallel()
+
+		check, err := NewChecker(SMTP, "example", "smtp://mail.example.com:25", 5*time.Second, func(s string) string {
+			return ""
+		})
+		if err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+
+		expected := "example"
+		if check.String() != expected {
+			t.Fatalf("expected name to be %q got %q", expected, check.String())
+		}
+	})
+
+	t.Run("Valid LDAP checker", func(t *testing.T) {
+		t.Parallel()
+
+		check, err := NewChecker(LDAP, "example", "ldap://ldap.example.com:389", 5*time.Second, func(s string) string {
+			return ""
+		})
+		if err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+
+		expected := "example"
+		if check.String() != expected {
+			t.Fatalf("expected name to be %q got %q", expected, check.String())
+		}
+	})
+
+	t.Run("Valid etcd checker", func(t *testing.T) {
+		t.Parallel()
+
+		check, err := NewChecker(ETCD, "example", "etcd.example.com:2379", 5*time.Second, func(s string) string {
+			return ""
+		})
+		if err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+
+		expected := "example"
+		if check.String() != expected {
+			t.Fatalf("expected name to be %q got %q", expected, check.String())
+		}
+	})
+
+	t.Run("Valid Consul checker", func(t *testing.T) {
+		t.Parallel()
+
+		check, err := NewChecker(CONSUL, "example", "consul.example.com:8500", 5*time.Second, func(s string) string {
+			return ""
+		})
+		if err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+
+		expected := "example"
+		if check.String() != expected {
+			t.Fatalf("expected name to be %q got %q", expected, check.String())
+		}
+	})
+
+	t.Run("Valid ZooKeeper checker", func(t *testing.T) {
+		t.Parallel()
+
+		check, err := NewChecker(ZOOKEEPER, "example", "zk.example.com:2181", 5*time.Second, func(s string) string {
+			return ""
+		})
+		if err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+
+		expected := "example"
+		if check.String() != expected {
+			t.Fatalf("expected name to be %q got %q", expected, check.String())
+		}
+	})
+
+	t.Run("Valid NATS checker", func(t *testing.T) {
+		t.Parallel()
+
+		check, err := NewChecker(NATS, "example", "nats.example.com:4222", 5*time.Second, func(s string) string {
+			return ""
+		})
+		if err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+
+		expected := "example"
+		if check.String() != expected {
+			t.Fatalf("expected name to be %q got %q", expected, check.String())
+		}
+	})
+
 	t.Run("Invalid checker type", func(t *testing.T) {
 		t.Parallel()
 
-		_, err := NewChecker(8, "example", "example.com", 5*time.Second, func(s string) string {
+		_, err := NewChecker(99, "example", "example.com", 5*time.Second, func(s string) string {
 			return ""
 		})
 		if err == nil {
 			t.Fatal("expected an error, got none")
 		}
 
-		expected := "unsupported check type: 8"
+		expected := "unsupported check type: 99"
 		if err.Error() != expected {
 			t.Errorf("expected error to be %q, got %q", expected, err.Error())
 		}
@@ -88,6 +347,54 @@ func TestGetCheckTypeString(t *testing.T) {
 		if ICMP.String() != "ICMP" {
 			t.Fatalf("expected 'ICMP', got %q", ICMP.String())
 		}
+		if UDP.String() != "UDP" {
+			t.Fatalf("expected 'UDP', got %q", UDP.String())
+		}
+		if TLS.String() != "TLS" {
+			t.Fatalf("expected 'TLS', got %q", TLS.String())
+		}
+		if KAFKA.String() != "KAFKA" {
+			t.Fatalf("expected 'KAFKA', got %q", KAFKA.String())
+		}
+		if AMQP.String() != "AMQP" {
+			t.Fatalf("expected 'AMQP', got %q", AMQP.String())
+		}
+		if MONGODB.String() != "MONGODB" {
+			t.Fatalf("expected 'MONGODB', got %q", MONGODB.String())
+		}
+		if UNIX.String() != "UNIX" {
+			t.Fatalf("expected 'UNIX', got %q", UNIX.String())
+		}
+		if FILE.String() != "FILE" {
+			t.Fatalf("expected 'FILE', got %q", FILE.String())
+		}
+		if EXEC.String() != "EXEC" {
+			t.Fatalf("expected 'EXEC', got %q", EXEC.String())
+		}
+		if K8S.String() != "K8S" {
+			t.Fatalf("expected 'K8S', got %q", K8S.String())
+		}
+		if WEBSOCKET.String() != "WEBSOCKET" {
+			t.Fatalf("expected 'WEBSOCKET', got %q", WEBSOCKET.String())
+		}
+		if SMTP.String() != "SMTP" {
+			t.Fatalf("expected 'SMTP', got %q", SMTP.String())
+		}
+		if LDAP.String() != "LDAP" {
+			t.Fatalf("expected 'LDAP', got %q", LDAP.String())
+		}
+		if ETCD.String() != "ETCD" {
+			t.Fatalf("expected 'ETCD', got %q", ETCD.String())
+		}
+		if CONSUL.String() != "CONSUL" {
+			t.Fatalf("expected 'CONSUL', got %q", CONSUL.String())
+		}
+		if ZOOKEEPER.String() != "ZOOKEEPER" {
+			t.Fatalf("expected 'ZOOKEEPER', got %q", ZOOKEEPER.String())
+		}
+		if NATS.String() != "NATS" {
+			t.Fatalf("expected 'NATS', got %q", NATS.String())
+		}
 	})
 
 	t.Run("Check type string (func)", func(t *testing.T) {
@@ -118,6 +425,150 @@ func TestGetCheckTypeString(t *testing.T) {
 			t.Fatalf("expected %q, got %q", want, got)
 		}
 
+		want = UDP
+		got, err = GetCheckTypeFromString("udp")
+		if err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+		if want != got {
+			t.Fatalf("expected %q, got %q", want, got)
+		}
+
+		want = TLS
+		got, err = GetCheckTypeFromString("tls")
+		if err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+		if want != got {
+			t.Fatalf("expected %q, got %q", want, got)
+		}
+
+		want = KAFKA
+		got, err = GetCheckTypeFromString("kafka")
+		if err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+		if want != got {
+			t.Fatalf("expected %q, got %q", want, got)
+		}
+
+		want = AMQP
+		got, err = GetCheckTypeFromString("amqp")
+		if err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+		if want != got {
+			t.Fatalf("expected %q, got %q", want, got)
+		}
+
+		want = MONGODB
+		got, err = GetCheckTypeFromString("mongodb")
+		if err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+		if want != got {
+			t.Fatalf("expected %q, got %q", want, got)
+		}
+
+		want = UNIX
+		got, err = GetCheckTypeFromString("unix")
+		if err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+		if want != got {
+			t.Fatalf("expected %q, got %q", want, got)
+		}
+
+		want = FILE
+		got, err = GetCheckTypeFromString("file")
+		if err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+		if want != got {
+			t.Fatalf("expected %q, got %q", want, got)
+		}
+
+		want = EXEC
+		got, err = GetCheckTypeFromString("exec")
+		if err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+		if want != got {
+			t.Fatalf("expected %q, got %q", want, got)
+		}
+
+		want = K8S
+		got, err = GetCheckTypeFromString("k8s")
+		if err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+		if want != got {
+			t.Fatalf("expected %q, got %q", want, got)
+		}
+
+		want = WEBSOCKET
+		got, err = GetCheckTypeFromString("ws")
+		if err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+		if want != got {
+			t.Fatalf("expected %q, got %q", want, got)
+		}
+
+		want = SMTP
+		got, err = GetCheckTypeFromString("smtp")
+		if err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+		if want != got {
+			t.Fatalf("expected %q, got %q", want, got)
+		}
+
+		want = LDAP
+		got, err = GetCheckTypeFromString("ldap")
+		if err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+		if want != got {
+			t.Fatalf("expected %q, got %q", want, got)
+		}
+
+		want = ETCD
+		got, err = GetCheckTypeFromString("etcd")
+		if err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+		if want != got {
+			t.Fatalf("expected %q, got %q", want, got)
+		}
+
+		want = CONSUL
+		got, err = GetCheckTypeFromString("consul")
+		if err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+		if want != got {
+			t.Fatalf("expected %q, got %q", want, got)
+		}
+
+		want = ZOOKEEPER
+		got, err = GetCheckTypeFromString("zookeeper")
+		if err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+		if want != got {
+			t.Fatalf("expected %q, got %q", want, got)
+		}
+
+		want = NATS
+		got, err = GetCheckTypeFromString("nats")
+		if err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+		if want != got {
+			t.Fatalf("expected %q, got %q", want, got)
+		}
+
 		want = -1
 		got, err = GetCheckTypeFromString("invalid")
 		if err == nil {
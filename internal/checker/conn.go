@@ -0,0 +1,30 @@
+package checker
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+)
+
+// deadlineConn bounds the reads and writes on the connection by the timeout, or by the deadline of the context if it is earlier,
+// and interrupts them as soon as the context is canceled. The returned function must be called once the exchange is done.
+func deadlineConn(ctx context.Context, conn net.Conn, timeout time.Duration) (func(), error) {
+	var deadline time.Time
+	if timeout > 0 {
+		deadline = time.Now().Add(timeout)
+	}
+	if ctxDeadline, ok := ctx.Deadline(); ok && (deadline.IsZero() || ctxDeadline.Before(deadline)) {
+		deadline = ctxDeadline
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		return nil, fmt.Errorf("failed to set deadline: %w", err)
+	}
+
+	// Stop blocking reads and writes as soon as the context is canceled
+	stop := context.AfterFunc(ctx, func() {
+		_ = conn.SetDeadline(time.Now())
+	})
+
+	return func() { stop() }, nil
+}
@@ -0,0 +1,160 @@
+package checker
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"testing"
+	"time"
+)
+
+// startSilentServer starts a TCP server which accepts connections but never sends anything.
+func startSilentServer(t *testing.T) net.Listener {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to start silent server: %q", err)
+	}
+	done := make(chan struct{})
+	t.Cleanup(func() {
+		ln.Close()
+		close(done)
+	})
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				<-done
+				conn.Close()
+			}()
+		}
+	}()
+
+	return ln
+}
+
+func TestDeadlineConn(t *testing.T) {
+	t.Parallel()
+
+	read := func(t *testing.T, ctx context.Context, timeout time.Duration) (time.Duration, error) {
+		t.Helper()
+
+		ln := startSilentServer(t)
+		conn, err := net.Dial("tcp", ln.Addr().String())
+		if err != nil {
+			t.Fatalf("failed to connect: %q", err)
+		}
+		defer conn.Close()
+
+		stop, err := deadlineConn(ctx, conn, timeout)
+		if err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+		defer stop()
+
+		start := time.Now()
+		_, err = conn.Read(make([]byte, 1))
+		return time.Since(start), err
+	}
+
+	t.Run("Timeout", func(t *testing.T) {
+		t.Parallel()
+
+		elapsed, err := read(t, context.Background(), 100*time.Millisecond)
+		if !errors.Is(err, os.ErrDeadlineExceeded) {
+			t.Fatalf("expected a deadline error, got %v", err)
+		}
+		if elapsed > time.Second {
+			t.Errorf("expected the read to stop after the timeout, took %s", elapsed)
+		}
+	})
+
+	t.Run("Earlier context deadline", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		elapsed, err := read(t, ctx, time.Minute)
+		if !errors.Is(err, os.ErrDeadlineExceeded) {
+			t.Fatalf("expected a deadline error, got %v", err)
+		}
+		if elapsed > time.Second {
+			t.Errorf("expected the read to stop at the context deadline, took %s", elapsed)
+		}
+	})
+
+	t.Run("Canceled context", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(100*time.Millisecond, cancel)
+
+		elapsed, err := read(t, ctx, time.Minute)
+		if !errors.Is(err, os.ErrDeadlineExceeded) {
+			t.Fatalf("expected a deadline error, got %v", err)
+		}
+		if elapsed > time.Second {
+			t.Errorf("expected the read to stop when the context is canceled, took %s", elapsed)
+		}
+	})
+}
+
+func TestSilentServerChecks(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		newFunc func(name, address string, timeout time.Duration, getEnv func(string) string) (Checker, error)
+		scheme  string
+		path    string
+	}{
+		{name: "TLS check", newFunc: NewTLSChecker},
+		{name: "Kafka check", newFunc: NewKafkaChecker},
+		{name: "AMQP check", newFunc: NewAMQPChecker},
+		{name: "MongoDB check", newFunc: NewMongoDBChecker},
+		{name: "WebSocket check", newFunc: NewWebSocketChecker, scheme: "ws://", path: "/ws"},
+		{name: "Secure WebSocket check", newFunc: NewWebSocketChecker, scheme: "wss://", path: "/ws"},
+		{name: "SMTP check", newFunc: NewSMTPChecker},
+		{name: "LDAP check", newFunc: NewLDAPChecker},
+		{name: "ZooKeeper check", newFunc: NewZooKeeperChecker},
+		{name: "NATS check", newFunc: NewNATSChecker},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ln := startSilentServer(t)
+
+			checker, err := tt.newFunc("example", tt.scheme+ln.Addr().String()+tt.path, 200*time.Millisecond, func(string) string { return "" })
+			if err != nil {
+				t.Fatalf("failed to create checker: %q", err)
+			}
+
+			// Like in the runner, the context has no deadline, so the check must give up after its own timeout
+			done := make(chan error, 1)
+			go func() {
+				done <- checker.Check(context.Background())
+			}()
+
+			select {
+			case err := <-done:
+				if err == nil {
+					t.Fatal("expected an error, got none")
+				}
+				if !errors.Is(err, os.ErrDeadlineExceeded) && !errors.Is(err, context.DeadlineExceeded) {
+					t.Errorf("expected a timeout error, got %q", err)
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatal("expected the check to time out, but it is still blocked")
+			}
+		})
+	}
+}
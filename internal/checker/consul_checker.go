@@ -0,0 +1,148 @@
+package checker
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	envConsulTLS           string = "CONSUL_TLS"
+	envConsulCAFile        string = "CONSUL_CA_FILE"
+	envConsulSkipTLSVerify string = "CONSUL_SKIP_TLS_VERIFY"
+
+	defaultConsulTLS           bool = false
+	defaultConsulSkipTLSVerify bool = false
+
+	consulMaxResponseSize int64 = 64 * 1024 // The maximum size of a response read from the status endpoint.
+)
+
+// ConsulChecker implements the Checker interface for Consul checks.
+type ConsulChecker struct {
+	Name    string       // The name of the checker.
+	Address string       // The address of the target.
+	TLS     bool         // Whether the HTTP API uses https.
+	client  *http.Client // The HTTP client to use for the request.
+}
+
+// String returns the name of the checker.
+func (c *ConsulChecker) String() string {
+	return c.Name
+}
+
+// NewConsulChecker creates a new ConsulChecker.
+func NewConsulChecker(name, address string, timeout time.Duration, getEnv func(string) string) (Checker, error) {
+	// The "consul://" prefix is used to identify the check type and is not needed for further processing,
+	// so it must be removed before passing the address to other functions.
+	address = strings.TrimPrefix(address, "consul://")
+
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address %s: %w", address, err)
+	}
+
+	checker := ConsulChecker{
+		Name:    name,
+		Address: address,
+		TLS:     defaultConsulTLS,
+	}
+
+	// Determine if the HTTP API uses https
+	if tlsStr := getEnv(envConsulTLS); tlsStr != "" {
+		useTLS, err := strconv.ParseBool(tlsStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value: %w", envConsulTLS, err)
+		}
+		checker.TLS = useTLS
+	}
+
+	tlsConfig := &tls.Config{
+		ServerName:         host,
+		InsecureSkipVerify: defaultConsulSkipTLSVerify,
+	}
+
+	// Load the CA certificates if specified
+	if caFile := getEnv(envConsulCAFile); caFile != "" {
+		pool, err := loadCertPool(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value: %w", envConsulCAFile, err)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	// Determine if TLS verification should be skipped
+	if skipVerifyStr := getEnv(envConsulSkipTLSVerify); skipVerifyStr != "" {
+		skipVerify, err := strconv.ParseBool(skipVerifyStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value: %w", envConsulSkipTLSVerify, err)
+		}
+		tlsConfig.InsecureSkipVerify = skipVerify
+	}
+
+	// Connections originate from the configured source address and interface
+	dialer, err := newDialer("tcp", timeout, getEnv)
+	if err != nil {
+		return nil, err
+	}
+
+	checker.client = &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:     dialer.DialContext,
+			TLSClientConfig: tlsConfig,
+		},
+	}
+
+	return &checker, nil
+}
+
+// Check queries the leader of the Consul cluster and verifies that one is elected.
+func (c *ConsulChecker) Check(ctx context.Context) error {
+	scheme := "http"
+	if c.TLS {
+		scheme = "https"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, scheme+"://"+c.Address+"/v1/status/leader", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, consulMaxResponseSize))
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	// Agents answer with an error message in plain text, e.g. "No cluster leader" during an election
+	if resp.StatusCode != http.StatusOK {
+		if msg := strings.TrimSpace(string(body)); msg != "" {
+			return fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, msg)
+		}
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	// The leader is returned as a JSON string, which is empty while no leader is elected
+	var leader string
+	if err := json.Unmarshal(body, &leader); err != nil {
+		return fmt.Errorf("failed to decode leader: %w", err)
+	}
+	if leader == "" {
+		return errors.New("no cluster leader elected")
+	}
+
+	return nil
+}
@@ -0,0 +1,128 @@
+package checker
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewConsulChecker(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Valid Consul checker config", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envConsulTLS: "true",
+			}
+			return env[key]
+		}
+
+		checker, err := NewConsulChecker("example", "consul://consul.example.com:8501", 1*time.Second, mockEnv)
+		if err != nil {
+			t.Fatalf("failed to create ConsulChecker: %q", err)
+		}
+
+		consulChecker := checker.(*ConsulChecker)
+
+		expected := "consul.example.com:8501"
+		if consulChecker.Address != expected {
+			t.Errorf("expected Address to be %q, got %q", expected, consulChecker.Address)
+		}
+
+		if !consulChecker.TLS {
+			t.Error("expected TLS to be true")
+		}
+	})
+
+	t.Run("Invalid CONSUL_CA_FILE", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envConsulCAFile: "/nonexistent/ca.crt",
+			}
+			return env[key]
+		}
+
+		_, err := NewConsulChecker("example", "consul.example.com:8500", 1*time.Second, mockEnv)
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := fmt.Sprintf("invalid %s value: failed to read CA file: open /nonexistent/ca.crt: no such file or directory", envConsulCAFile)
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+}
+
+func TestConsulChecker(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		statusCode int
+		body       string
+		expected   string
+	}{
+		{
+			name:       "Leader elected",
+			statusCode: http.StatusOK,
+			body:       `"10.0.0.10:8300"`,
+		},
+		{
+			name:       "No leader elected",
+			statusCode: http.StatusOK,
+			body:       `""`,
+			expected:   "no cluster leader elected",
+		},
+		{
+			name:       "Agent error",
+			statusCode: http.StatusInternalServerError,
+			body:       "No cluster leader\n",
+			expected:   "unexpected status code 500: No cluster leader",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/v1/status/leader" {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				w.WriteHeader(tt.statusCode)
+				fmt.Fprint(w, tt.body)
+			}))
+			t.Cleanup(server.Close)
+
+			checker, err := NewConsulChecker("example", "consul://"+strings.TrimPrefix(server.URL, "http://"), 1*time.Second, func(string) string { return "" })
+			if err != nil {
+				t.Fatalf("failed to create ConsulChecker: %q", err)
+			}
+
+			err = checker.Check(context.Background())
+			if tt.expected == "" {
+				if err != nil {
+					t.Fatalf("expected no error, got %q", err)
+				}
+				return
+			}
+
+			if err == nil {
+				t.Fatal("expected an error, got none")
+			}
+			if err.Error() != tt.expected {
+				t.Errorf("expected error %q, got %q", tt.expected, err.Error())
+			}
+		})
+	}
+}
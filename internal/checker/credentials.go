@@ -0,0 +1,18 @@
+package checker
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// readCredentialFile reads a credential (e.g. a password mounted from a Kubernetes Secret) from the given file.
+// Trailing newlines are removed, since editors and tools like Vault agent often append them.
+func readCredentialFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read credential file: %w", err)
+	}
+
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
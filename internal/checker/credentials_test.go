@@ -0,0 +1,44 @@
+package checker
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadCredentialFile(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Trailing newline is removed", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "password")
+		if err := os.WriteFile(path, []byte(" s3cr3t \n"), 0o600); err != nil {
+			t.Fatalf("failed to write credential file: %q", err)
+		}
+
+		credential, err := readCredentialFile(path)
+		if err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+
+		expected := " s3cr3t "
+		if credential != expected {
+			t.Errorf("expected %q, got %q", expected, credential)
+		}
+	})
+
+	t.Run("Missing file", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := readCredentialFile("/does/not/exist")
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := "failed to read credential file: open /does/not/exist: no such file or directory"
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+}
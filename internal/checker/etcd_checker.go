@@ -0,0 +1,161 @@
+package checker
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	envEtcdTLS           string = "ETCD_TLS"
+	envEtcdCAFile        string = "ETCD_CA_FILE"
+	envEtcdCertFile      string = "ETCD_CERT_FILE"
+	envEtcdKeyFile       string = "ETCD_KEY_FILE"
+	envEtcdSkipTLSVerify string = "ETCD_SKIP_TLS_VERIFY"
+
+	defaultEtcdTLS           bool = false
+	defaultEtcdSkipTLSVerify bool = false
+
+	etcdMaxResponseSize int64 = 64 * 1024 // The maximum size of a response read from the health endpoint.
+)
+
+// EtcdChecker implements the Checker interface for etcd health checks.
+type EtcdChecker struct {
+	Name    string       // The name of the checker.
+	Address string       // The address of the target.
+	TLS     bool         // Whether the client URL uses https.
+	client  *http.Client // The HTTP client to use for the request.
+}
+
+// String returns the name of the checker.
+func (c *EtcdChecker) String() string {
+	return c.Name
+}
+
+// NewEtcdChecker creates a new EtcdChecker.
+func NewEtcdChecker(name, address string, timeout time.Duration, getEnv func(string) string) (Checker, error) {
+	// The "etcd://" prefix is used to identify the check type and is not needed for further processing,
+	// so it must be removed before passing the address to other functions.
+	address = strings.TrimPrefix(address, "etcd://")
+
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address %s: %w", address, err)
+	}
+
+	checker := EtcdChecker{
+		Name:    name,
+		Address: address,
+		TLS:     defaultEtcdTLS,
+	}
+
+	// Determine if the client URL uses https
+	if tlsStr := getEnv(envEtcdTLS); tlsStr != "" {
+		useTLS, err := strconv.ParseBool(tlsStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value: %w", envEtcdTLS, err)
+		}
+		checker.TLS = useTLS
+	}
+
+	tlsConfig := &tls.Config{
+		ServerName:         host,
+		InsecureSkipVerify: defaultEtcdSkipTLSVerify,
+	}
+
+	// Load the CA certificates if specified
+	if caFile := getEnv(envEtcdCAFile); caFile != "" {
+		pool, err := loadCertPool(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value: %w", envEtcdCAFile, err)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	// Load the client certificate if specified, since etcd clusters commonly require client authentication
+	certFile, keyFile := getEnv(envEtcdCertFile), getEnv(envEtcdKeyFile)
+	if (certFile == "") != (keyFile == "") {
+		return nil, fmt.Errorf("%s and %s must be set together", envEtcdCertFile, envEtcdKeyFile)
+	}
+	if certFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value: %w", envEtcdCertFile, err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	// Determine if TLS verification should be skipped
+	if skipVerifyStr := getEnv(envEtcdSkipTLSVerify); skipVerifyStr != "" {
+		skipVerify, err := strconv.ParseBool(skipVerifyStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value: %w", envEtcdSkipTLSVerify, err)
+		}
+		tlsConfig.InsecureSkipVerify = skipVerify
+	}
+
+	// Connections originate from the configured source address and interface
+	dialer, err := newDialer("tcp", timeout, getEnv)
+	if err != nil {
+		return nil, err
+	}
+
+	checker.client = &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:     dialer.DialContext,
+			TLSClientConfig: tlsConfig,
+		},
+	}
+
+	return &checker, nil
+}
+
+// Check queries the health endpoint of the etcd member and verifies that it reports itself as healthy.
+func (c *EtcdChecker) Check(ctx context.Context) error {
+	scheme := "http"
+	if c.TLS {
+		scheme = "https"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, scheme+"://"+c.Address+"/health", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, etcdMaxResponseSize))
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	// An unhealthy member answers with 503 and a body explaining the reason
+	var health struct {
+		Health string `json:"health"`
+		Reason string `json:"reason"`
+	}
+	if err := json.Unmarshal(body, &health); err != nil {
+		return fmt.Errorf("unexpected status code %d: failed to decode health: %w", resp.StatusCode, err)
+	}
+
+	if health.Health != "true" {
+		if health.Reason != "" {
+			return fmt.Errorf("etcd member is not healthy: %s", health.Reason)
+		}
+		return fmt.Errorf("etcd member is not healthy")
+	}
+
+	return nil
+}
@@ -0,0 +1,200 @@
+package checker
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// startFakeEtcdServer starts an HTTP server answering the health endpoint with the given status code and body.
+func startFakeEtcdServer(t *testing.T, statusCode int, body string) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/health" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(statusCode)
+		fmt.Fprint(w, body)
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestNewEtcdChecker(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Valid etcd checker config", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envEtcdTLS:           "true",
+				envEtcdSkipTLSVerify: "true",
+			}
+			return env[key]
+		}
+
+		checker, err := NewEtcdChecker("example", "etcd://etcd.example.com:2379", 1*time.Second, mockEnv)
+		if err != nil {
+			t.Fatalf("failed to create EtcdChecker: %q", err)
+		}
+
+		etcdChecker := checker.(*EtcdChecker)
+
+		expected := "etcd.example.com:2379"
+		if etcdChecker.Address != expected {
+			t.Errorf("expected Address to be %q, got %q", expected, etcdChecker.Address)
+		}
+
+		if !etcdChecker.TLS {
+			t.Error("expected TLS to be true")
+		}
+	})
+
+	t.Run("Missing port", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := NewEtcdChecker("example", "etcd://etcd.example.com", 1*time.Second, func(string) string { return "" })
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := "invalid address etcd.example.com: address etcd.example.com: missing port in address"
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Certificate without key", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envEtcdCertFile: "/etc/etcd/client.crt",
+			}
+			return env[key]
+		}
+
+		_, err := NewEtcdChecker("example", "etcd.example.com:2379", 1*time.Second, mockEnv)
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := fmt.Sprintf("%s and %s must be set together", envEtcdCertFile, envEtcdKeyFile)
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Invalid ETCD_TLS", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envEtcdTLS: "invalid",
+			}
+			return env[key]
+		}
+
+		_, err := NewEtcdChecker("example", "etcd.example.com:2379", 1*time.Second, mockEnv)
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := fmt.Sprintf("invalid %s value: strconv.ParseBool: parsing \"invalid\": invalid syntax", envEtcdTLS)
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+}
+
+func TestEtcdChecker(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		statusCode int
+		body       string
+		expected   string
+	}{
+		{
+			name:       "Healthy member",
+			statusCode: http.StatusOK,
+			body:       `{"health":"true","reason":""}`,
+		},
+		{
+			name:       "No leader",
+			statusCode: http.StatusServiceUnavailable,
+			body:       `{"health":"false","reason":"RAFT NO LEADER"}`,
+			expected:   "etcd member is not healthy: RAFT NO LEADER",
+		},
+		{
+			name:       "Unexpected response",
+			statusCode: http.StatusBadGateway,
+			body:       "Bad Gateway",
+			expected:   "unexpected status code 502: failed to decode health: invalid character 'B' looking for beginning of value",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			server := startFakeEtcdServer(t, tt.statusCode, tt.body)
+
+			checker, err := NewEtcdChecker("example", "etcd://"+strings.TrimPrefix(server.URL, "http://"), 1*time.Second, func(string) string { return "" })
+			if err != nil {
+				t.Fatalf("failed to create EtcdChecker: %q", err)
+			}
+
+			err = checker.Check(context.Background())
+			if tt.expected == "" {
+				if err != nil {
+					t.Fatalf("expected no error, got %q", err)
+				}
+				return
+			}
+
+			if err == nil {
+				t.Fatal("expected an error, got none")
+			}
+			if err.Error() != tt.expected {
+				t.Errorf("expected error %q, got %q", tt.expected, err.Error())
+			}
+		})
+	}
+
+	t.Run("Healthy member with TLS", func(t *testing.T) {
+		t.Parallel()
+
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"health":"true","reason":""}`)
+		}))
+		t.Cleanup(server.Close)
+
+		caFile := writeServerCA(t, server)
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envEtcdTLS:    "true",
+				envEtcdCAFile: caFile,
+			}
+			return env[key]
+		}
+
+		checker, err := NewEtcdChecker("example", strings.TrimPrefix(server.URL, "https://"), 1*time.Second, mockEnv)
+		if err != nil {
+			t.Fatalf("failed to create EtcdChecker: %q", err)
+		}
+
+		if err := checker.Check(context.Background()); err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+	})
+}
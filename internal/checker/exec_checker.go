@@ -0,0 +1,165 @@
+package checker
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	envExecShell string = "EXEC_SHELL"
+
+	defaultExecShell bool = false
+
+	execShellPath  string        = "/bin/sh"              // The shell used if EXEC_SHELL is enabled.
+	execWaitDelay  time.Duration = 100 * time.Millisecond // How long to wait for the output pipes to close after the command was killed.
+	execNoExitCode int           = -1                     // The exit code reported by os/exec if the command was terminated by a signal.
+)
+
+// ExecChecker implements the Checker interface for checks running an arbitrary command.
+type ExecChecker struct {
+	Name    string        // The name of the checker.
+	Address string        // The command line to run.
+	Args    []string      // The program and its arguments.
+	Timeout time.Duration // The maximum time the command may run.
+}
+
+// String returns the name of the checker.
+func (c *ExecChecker) String() string {
+	return c.Name
+}
+
+// NewExecChecker creates a new ExecChecker.
+func NewExecChecker(name, address string, timeout time.Duration, getEnv func(string) string) (Checker, error) {
+	// The "exec://" prefix is used to identify the check type and is not needed for further processing,
+	// so it must be removed before passing the address to other functions.
+	address = strings.TrimPrefix(address, "exec://")
+
+	checker := ExecChecker{
+		Name:    name,
+		Address: address,
+		Timeout: timeout,
+	}
+
+	// Determine if the command must be run by a shell
+	useShell := defaultExecShell
+	if shellStr := getEnv(envExecShell); shellStr != "" {
+		shell, err := strconv.ParseBool(shellStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value: %w", envExecShell, err)
+		}
+		useShell = shell
+	}
+
+	if useShell {
+		checker.Args = []string{execShellPath, "-c", address}
+		return &checker, nil
+	}
+
+	args, err := SplitCommandLine(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid command %q: %w", address, err)
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("invalid command %q: command is empty", address)
+	}
+	checker.Args = args
+
+	return &checker, nil
+}
+
+// Check runs the command and reports success if it exits with code 0.
+func (c *ExecChecker) Check(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+
+	var output bytes.Buffer
+	cmd := exec.CommandContext(ctx, c.Args[0], c.Args[1:]...)
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	cmd.WaitDelay = execWaitDelay // Do not wait forever for child processes which inherited the output pipes
+
+	err := cmd.Run()
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf("command timed out after %s", c.Timeout)
+	} else if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() != execNoExitCode {
+		err = fmt.Errorf("command exited with code %d", exitErr.ExitCode())
+	}
+
+	if out := strings.TrimSpace(output.String()); out != "" {
+		return fmt.Errorf("%w: %s", err, out)
+	}
+
+	return err
+}
+
+// SplitCommandLine splits a command line into its arguments. Arguments are separated by whitespace;
+// single quotes preserve their content literally, double quotes allow escaping '"' and '\' with a backslash,
+// and a backslash outside of quotes escapes the next character.
+func SplitCommandLine(s string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	inArg := false
+	var quote rune
+
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+
+		switch {
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+				continue
+			}
+			current.WriteRune(r)
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+				continue
+			}
+			if r == '\\' && i+1 < len(runes) && (runes[i+1] == '"' || runes[i+1] == '\\') {
+				i++
+				r = runes[i]
+			}
+			current.WriteRune(r)
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+		case r == '\\':
+			if i+1 == len(runes) {
+				return nil, fmt.Errorf("trailing backslash")
+			}
+			i++
+			current.WriteRune(runes[i])
+			inArg = true
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+
+	return args, nil
+}
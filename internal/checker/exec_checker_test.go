@@ -0,0 +1,235 @@
+package checker
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNewExecChecker(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Valid Exec checker config", func(t *testing.T) {
+		t.Parallel()
+
+		checker, err := NewExecChecker("example", `exec://pg_isready -h db --dbname "app db"`, 1*time.Second, func(string) string { return "" })
+		if err != nil {
+			t.Fatalf("failed to create ExecChecker: %q", err)
+		}
+
+		execChecker := checker.(*ExecChecker)
+
+		expected := []string{"pg_isready", "-h", "db", "--dbname", "app db"}
+		if !reflect.DeepEqual(execChecker.Args, expected) {
+			t.Errorf("expected Args to be %q, got %q", expected, execChecker.Args)
+		}
+
+		if execChecker.Timeout != 1*time.Second {
+			t.Errorf("expected Timeout to be 1s, got %v", execChecker.Timeout)
+		}
+	})
+
+	t.Run("Valid Exec checker config with shell", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envExecShell: "true",
+			}
+			return env[key]
+		}
+
+		checker, err := NewExecChecker("example", "test -f /tmp/ready && echo ok", 1*time.Second, mockEnv)
+		if err != nil {
+			t.Fatalf("failed to create ExecChecker: %q", err)
+		}
+
+		expected := []string{"/bin/sh", "-c", "test -f /tmp/ready && echo ok"}
+		if args := checker.(*ExecChecker).Args; !reflect.DeepEqual(args, expected) {
+			t.Errorf("expected Args to be %q, got %q", expected, args)
+		}
+	})
+
+	t.Run("Invalid EXEC_SHELL", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envExecShell: "invalid",
+			}
+			return env[key]
+		}
+
+		_, err := NewExecChecker("example", "true", 1*time.Second, mockEnv)
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := fmt.Sprintf("invalid %s value: strconv.ParseBool: parsing \"invalid\": invalid syntax", envExecShell)
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Empty command", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := NewExecChecker("example", "exec://  ", 1*time.Second, func(string) string { return "" })
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := `invalid command "  ": command is empty`
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Unterminated quote", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := NewExecChecker("example", `echo "ready`, 1*time.Second, func(string) string { return "" })
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := `invalid command "echo \"ready": unterminated " quote`
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+}
+
+func TestExecChecker(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Valid Exec check", func(t *testing.T) {
+		t.Parallel()
+
+		checker, err := NewExecChecker("example", "exec://true", 1*time.Second, func(string) string { return "" })
+		if err != nil {
+			t.Fatalf("failed to create ExecChecker: %q", err)
+		}
+
+		if err := checker.Check(context.Background()); err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+	})
+
+	t.Run("Non-zero exit code with output", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envExecShell: "true",
+			}
+			return env[key]
+		}
+
+		checker, err := NewExecChecker("example", "echo 'no response'; echo 'server is starting' >&2; exit 2", 1*time.Second, mockEnv)
+		if err != nil {
+			t.Fatalf("failed to create ExecChecker: %q", err)
+		}
+
+		err = checker.Check(context.Background())
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := "command exited with code 2: no response\nserver is starting"
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Non-zero exit code without output", func(t *testing.T) {
+		t.Parallel()
+
+		checker, err := NewExecChecker("example", "false", 1*time.Second, func(string) string { return "" })
+		if err != nil {
+			t.Fatalf("failed to create ExecChecker: %q", err)
+		}
+
+		err = checker.Check(context.Background())
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := "command exited with code 1"
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Command not found", func(t *testing.T) {
+		t.Parallel()
+
+		checker, err := NewExecChecker("example", "portpatrol-does-not-exist", 1*time.Second, func(string) string { return "" })
+		if err != nil {
+			t.Fatalf("failed to create ExecChecker: %q", err)
+		}
+
+		err = checker.Check(context.Background())
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := `exec: "portpatrol-does-not-exist": executable file not found in $PATH`
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Command timed out", func(t *testing.T) {
+		t.Parallel()
+
+		checker, err := NewExecChecker("example", "sleep 5", 100*time.Millisecond, func(string) string { return "" })
+		if err != nil {
+			t.Fatalf("failed to create ExecChecker: %q", err)
+		}
+
+		err = checker.Check(context.Background())
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := "command timed out after 100ms"
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+}
+
+func TestSplitCommandLine(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Quotes and escapes", func(t *testing.T) {
+		t.Parallel()
+
+		args, err := SplitCommandLine(`curl  -H 'X-Token: a"b' --data "{\"ok\": true}" a\ b ''`)
+		if err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+
+		expected := []string{"curl", "-H", `X-Token: a"b`, "--data", `{"ok": true}`, "a b", ""}
+		if !reflect.DeepEqual(args, expected) {
+			t.Errorf("expected %q, got %q", expected, args)
+		}
+	})
+
+	t.Run("Trailing backslash", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := SplitCommandLine(`echo \`)
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := "trailing backslash"
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+}
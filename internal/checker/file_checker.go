@@ -0,0 +1,185 @@
+package checker
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	envFileNotEmpty      string = "FILE_NOT_EMPTY"
+	envFileChecksum      string = "FILE_CHECKSUM"
+	envFileMatch         string = "FILE_MATCH"
+	envFileModifiedAfter string = "FILE_MODIFIED_AFTER"
+
+	defaultFileNotEmpty bool = false
+)
+
+// fileChecksumAlgorithms maps the supported checksum prefixes to their hash constructors.
+var fileChecksumAlgorithms = map[string]func() hash.Hash{
+	"sha256": sha256.New,
+	"sha512": sha512.New,
+}
+
+// FileChecker implements the Checker interface for file and path existence checks.
+type FileChecker struct {
+	Name              string           // The name of the checker.
+	Address           string           // The path to check.
+	NotEmpty          bool             // Whether the file must not be empty (or the directory must contain entries).
+	ChecksumAlgorithm string           // The algorithm of the expected checksum (e.g. "sha256").
+	Checksum          []byte           // The expected checksum of the file content. If nil, the checksum is not checked.
+	Match             *regexp.Regexp   // The pattern the file content must match. If nil, the content is not checked.
+	ModifiedAfter     time.Time        // The time after which the file must have been modified. If zero, it is not checked.
+	newHash           func() hash.Hash // The constructor of the checksum hash.
+}
+
+// String returns the name of the checker.
+func (c *FileChecker) String() string {
+	return c.Name
+}
+
+// NewFileChecker creates a new FileChecker.
+func NewFileChecker(name, address string, timeout time.Duration, getEnv func(string) string) (Checker, error) {
+	// The "file://" prefix is used to identify the check type and is not needed for further processing,
+	// so it must be removed before passing the address to other functions.
+	address = strings.TrimPrefix(address, "file://")
+
+	checker := FileChecker{
+		Name:     name,
+		Address:  address,
+		NotEmpty: defaultFileNotEmpty,
+	}
+
+	// Determine if the file must not be empty
+	if notEmptyStr := getEnv(envFileNotEmpty); notEmptyStr != "" {
+		notEmpty, err := strconv.ParseBool(notEmptyStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value: %w", envFileNotEmpty, err)
+		}
+		checker.NotEmpty = notEmpty
+	}
+
+	// Parse the expected checksum in the format "[algorithm:]hex"
+	if checksumStr := getEnv(envFileChecksum); checksumStr != "" {
+		algorithm, sum, found := strings.Cut(checksumStr, ":")
+		if !found {
+			algorithm, sum = "sha256", checksumStr
+		}
+		algorithm = strings.ToLower(algorithm)
+
+		newHash, ok := fileChecksumAlgorithms[algorithm]
+		if !ok {
+			return nil, fmt.Errorf("invalid %s value: unsupported checksum algorithm: %s", envFileChecksum, algorithm)
+		}
+		checksum, err := hex.DecodeString(sum)
+		if err != nil || len(checksum) != newHash().Size() {
+			return nil, fmt.Errorf("invalid %s value: invalid %s checksum: %s", envFileChecksum, algorithm, sum)
+		}
+
+		checker.ChecksumAlgorithm = algorithm
+		checker.Checksum = checksum
+		checker.newHash = newHash
+	}
+
+	// Compile the content pattern if specified
+	if matchStr := getEnv(envFileMatch); matchStr != "" {
+		match, err := regexp.Compile(matchStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value: %w", envFileMatch, err)
+		}
+		checker.Match = match
+	}
+
+	// Parse the modification time threshold
+	if modifiedAfterStr := getEnv(envFileModifiedAfter); modifiedAfterStr != "" {
+		modifiedAfter, err := time.Parse(time.RFC3339, modifiedAfterStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value: %w", envFileModifiedAfter, err)
+		}
+		checker.ModifiedAfter = modifiedAfter
+	}
+
+	return &checker, nil
+}
+
+// Check verifies that the path exists and satisfies all configured conditions.
+func (c *FileChecker) Check(ctx context.Context) error {
+	info, err := os.Stat(c.Address)
+	if err != nil {
+		return err
+	}
+
+	if !c.ModifiedAfter.IsZero() && !info.ModTime().After(c.ModifiedAfter) {
+		return fmt.Errorf("%s was last modified at %s, expected after %s", c.Address, info.ModTime().UTC().Format(time.RFC3339), c.ModifiedAfter.UTC().Format(time.RFC3339))
+	}
+
+	if info.IsDir() {
+		return c.checkDir()
+	}
+
+	if c.NotEmpty && info.Size() == 0 {
+		return fmt.Errorf("%s is empty", c.Address)
+	}
+
+	if c.Checksum == nil && c.Match == nil {
+		return nil
+	}
+
+	return c.checkContent(ctx)
+}
+
+// checkDir validates a directory. Content conditions cannot be applied to directories.
+func (c *FileChecker) checkDir() error {
+	if c.Checksum != nil || c.Match != nil {
+		return fmt.Errorf("%s is a directory", c.Address)
+	}
+
+	if !c.NotEmpty {
+		return nil
+	}
+
+	entries, err := os.ReadDir(c.Address)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return fmt.Errorf("%s is empty", c.Address)
+	}
+
+	return nil
+}
+
+// checkContent reads the file and validates its checksum and content.
+func (c *FileChecker) checkContent(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	content, err := os.ReadFile(c.Address)
+	if err != nil {
+		return err
+	}
+
+	if c.Checksum != nil {
+		h := c.newHash()
+		h.Write(content)
+		if sum := h.Sum(nil); !bytes.Equal(sum, c.Checksum) {
+			return fmt.Errorf("%s checksum mismatch: got %s, expected %s", c.ChecksumAlgorithm, hex.EncodeToString(sum), hex.EncodeToString(c.Checksum))
+		}
+	}
+
+	if c.Match != nil && !c.Match.Match(content) {
+		return fmt.Errorf("content of %s does not match %q", c.Address, c.Match.String())
+	}
+
+	return nil
+}
@@ -0,0 +1,436 @@
+package checker
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeTempFile writes the given content to a file in a temporary directory and returns its path.
+func writeTempFile(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "ready")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write file: %q", err)
+	}
+
+	return path
+}
+
+func TestNewFileChecker(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Valid File checker config", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envFileNotEmpty:      "true",
+				envFileChecksum:      "sha512:" + fmt.Sprintf("%0128x", 1),
+				envFileMatch:         "^done$",
+				envFileModifiedAfter: "2024-01-01T00:00:00Z",
+			}
+			return env[key]
+		}
+
+		checker, err := NewFileChecker("example", "file:///var/run/ready", 1*time.Second, mockEnv)
+		if err != nil {
+			t.Fatalf("failed to create FileChecker: %q", err)
+		}
+
+		fileChecker := checker.(*FileChecker)
+
+		expected := "/var/run/ready"
+		if fileChecker.Address != expected {
+			t.Errorf("expected Address to be %q, got %q", expected, fileChecker.Address)
+		}
+
+		if !fileChecker.NotEmpty {
+			t.Error("expected NotEmpty to be true")
+		}
+
+		if fileChecker.ChecksumAlgorithm != "sha512" || len(fileChecker.Checksum) != 64 {
+			t.Errorf("expected a sha512 checksum, got %s with %d bytes", fileChecker.ChecksumAlgorithm, len(fileChecker.Checksum))
+		}
+
+		if fileChecker.Match.String() != "^done$" {
+			t.Errorf("expected Match to be %q, got %q", "^done$", fileChecker.Match.String())
+		}
+
+		if !fileChecker.ModifiedAfter.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)) {
+			t.Errorf("expected ModifiedAfter to be 2024-01-01, got %s", fileChecker.ModifiedAfter)
+		}
+	})
+
+	t.Run("Checksum without algorithm", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envFileChecksum: fmt.Sprintf("%064x", 1),
+			}
+			return env[key]
+		}
+
+		checker, err := NewFileChecker("example", "/var/run/ready", 1*time.Second, mockEnv)
+		if err != nil {
+			t.Fatalf("failed to create FileChecker: %q", err)
+		}
+
+		if algorithm := checker.(*FileChecker).ChecksumAlgorithm; algorithm != "sha256" {
+			t.Errorf("expected ChecksumAlgorithm to be %q, got %q", "sha256", algorithm)
+		}
+	})
+
+	t.Run("Invalid FILE_NOT_EMPTY", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envFileNotEmpty: "invalid",
+			}
+			return env[key]
+		}
+
+		_, err := NewFileChecker("example", "/var/run/ready", 1*time.Second, mockEnv)
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := fmt.Sprintf("invalid %s value: strconv.ParseBool: parsing \"invalid\": invalid syntax", envFileNotEmpty)
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Unsupported checksum algorithm", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envFileChecksum: "md5:d41d8cd98f00b204e9800998ecf8427e",
+			}
+			return env[key]
+		}
+
+		_, err := NewFileChecker("example", "/var/run/ready", 1*time.Second, mockEnv)
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := fmt.Sprintf("invalid %s value: unsupported checksum algorithm: md5", envFileChecksum)
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Invalid checksum length", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envFileChecksum: "sha256:abcd",
+			}
+			return env[key]
+		}
+
+		_, err := NewFileChecker("example", "/var/run/ready", 1*time.Second, mockEnv)
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := fmt.Sprintf("invalid %s value: invalid sha256 checksum: abcd", envFileChecksum)
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Invalid FILE_MATCH", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envFileMatch: "(",
+			}
+			return env[key]
+		}
+
+		_, err := NewFileChecker("example", "/var/run/ready", 1*time.Second, mockEnv)
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := fmt.Sprintf("invalid %s value: error parsing regexp: missing closing ): `(`", envFileMatch)
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Invalid FILE_MODIFIED_AFTER", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envFileModifiedAfter: "yesterday",
+			}
+			return env[key]
+		}
+
+		_, err := NewFileChecker("example", "/var/run/ready", 1*time.Second, mockEnv)
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := fmt.Sprintf("invalid %s value: parsing time \"yesterday\" as \"2006-01-02T15:04:05Z07:00\": cannot parse \"yesterday\" as \"2006\"", envFileModifiedAfter)
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+}
+
+func TestFileChecker(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Valid File check", func(t *testing.T) {
+		t.Parallel()
+
+		path := writeTempFile(t, "")
+
+		checker, err := NewFileChecker("example", "file://"+path, 1*time.Second, func(string) string { return "" })
+		if err != nil {
+			t.Fatalf("failed to create FileChecker: %q", err)
+		}
+
+		if err := checker.Check(context.Background()); err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+	})
+
+	t.Run("Missing file", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "missing")
+
+		checker, err := NewFileChecker("example", "file://"+path, 1*time.Second, func(string) string { return "" })
+		if err != nil {
+			t.Fatalf("failed to create FileChecker: %q", err)
+		}
+
+		err = checker.Check(context.Background())
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := fmt.Sprintf("stat %s: no such file or directory", path)
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Empty file", func(t *testing.T) {
+		t.Parallel()
+
+		path := writeTempFile(t, "")
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envFileNotEmpty: "true",
+			}
+			return env[key]
+		}
+
+		checker, err := NewFileChecker("example", path, 1*time.Second, mockEnv)
+		if err != nil {
+			t.Fatalf("failed to create FileChecker: %q", err)
+		}
+
+		err = checker.Check(context.Background())
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := fmt.Sprintf("%s is empty", path)
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Empty directory", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envFileNotEmpty: "true",
+			}
+			return env[key]
+		}
+
+		checker, err := NewFileChecker("example", dir, 1*time.Second, mockEnv)
+		if err != nil {
+			t.Fatalf("failed to create FileChecker: %q", err)
+		}
+
+		err = checker.Check(context.Background())
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := fmt.Sprintf("%s is empty", dir)
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+
+		if err := os.WriteFile(filepath.Join(dir, "secret"), []byte("s3cr3t"), 0o600); err != nil {
+			t.Fatalf("failed to write file: %q", err)
+		}
+
+		if err := checker.Check(context.Background()); err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+	})
+
+	t.Run("Content conditions on directory", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envFileMatch: "done",
+			}
+			return env[key]
+		}
+
+		checker, err := NewFileChecker("example", dir, 1*time.Second, mockEnv)
+		if err != nil {
+			t.Fatalf("failed to create FileChecker: %q", err)
+		}
+
+		err = checker.Check(context.Background())
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := fmt.Sprintf("%s is a directory", dir)
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Valid checksum and content", func(t *testing.T) {
+		t.Parallel()
+
+		path := writeTempFile(t, "test")
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envFileChecksum: "sha256:9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+				envFileMatch:    "^te",
+			}
+			return env[key]
+		}
+
+		checker, err := NewFileChecker("example", path, 1*time.Second, mockEnv)
+		if err != nil {
+			t.Fatalf("failed to create FileChecker: %q", err)
+		}
+
+		if err := checker.Check(context.Background()); err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+	})
+
+	t.Run("Checksum mismatch", func(t *testing.T) {
+		t.Parallel()
+
+		path := writeTempFile(t, "partial")
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envFileChecksum: "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+			}
+			return env[key]
+		}
+
+		checker, err := NewFileChecker("example", path, 1*time.Second, mockEnv)
+		if err != nil {
+			t.Fatalf("failed to create FileChecker: %q", err)
+		}
+
+		err = checker.Check(context.Background())
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := "sha256 checksum mismatch: got 9834a14ab9bcaa0f6a8da71073617eac8f004e596a3fa11d807b84631b825d9d, expected 9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Content does not match", func(t *testing.T) {
+		t.Parallel()
+
+		path := writeTempFile(t, "pending")
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envFileMatch: "^done$",
+			}
+			return env[key]
+		}
+
+		checker, err := NewFileChecker("example", path, 1*time.Second, mockEnv)
+		if err != nil {
+			t.Fatalf("failed to create FileChecker: %q", err)
+		}
+
+		err = checker.Check(context.Background())
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := fmt.Sprintf("content of %s does not match \"^done$\"", path)
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Modified too early", func(t *testing.T) {
+		t.Parallel()
+
+		path := writeTempFile(t, "done")
+		modTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatalf("failed to change modification time: %q", err)
+		}
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envFileModifiedAfter: "2024-06-01T00:00:00Z",
+			}
+			return env[key]
+		}
+
+		checker, err := NewFileChecker("example", path, 1*time.Second, mockEnv)
+		if err != nil {
+			t.Fatalf("failed to create FileChecker: %q", err)
+		}
+
+		err = checker.Check(context.Background())
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := fmt.Sprintf("%s was last modified at 2024-01-01T00:00:00Z, expected after 2024-06-01T00:00:00Z", path)
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+}
@@ -4,11 +4,18 @@ import (
 	"context"
 	"crypto/tls"
 	"fmt"
+	"log/slog"
+	"net"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/containeroo/portpatrol/pkg/httputils"
+	"github.com/quic-go/quic-go/http3"
+	"golang.org/x/net/http/httpproxy"
+	"golang.org/x/net/http2"
 )
 
 const (
@@ -17,10 +24,16 @@ const (
 	envHTTPAllowDuplicateHeaders string = "HTTP_ALLOW_DUPLICATE_HEADERS"
 	envHTTPExpectedStatusCodes   string = "HTTP_EXPECTED_STATUS_CODES"
 	envHTTPSkipTLSVerify         string = "HTTP_SKIP_TLS_VERIFY"
+	envHTTPVersion               string = "HTTP_VERSION"
 
 	defaultHTTPMethod                string = http.MethodGet
 	defaultHTTPAllowDuplicateHeaders bool   = false
 	defaultHTTPSkipTLSVerify         bool   = false
+
+	httpVersion11  string = "1.1" // HTTP/1.1, also over TLS.
+	httpVersion2   string = "2"   // HTTP/2 over TLS, negotiated with ALPN.
+	httpVersionH2C string = "h2c" // HTTP/2 over cleartext TCP, with prior knowledge.
+	httpVersion3   string = "3"   // HTTP/3 over QUIC.
 )
 
 var defaultHTTPExpectedStatusCodes = []int{200} // Slice cannot be consts
@@ -32,8 +45,10 @@ type HTTPChecker struct {
 	ExpectedStatusCodes []int             // The expected status codes.
 	Method              string            // The HTTP method to use.
 	Headers             map[string]string // The HTTP headers to include in the request.
+	Version             string            // The HTTP version which must be negotiated. If empty, any version is accepted.
 	client              *http.Client      // The HTTP client to use for the request.
 	DialTimeout         time.Duration     // The timeout for dialing the target.
+	protocol            string            // The protocol negotiated in the last check, e.g. "HTTP/2.0".
 }
 
 // String returns the name of the checker.
@@ -90,22 +105,134 @@ func NewHTTPChecker(name, address string, timeout time.Duration, getEnv func(str
 		}
 	}
 
+	// Connections originate from the configured source address and interface
+	dialer, err := newDialer("tcp", timeout, getEnv)
+	if err != nil {
+		return nil, err
+	}
+
+	// Determine the HTTP version which must be negotiated
+	version, err := parseHTTPVersion(getEnv, address)
+	if err != nil {
+		return nil, err
+	}
+	checker.Version = version
+
+	// Connections start with a PROXY protocol header, if configured. Through a proxy, the header would announce
+	// the proxy instead of the target as destination; h2c connections never go through a proxy.
+	if getEnv(envProxyProtocol) != "" && version != httpVersionH2C && httpProxied(getEnv, address) {
+		return nil, fmt.Errorf("invalid %s value: the header cannot be sent through a proxy", envProxyProtocol)
+	}
+	transportDialer, err := withProxyProtocol(dialer, address, getEnv)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create the HTTP client with the given timeout and TLS configuration
 	checker.client = &http.Client{
-		Timeout: timeout,
-		Transport: &http.Transport{
-			Proxy: http.ProxyFromEnvironment,
-			TLSClientConfig: &tls.Config{
-				InsecureSkipVerify: skipTLSVerify,
-			},
-		},
+		Timeout:   timeout,
+		Transport: newHTTPTransport(version, transportDialer, &tls.Config{InsecureSkipVerify: skipTLSVerify}),
 	}
 
 	return &checker, nil
 }
 
+// parseHTTPVersion reads the HTTP version from HTTP_VERSION and verifies it can be used for the address.
+func parseHTTPVersion(getEnv func(string) string, address string) (string, error) {
+	versionStr := getEnv(envHTTPVersion)
+	if versionStr == "" {
+		return "", nil
+	}
+
+	version := strings.ToLower(versionStr)
+	switch version {
+	case httpVersion11, httpVersion2, httpVersionH2C, httpVersion3:
+	default:
+		return "", fmt.Errorf("invalid %s value: %s", envHTTPVersion, versionStr)
+	}
+
+	u, err := url.Parse(address)
+	if err != nil {
+		return "", fmt.Errorf("invalid address %s: %w", address, err)
+	}
+
+	// HTTP/2 uses TLS unless it is requested as h2c, HTTP/3 always uses TLS
+	switch scheme := strings.ToLower(u.Scheme); {
+	case version == httpVersion2 && scheme != "https":
+		return "", fmt.Errorf("invalid %s value: HTTP/2 requires an https address, use h2c for cleartext HTTP/2", envHTTPVersion)
+	case version == httpVersionH2C && scheme != "http":
+		return "", fmt.Errorf("invalid %s value: h2c requires an http address", envHTTPVersion)
+	case version == httpVersion3 && scheme != "https":
+		return "", fmt.Errorf("invalid %s value: HTTP/3 requires an https address", envHTTPVersion)
+	}
+
+	// QUIC runs over UDP, so the TCP dialer and its options are not used
+	if version == httpVersion3 {
+		for _, env := range []string{envSourceAddress, envSourceInterface, envProxyProtocol} {
+			if getEnv(env) != "" {
+				return "", fmt.Errorf("invalid %s value: %s is not supported with HTTP/3", envHTTPVersion, env)
+			}
+		}
+	}
+
+	return version, nil
+}
+
+// httpProxied reports whether requests to the address go through the proxy configured in HTTP_PROXY or HTTPS_PROXY.
+func httpProxied(getEnv func(string) string, address string) bool {
+	u, err := url.Parse(address)
+	if err != nil {
+		return false
+	}
+
+	proxyURL, err := (&httpproxy.Config{
+		HTTPProxy:  lookupEnvAnyCase(getEnv, "HTTP_PROXY"),
+		HTTPSProxy: lookupEnvAnyCase(getEnv, "HTTPS_PROXY"),
+		NoProxy:    lookupEnvAnyCase(getEnv, envNoProxy),
+	}).ProxyFunc()(u)
+	return err == nil && proxyURL != nil
+}
+
+// newHTTPTransport creates the transport speaking the given HTTP version.
+// Without a version, the standard transport is used, which speaks HTTP/1.1.
+func newHTTPTransport(version string, dialer contextDialer, tlsConfig *tls.Config) http.RoundTripper {
+	switch version {
+	case httpVersionH2C:
+		// Cleartext HTTP/2 has no negotiation, so the connection starts with HTTP/2 right away
+		return &http2.Transport{
+			AllowHTTP: true,
+			DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+				return dialer.DialContext(ctx, network, addr)
+			},
+		}
+	case httpVersion3:
+		return &http3.Transport{
+			TLSClientConfig: tlsConfig,
+		}
+	}
+
+	transport := &http.Transport{
+		DialContext:     dialer.DialContext,
+		Proxy:           http.ProxyFromEnvironment,
+		TLSClientConfig: tlsConfig,
+	}
+
+	switch version {
+	case httpVersion11:
+		// A non-nil, empty map disables HTTP/2
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	case httpVersion2:
+		// HTTP/2 is only attempted by default if neither a dialer nor a TLS configuration is set
+		transport.ForceAttemptHTTP2 = true
+	}
+
+	return transport
+}
+
 // Check performs an HTTP request and checks the response.
 func (c *HTTPChecker) Check(ctx context.Context) error {
+	c.protocol = ""
+
 	// Create the HTTP request
 	req, err := http.NewRequestWithContext(ctx, c.Method, c.Address, nil)
 	if err != nil {
@@ -124,6 +251,12 @@ func (c *HTTPChecker) Check(ctx context.Context) error {
 	}
 	defer resp.Body.Close()
 
+	// Check the negotiated protocol
+	c.protocol = resp.Proto
+	if c.Version != "" && !httpVersionNegotiated(c.Version, resp) {
+		return fmt.Errorf("expected HTTP version %s, got %s", c.Version, resp.Proto)
+	}
+
 	// Check the response status code
 	for _, code := range c.ExpectedStatusCodes {
 		if resp.StatusCode == code {
@@ -133,3 +266,26 @@ func (c *HTTPChecker) Check(ctx context.Context) error {
 
 	return fmt.Errorf("unexpected status code: got %d, expected one of %v", resp.StatusCode, c.ExpectedStatusCodes)
 }
+
+// Stats returns the negotiated protocol of the last check, if an HTTP version is required.
+func (c *HTTPChecker) Stats() []slog.Attr {
+	if c.Version == "" || c.protocol == "" {
+		return nil
+	}
+
+	return []slog.Attr{slog.String("protocol", c.protocol)}
+}
+
+// httpVersionNegotiated reports whether the response was received with the given HTTP version.
+func httpVersionNegotiated(version string, resp *http.Response) bool {
+	switch version {
+	case httpVersion11:
+		return resp.ProtoMajor == 1 && resp.ProtoMinor == 1
+	case httpVersion2, httpVersionH2C:
+		return resp.ProtoMajor == 2
+	case httpVersion3:
+		return resp.ProtoMajor == 3
+	default:
+		return true
+	}
+}
@@ -2,7 +2,10 @@ package checker
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
+	"log/slog"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -11,6 +14,10 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/quic-go/quic-go/http3"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 )
 
 func TestHTTPChecker(t *testing.T) {
@@ -405,6 +412,171 @@ func TestHTTPChecker(t *testing.T) {
 	})
 }
 
+func TestNewHTTPCheckerVersion(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		address  string
+		env      map[string]string
+		expected string
+	}{
+		{
+			name:     "Invalid version",
+			address:  "https://localhost:8443",
+			env:      map[string]string{envHTTPVersion: "2.1"},
+			expected: fmt.Sprintf("invalid %s value: 2.1", envHTTPVersion),
+		},
+		{
+			name:     "HTTP/2 without TLS",
+			address:  "http://localhost:8080",
+			env:      map[string]string{envHTTPVersion: httpVersion2},
+			expected: fmt.Sprintf("invalid %s value: HTTP/2 requires an https address, use h2c for cleartext HTTP/2", envHTTPVersion),
+		},
+		{
+			name:     "h2c with TLS",
+			address:  "https://localhost:8443",
+			env:      map[string]string{envHTTPVersion: httpVersionH2C},
+			expected: fmt.Sprintf("invalid %s value: h2c requires an http address", envHTTPVersion),
+		},
+		{
+			name:     "HTTP/3 without TLS",
+			address:  "http://localhost:8080",
+			env:      map[string]string{envHTTPVersion: httpVersion3},
+			expected: fmt.Sprintf("invalid %s value: HTTP/3 requires an https address", envHTTPVersion),
+		},
+		{
+			name:     "HTTP/3 with PROXY protocol",
+			address:  "https://localhost:8443",
+			env:      map[string]string{envHTTPVersion: httpVersion3, envProxyProtocol: proxyProtocolV2},
+			expected: fmt.Sprintf("invalid %s value: %s is not supported with HTTP/3", envHTTPVersion, envProxyProtocol),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewHTTPChecker("example", tt.address, 1*time.Second, func(key string) string { return tt.env[key] })
+			if err == nil {
+				t.Fatal("expected an error, got none")
+			}
+			if err.Error() != tt.expected {
+				t.Errorf("expected error %q, got %q", tt.expected, err.Error())
+			}
+		})
+	}
+}
+
+func TestHTTPCheckerVersion(t *testing.T) {
+	t.Parallel()
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	// HTTP/1.1 only, HTTP/2 over TLS and HTTP/2 with prior knowledge over cleartext
+	http1Server := httptest.NewTLSServer(handler)
+	t.Cleanup(http1Server.Close)
+
+	http2Server := httptest.NewUnstartedServer(handler)
+	http2Server.EnableHTTP2 = true
+	http2Server.StartTLS()
+	t.Cleanup(http2Server.Close)
+
+	h2cServer := httptest.NewServer(h2c.NewHandler(handler, &http2.Server{}))
+	t.Cleanup(h2cServer.Close)
+
+	// HTTP/3 over QUIC, with the certificate of the test server
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen for QUIC: %q", err)
+	}
+	http3Server := &http3.Server{
+		Handler:   handler,
+		TLSConfig: http3.ConfigureTLSConfig(&tls.Config{Certificates: http1Server.TLS.Certificates}),
+	}
+	go func() { _ = http3Server.Serve(conn) }()
+	t.Cleanup(func() { http3Server.Close(); conn.Close() })
+
+	tests := []struct {
+		name     string
+		address  string
+		version  string
+		expected string
+		protocol string
+	}{
+		{
+			name:     "HTTP/1.1 from an HTTP/2 server",
+			address:  http2Server.URL,
+			version:  httpVersion11,
+			protocol: "HTTP/1.1",
+		},
+		{
+			name:     "HTTP/2",
+			address:  http2Server.URL,
+			version:  httpVersion2,
+			protocol: "HTTP/2.0",
+		},
+		{
+			name:     "HTTP/2 not negotiated",
+			address:  http1Server.URL,
+			version:  httpVersion2,
+			expected: "expected HTTP version 2, got HTTP/1.1",
+			protocol: "HTTP/1.1",
+		},
+		{
+			name:     "h2c",
+			address:  h2cServer.URL,
+			version:  httpVersionH2C,
+			protocol: "HTTP/2.0",
+		},
+		{
+			name:     "HTTP/3",
+			address:  "https://" + conn.LocalAddr().String(),
+			version:  httpVersion3,
+			protocol: "HTTP/3.0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockEnv := func(key string) string {
+				env := map[string]string{
+					envHTTPVersion:       tt.version,
+					envHTTPSkipTLSVerify: "true",
+				}
+				return env[key]
+			}
+
+			checker, err := NewHTTPChecker("example", tt.address, 2*time.Second, mockEnv)
+			if err != nil {
+				t.Fatalf("failed to create HTTPChecker: %q", err)
+			}
+
+			err = checker.Check(context.Background())
+			if tt.expected == "" && err != nil {
+				t.Fatalf("expected no error, got %q", err)
+			}
+			if tt.expected != "" {
+				if err == nil {
+					t.Fatal("expected an error, got none")
+				}
+				if err.Error() != tt.expected {
+					t.Errorf("expected error %q, got %q", tt.expected, err.Error())
+				}
+			}
+
+			expected := fmt.Sprint([]slog.Attr{slog.String("protocol", tt.protocol)})
+			if stats := fmt.Sprint(checker.(StatsReporter).Stats()); stats != expected {
+				t.Errorf("expected stats %s, got %s", expected, stats)
+			}
+		})
+	}
+}
+
 func TestIsValidCheckTypeWithProxy(t *testing.T) {
 	t.Run("Invalid HTTP check (invalid proxy)", func(t *testing.T) {
 		// Do not use t.Parallel here since we're modifying global state (environment variables)
@@ -2,27 +2,100 @@ package checker
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"net"
 	"os"
+	"strconv"
 	"strings"
 	"sync/atomic"
 	"time"
 )
 
 const (
-	envICMPReadTimeout string = "ICMP_READ_TIMEOUT"
+	envICMPReadTimeout   string = "ICMP_READ_TIMEOUT"
+	envICMPCount         string = "ICMP_COUNT"
+	envICMPProbeInterval string = "ICMP_PROBE_INTERVAL"
+	envICMPMaxPacketLoss string = "ICMP_MAX_PACKET_LOSS"
+	envICMPMaxRTT        string = "ICMP_MAX_RTT"
+	envICMPMode          string = "ICMP_MODE"
+	envICMPAddresses     string = "ICMP_ADDRESSES"
 
-	defaultICMPReadTimeout time.Duration = time.Second * 1
+	defaultICMPReadTimeout   time.Duration = time.Second * 1
+	defaultICMPCount         int           = 1
+	defaultICMPProbeInterval time.Duration = 100 * time.Millisecond
+	defaultICMPMaxPacketLoss float64       = 0
+	defaultICMPMaxRTT        time.Duration = 0 // No limit
+	defaultICMPMode          string        = icmpModeAuto
 )
 
+// icmpCheckers counts the created ICMP checkers, so each checker of the process uses its own identifier.
+var icmpCheckers atomic.Uint32
+
+// ICMPStats holds the statistics of the echo requests sent during a check.
+type ICMPStats struct {
+	Sent     int           // The number of echo requests sent.
+	Received int           // The number of valid echo replies received.
+	Min      time.Duration // The minimum round-trip time.
+	Avg      time.Duration // The average round-trip time.
+	Max      time.Duration // The maximum round-trip time.
+	Jitter   time.Duration // The mean deviation between consecutive round-trip times.
+}
+
+// Loss returns the packet loss in percent.
+func (s ICMPStats) Loss() float64 {
+	if s.Sent == 0 {
+		return 0
+	}
+	return float64(s.Sent-s.Received) / float64(s.Sent) * 100
+}
+
+// newICMPStats computes the statistics for the given number of sent requests and the round-trip times of the replies.
+func newICMPStats(sent int, rtts []time.Duration) ICMPStats {
+	stats := ICMPStats{Sent: sent, Received: len(rtts)}
+	if len(rtts) == 0 {
+		return stats
+	}
+
+	var sum, deviation time.Duration
+	stats.Min, stats.Max = rtts[0], rtts[0]
+	for i, rtt := range rtts {
+		sum += rtt
+		stats.Min = min(stats.Min, rtt)
+		stats.Max = max(stats.Max, rtt)
+		if i > 0 {
+			deviation += (rtt - rtts[i-1]).Abs()
+		}
+	}
+	stats.Avg = sum / time.Duration(len(rtts))
+	if len(rtts) > 1 {
+		stats.Jitter = deviation / time.Duration(len(rtts)-1)
+	}
+
+	return stats
+}
+
 // ICMPChecker implements a basic ICMP ping checker.
 type ICMPChecker struct {
-	Name         string        // The name of the checker.
-	Address      string        // The address of the target.
-	Protocol     Protocol      // The protocol to use for the connection.
-	ReadTimeout  time.Duration // The timeout for reading the ICMP reply.
-	WriteTimeout time.Duration // The timeout for writing the ICMP request.
+	Name          string        // The name of the checker.
+	Address       string        // The address of the target.
+	Protocol      Protocol      // The protocol to use for all addresses. If nil, it is chosen by the family of each resolved address.
+	ReadTimeout   time.Duration // The timeout for reading the ICMP reply.
+	WriteTimeout  time.Duration // The timeout for writing the ICMP request.
+	Count         int           // The number of echo requests sent per check. Values below 1 are treated as 1.
+	ProbeInterval time.Duration // The time between echo requests.
+	MaxPacketLoss float64       // The maximum accepted packet loss in percent.
+	MaxRTT        time.Duration // The maximum accepted average round-trip time. If 0, there is no limit.
+	Mode          string        // The socket mode (auto, raw or udp).
+	Family        string        // The address family of the resolved addresses (any, ipv4 or ipv6).
+	Addresses     string        // Which of the resolved addresses are checked (first, any or all).
+	TraceMaxHops  int           // The maximum number of hops probed when tracing the path to the target.
+	source        source        // The local address and network interface the echo requests are sent from.
+	resolve       resolveFunc   // The function resolving the target. If nil, lookupIPs is used.
+	stats         ICMPStats     // The statistics of the last check.
+	identifier    uint16        // The identifier of the echo requests.
+	sequence      atomic.Uint32 // The sequence number of the last echo request.
 }
 
 // String returns the name of the checker.
@@ -37,17 +110,18 @@ func NewICMPChecker(name, address string, dialTimeout time.Duration, getEnv func
 	address = strings.TrimPrefix(address, "icmp://")
 
 	checker := ICMPChecker{
-		Name:         name,
-		Address:      address,
-		ReadTimeout:  defaultICMPReadTimeout,
-		WriteTimeout: dialTimeout,
-	}
-
-	protocol, err := newProtocol(checker.Address)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create ICMP protocol: %w", err)
+		Name:          name,
+		Address:       address,
+		ReadTimeout:   defaultICMPReadTimeout,
+		WriteTimeout:  dialTimeout,
+		Count:         defaultICMPCount,
+		ProbeInterval: defaultICMPProbeInterval,
+		MaxPacketLoss: defaultICMPMaxPacketLoss,
+		MaxRTT:        defaultICMPMaxRTT,
+		Mode:          defaultICMPMode,
+		TraceMaxHops:  defaultTraceMaxHops,
+		identifier:    uint16((os.Getpid() + int(icmpCheckers.Add(1))) & 0xffff),
 	}
-	checker.Protocol = protocol
 
 	// Determine the read timeout
 	if readTimeoutStr := getEnv(envICMPReadTimeout); readTimeoutStr != "" {
@@ -58,50 +132,294 @@ func NewICMPChecker(name, address string, dialTimeout time.Duration, getEnv func
 		checker.ReadTimeout = readTimeout
 	}
 
+	// Determine the number of echo requests per check
+	if countStr := getEnv(envICMPCount); countStr != "" {
+		count, err := strconv.Atoi(countStr)
+		if err != nil || count < 1 {
+			return nil, fmt.Errorf("invalid %s value: %s", envICMPCount, countStr)
+		}
+		checker.Count = count
+	}
+
+	// Determine the time between echo requests
+	if probeIntervalStr := getEnv(envICMPProbeInterval); probeIntervalStr != "" {
+		probeInterval, err := time.ParseDuration(probeIntervalStr)
+		if err != nil || probeInterval < 0 {
+			return nil, fmt.Errorf("invalid %s value: %s", envICMPProbeInterval, probeIntervalStr)
+		}
+		checker.ProbeInterval = probeInterval
+	}
+
+	// Determine the maximum accepted packet loss
+	if maxPacketLossStr := getEnv(envICMPMaxPacketLoss); maxPacketLossStr != "" {
+		maxPacketLoss, err := strconv.ParseFloat(strings.TrimSuffix(maxPacketLossStr, "%"), 64)
+		if err != nil || maxPacketLoss < 0 || maxPacketLoss > 100 {
+			return nil, fmt.Errorf("invalid %s value: %s", envICMPMaxPacketLoss, maxPacketLossStr)
+		}
+		checker.MaxPacketLoss = maxPacketLoss
+	}
+
+	// Determine the maximum accepted average round-trip time
+	if maxRTTStr := getEnv(envICMPMaxRTT); maxRTTStr != "" {
+		maxRTT, err := time.ParseDuration(maxRTTStr)
+		if err != nil || maxRTT <= 0 {
+			return nil, fmt.Errorf("invalid %s value: %s", envICMPMaxRTT, maxRTTStr)
+		}
+		checker.MaxRTT = maxRTT
+	}
+
+	// Determine whether raw or unprivileged datagram sockets are used
+	if modeStr := getEnv(envICMPMode); modeStr != "" {
+		mode := strings.ToLower(modeStr)
+		if mode != icmpModeAuto && mode != icmpModeRaw && mode != icmpModeUDP {
+			return nil, fmt.Errorf("invalid %s value: %s", envICMPMode, modeStr)
+		}
+		checker.Mode = mode
+	}
+
+	// Determine the address family and which of the resolved addresses are checked
+	family, err := parseIPFamily(getEnv)
+	if err != nil {
+		return nil, err
+	}
+	checker.Family = family
+
+	// Determine the maximum number of hops when tracing the path
+	traceMaxHops, err := parseTraceMaxHops(getEnv)
+	if err != nil {
+		return nil, err
+	}
+	checker.TraceMaxHops = traceMaxHops
+
+	// Determine the local address and network interface the echo requests are sent from
+	src, err := parseSource(getEnv)
+	if err != nil {
+		return nil, err
+	}
+	checker.source = src
+
+	// A source address only reaches targets of its own family
+	if src.IP != nil && checker.Family == ipFamilyAny {
+		checker.Family = ipFamilyIPv4
+		if src.IP.To4() == nil {
+			checker.Family = ipFamilyIPv6
+		}
+	}
+
+	addresses, err := parseAddressMode(getEnv, envICMPAddresses)
+	if err != nil {
+		return nil, err
+	}
+	checker.Addresses = addresses
+
+	// Fail early if the target does not resolve, the addresses are resolved again on every check
+	if _, err := lookupIPs(context.Background(), checker.Address, checker.Family); err != nil {
+		return nil, fmt.Errorf("failed to create ICMP protocol: invalid or unresolvable address: %s", checker.Address)
+	}
+
 	return &checker, nil
 }
 
-// Check performs an ICMP check on the target.
+// Check resolves the target and sends the configured number of echo requests to the selected addresses.
+// The packet loss and round-trip times are evaluated for each address.
 func (c *ICMPChecker) Check(ctx context.Context) error {
-	// Resolve the IP address
-	dst, err := net.ResolveIPAddr(c.Protocol.Network(), c.Address)
+	c.stats = ICMPStats{}
+
+	// Resolve the addresses on every check, so DNS changes are picked up
+	ips, err := c.resolveIPs(ctx)
+	if err != nil {
+		return err
+	}
+	if c.Addresses != addressModeAny && c.Addresses != addressModeAll {
+		ips = ips[:1]
+	}
+
+	var errs []error
+	var sent int
+	var rtts []time.Duration
+	for _, ip := range ips {
+		addrSent, addrRTTs, err := c.checkAddress(ctx, ip)
+		sent += addrSent
+		rtts = append(rtts, addrRTTs...)
+		c.stats = newICMPStats(sent, rtts)
+
+		if err == nil {
+			if c.Addresses == addressModeAny {
+				return nil
+			}
+			continue
+		}
+		if ctx.Err() != nil {
+			return err
+		}
+		if len(ips) > 1 {
+			err = fmt.Errorf("%s: %w", ip, err)
+		}
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// checkAddress sends the configured number of echo requests to the address and evaluates the packet loss and round-trip times.
+// It returns the number of sent requests and the round-trip times of the replies.
+// If no reply is received, the error of the last echo request is returned.
+func (c *ICMPChecker) checkAddress(ctx context.Context, ip net.IP) (int, []time.Duration, error) {
+	protocol := c.protocolFor(ip)
+
+	listenAddress, err := c.source.listenAddress(ip)
 	if err != nil {
-		return fmt.Errorf("failed to resolve IP address: %w", err)
+		return 0, nil, err
 	}
 
 	// Listen for ICMP packets
-	conn, err := c.Protocol.ListenPacket(ctx, c.Protocol.Network(), "0.0.0.0")
+	conn, err := protocol.ListenPacket(ctx, protocol.Network(), listenAddress)
 	if err != nil {
-		return fmt.Errorf("failed to listen for ICMP packets: %w", err)
+		return 0, nil, fmt.Errorf("failed to listen for ICMP packets: %w", err)
 	}
 	defer conn.Close()
 
-	identifier := uint16(os.Getpid() & 0xffff)                    // Create a unique identifier
-	sequence := uint16(atomic.AddUint32(new(uint32), 1) & 0xffff) // Create a unique sequence number
+	dst := &net.IPAddr{IP: ip}
 
-	// Make the ICMP request
-	msg, err := c.Protocol.MakeRequest(identifier, sequence)
+	count := max(c.Count, 1)
+	rtts := make([]time.Duration, 0, count)
+	var lastErr error
+	for i := range count {
+		// Wait between echo requests, like ping does
+		if i > 0 && c.ProbeInterval > 0 {
+			select {
+			case <-ctx.Done():
+				return i, rtts, ctx.Err()
+			case <-time.After(c.ProbeInterval):
+			}
+		}
+
+		// Sequence numbers continue across checks, so late replies to earlier requests are not mistaken for the current one
+		sequence := uint16(c.sequence.Add(1))
+		rtt, err := c.probe(ctx, protocol, conn, dst, c.identifier, sequence)
+		if err != nil {
+			if ctx.Err() != nil {
+				return i + 1, rtts, err
+			}
+			lastErr = err
+			continue
+		}
+		rtts = append(rtts, rtt)
+	}
+
+	if len(rtts) == 0 {
+		return count, rtts, lastErr
+	}
+
+	stats := newICMPStats(count, rtts)
+	if loss := stats.Loss(); loss > c.MaxPacketLoss {
+		return count, rtts, fmt.Errorf("packet loss %.1f%% exceeds %.1f%% (%d/%d replies received)", loss, c.MaxPacketLoss, stats.Received, stats.Sent)
+	}
+
+	if c.MaxRTT > 0 && stats.Avg > c.MaxRTT {
+		return count, rtts, fmt.Errorf("average round-trip time %s exceeds %s", stats.Avg, c.MaxRTT)
+	}
+
+	return count, rtts, nil
+}
+
+// Trace sends echo requests with increasing TTLs to the first resolved address of the target and returns the hosts answering them.
+// Routers report expired echo requests with ICMP time exceeded messages, which only raw sockets receive,
+// so tracing requires the CAP_NET_RAW capability even if the checks use datagram sockets.
+func (c *ICMPChecker) Trace(ctx context.Context) ([]Hop, error) {
+	ips, err := c.resolveIPs(ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	ip := ips[0]
 
-	// Write the ICMP request
-	if err := c.writeICMPRequest(ctx, conn, msg, dst); err != nil {
-		return err
+	// Datagram sockets do not receive time exceeded messages, so a raw socket is used regardless of the mode
+	protocol := c.Protocol
+	if protocol == nil {
+		protocol = newProtocol(ip, icmpModeRaw, c.source.Interface)
 	}
 
-	// Read the ICMP reply with context
-	reply, err := c.readICMPReply(ctx, conn)
+	conn, err := listenTrace(ctx, protocol, c.source, ip)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	defer conn.Close()
 
-	// Validate the ICMP reply
-	if err := c.validateICMPReply(ctx, reply, identifier, sequence); err != nil {
-		return err
+	dst := &net.IPAddr{IP: ip}
+	return traceHops(c.TraceMaxHops, ip, func(ttl int) (time.Duration, error) {
+		if err := protocol.SetTTL(ttl); err != nil {
+			return 0, fmt.Errorf("failed to set TTL: %w", err)
+		}
+		return c.probe(ctx, protocol, conn, dst, c.identifier, uint16(c.sequence.Add(1)))
+	})
+}
+
+// resolveIPs resolves the target into the addresses of the configured family.
+func (c *ICMPChecker) resolveIPs(ctx context.Context) ([]net.IP, error) {
+	resolve := c.resolve
+	if resolve == nil {
+		resolve = lookupIPs
 	}
 
-	return nil
+	ips, err := resolve(ctx, c.Address, c.Family)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve IP address: %w", err)
+	}
+
+	return ips, nil
+}
+
+// protocolFor returns the configured protocol or, if none is configured, the protocol matching the family of the address.
+func (c *ICMPChecker) protocolFor(ip net.IP) Protocol {
+	if c.Protocol != nil {
+		return c.Protocol
+	}
+
+	return newProtocol(ip, c.Mode, c.source.Interface)
+}
+
+// Stats returns the packet loss and round-trip times of the last check.
+func (c *ICMPChecker) Stats() []slog.Attr {
+	if c.stats.Sent == 0 {
+		return nil
+	}
+
+	attrs := []slog.Attr{
+		slog.Int("sent", c.stats.Sent),
+		slog.Int("received", c.stats.Received),
+		slog.String("loss", fmt.Sprintf("%.1f%%", c.stats.Loss())),
+	}
+	if c.stats.Received > 0 {
+		attrs = append(attrs,
+			slog.Duration("rtt_min", c.stats.Min),
+			slog.Duration("rtt_avg", c.stats.Avg),
+			slog.Duration("rtt_max", c.stats.Max),
+			slog.Duration("rtt_jitter", c.stats.Jitter),
+		)
+	}
+
+	return attrs
+}
+
+// probe sends a single echo request and returns the round-trip time of the valid reply.
+// The round-trip time is also returned for ICMP error messages, e.g. of routers answering a trace.
+func (c *ICMPChecker) probe(ctx context.Context, protocol Protocol, conn net.PacketConn, dst net.Addr, identifier, sequence uint16) (time.Duration, error) {
+	// Make the ICMP request
+	msg, err := protocol.MakeRequest(identifier, sequence)
+	if err != nil {
+		return 0, err
+	}
+
+	start := time.Now()
+
+	// Write the ICMP request
+	if err := c.writeICMPRequest(ctx, conn, msg, dst); err != nil {
+		return 0, err
+	}
+
+	// Wait for the ICMP reply
+	err = c.awaitICMPReply(ctx, protocol, conn, dst, identifier, sequence)
+	return time.Since(start), err
 }
 
 // writeICMPRequest handles writing the ICMP request.
@@ -128,40 +446,86 @@ func (c *ICMPChecker) writeICMPRequest(ctx context.Context, conn net.PacketConn,
 	}
 }
 
-// readICMPReply handles reading the ICMP reply.
-func (c *ICMPChecker) readICMPReply(ctx context.Context, conn net.PacketConn) ([]byte, error) {
-	// Set the read deadline
+// awaitICMPReply reads packets until the reply to the echo request arrives or the read timeout expires.
+// Packets not belonging to the echo request, like the replies to other processes or echo replies not sent by dst, are discarded.
+// ICMP error messages sent in response to the echo request are returned with the address of the reporting host.
+func (c *ICMPChecker) awaitICMPReply(ctx context.Context, protocol Protocol, conn net.PacketConn, dst net.Addr, identifier, sequence uint16) error {
+	// Set the read deadline once, so unrelated packets do not extend the wait
 	if err := conn.SetReadDeadline(time.Now().Add(c.ReadTimeout)); err != nil {
-		return nil, fmt.Errorf("failed to set read deadline: %w", err)
+		return fmt.Errorf("failed to set read deadline: %w", err)
 	}
 
+	for {
+		reply, peer, err := c.readICMPReply(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		err = c.validateICMPReply(ctx, protocol, reply, identifier, sequence)
+
+		var unrelatedErr *unrelatedPacketError
+		if errors.As(err, &unrelatedErr) {
+			continue
+		}
+
+		// Only the target answers the echo request, while error messages are reported by any host on the path
+		if err == nil && !addrIP(peer).Equal(addrIP(dst)) {
+			continue
+		}
+
+		var icmpErr *icmpError
+		if errors.As(err, &icmpErr) {
+			if ip := addrIP(peer); ip != nil {
+				icmpErr.from = ip.String()
+			}
+		}
+
+		return err
+	}
+}
+
+// addrIP returns the IP address of a raw or datagram socket address, or nil for other addresses.
+func addrIP(addr net.Addr) net.IP {
+	switch a := addr.(type) {
+	case *net.IPAddr:
+		return a.IP
+	case *net.UDPAddr:
+		return a.IP
+	default:
+		return nil
+	}
+}
+
+// readICMPReply handles reading a single packet and returns it along with the address of its sender.
+func (c *ICMPChecker) readICMPReply(ctx context.Context, conn net.PacketConn) ([]byte, net.Addr, error) {
 	done := make(chan error, 1)
 	reply := make([]byte, 1500)
 	var n int
+	var peer net.Addr
 
 	go func() {
 		var err error
-		n, _, err = conn.ReadFrom(reply)
+		n, peer, err = conn.ReadFrom(reply)
 		done <- err
 	}()
 
 	select {
 	case <-ctx.Done():
-		return nil, ctx.Err()
+		return nil, nil, ctx.Err()
 	case err := <-done:
 		if err != nil {
-			return nil, fmt.Errorf("failed to read ICMP reply from %s: %w", c.Address, err)
+			return nil, nil, fmt.Errorf("failed to read ICMP reply from %s: %w", c.Address, err)
 		}
-		return reply[:n], nil
+		return reply[:n], peer, nil
 	}
 }
 
 // validateICMPReply handles validating the ICMP reply.
-func (c *ICMPChecker) validateICMPReply(ctx context.Context, reply []byte, identifier, sequence uint16) error {
+func (c *ICMPChecker) validateICMPReply(ctx context.Context, protocol Protocol, reply []byte, identifier, sequence uint16) error {
 	done := make(chan error, 1)
 
 	go func() {
-		err := c.Protocol.ValidateReply(reply, identifier, sequence)
+		err := protocol.ValidateReply(reply, identifier, sequence)
 		done <- err
 	}()
 
@@ -2,8 +2,11 @@ package checker
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
+	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -19,7 +22,10 @@ func TestNewICMPChecker(t *testing.T) {
 		t.Parallel()
 
 		mockEnv := func(key string) string {
-			return "2s"
+			env := map[string]string{
+				envICMPReadTimeout: "2s",
+			}
+			return env[key]
 		}
 
 		checker, err := NewICMPChecker("TestIPv4", "icmp://google.com", 1*time.Second, mockEnv)
@@ -117,6 +123,144 @@ func TestNewICMPChecker(t *testing.T) {
 			t.Errorf("expected error %q, got %q", expected, err.Error())
 		}
 	})
+
+	t.Run("Valid Multi-Probe Configuration", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envICMPCount:         "5",
+				envICMPProbeInterval: "200ms",
+				envICMPMaxPacketLoss: "20%",
+				envICMPMaxRTT:        "150ms",
+			}
+			return env[key]
+		}
+
+		checker, err := NewICMPChecker("TestMultiProbe", "icmp://127.0.0.1", 1*time.Second, mockEnv)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		icmpChecker := checker.(*ICMPChecker)
+		if icmpChecker.Count != 5 {
+			t.Errorf("expected count of 5, got %d", icmpChecker.Count)
+		}
+		if icmpChecker.ProbeInterval != 200*time.Millisecond {
+			t.Errorf("expected probe interval of 200ms, got %v", icmpChecker.ProbeInterval)
+		}
+		if icmpChecker.MaxPacketLoss != 20 {
+			t.Errorf("expected max packet loss of 20, got %v", icmpChecker.MaxPacketLoss)
+		}
+		if icmpChecker.MaxRTT != 150*time.Millisecond {
+			t.Errorf("expected max RTT of 150ms, got %v", icmpChecker.MaxRTT)
+		}
+	})
+
+	t.Run("Invalid Count", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envICMPCount: "0",
+			}
+			return env[key]
+		}
+
+		_, err := NewICMPChecker("TestInvalidCount", "icmp://127.0.0.1", 1*time.Second, mockEnv)
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := fmt.Sprintf("invalid %s value: 0", envICMPCount)
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Invalid Mode", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envICMPMode: "privileged",
+			}
+			return env[key]
+		}
+
+		_, err := NewICMPChecker("TestInvalidMode", "icmp://127.0.0.1", 1*time.Second, mockEnv)
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := fmt.Sprintf("invalid %s value: privileged", envICMPMode)
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Invalid IP Family", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envIPFamily: "ipv5",
+			}
+			return env[key]
+		}
+
+		_, err := NewICMPChecker("TestInvalidFamily", "icmp://127.0.0.1", 1*time.Second, mockEnv)
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := fmt.Sprintf("invalid %s value: ipv5", envIPFamily)
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Address Not In IP Family", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envIPFamily: "ipv6",
+			}
+			return env[key]
+		}
+
+		_, err := NewICMPChecker("TestWrongFamily", "icmp://127.0.0.1", 1*time.Second, mockEnv)
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := "failed to create ICMP protocol: invalid or unresolvable address: 127.0.0.1"
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Invalid Max Packet Loss", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envICMPMaxPacketLoss: "120",
+			}
+			return env[key]
+		}
+
+		_, err := NewICMPChecker("TestInvalidLoss", "icmp://127.0.0.1", 1*time.Second, mockEnv)
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := fmt.Sprintf("invalid %s value: 120", envICMPMaxPacketLoss)
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
 }
 
 func TestICMPChecker(t *testing.T) {
@@ -571,6 +715,513 @@ func TestICMPChecker(t *testing.T) {
 	})
 }
 
+// newEchoMockProtocol returns a mock protocol answering echo requests, except for the given sequence numbers, whose replies are lost.
+func newEchoMockProtocol(lost map[int]bool) *testutils.MockProtocol {
+	replies := make(chan []byte, 16)
+	from := make(chan net.Addr, 16)
+	icmpv4 := &ICMPv4{}
+
+	mockPacketConn := &testutils.MockPacketConn{
+		WriteToFunc: func(b []byte, addr net.Addr) (int, error) {
+			msg, err := icmp.ParseMessage(icmpv4ProtocolNumber, b)
+			if err != nil {
+				return 0, err
+			}
+			echo := msg.Body.(*icmp.Echo)
+			if !lost[echo.Seq] {
+				reply, _ := (&icmp.Message{Type: ipv4.ICMPTypeEchoReply, Body: echo}).Marshal(nil)
+				replies <- reply
+				from <- addr
+			}
+			return len(b), nil
+		},
+		ReadFromFunc: func(b []byte) (int, net.Addr, error) {
+			select {
+			case reply := <-replies:
+				return copy(b, reply), <-from, nil
+			case <-time.After(50 * time.Millisecond):
+				return 0, nil, fmt.Errorf("i/o timeout")
+			}
+		},
+	}
+
+	return &testutils.MockProtocol{
+		MakeRequestFunc:   icmpv4.MakeRequest,
+		ValidateReplyFunc: icmpv4.ValidateReply,
+		NetworkFunc: func() string {
+			return "ip4:icmp"
+		},
+		ListenPacketFunc: func(ctx context.Context, network, address string) (net.PacketConn, error) {
+			return mockPacketConn, nil
+		},
+	}
+}
+
+func TestICMPCheckerMultiProbe(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Sequence Continues Across Checks", func(t *testing.T) {
+		t.Parallel()
+
+		checker := &ICMPChecker{
+			Name:        "TestChecker",
+			Address:     "127.0.0.1",
+			Protocol:    newEchoMockProtocol(map[int]bool{3: true}),
+			ReadTimeout: time.Second,
+			Count:       2,
+		}
+
+		if err := checker.Check(context.Background()); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		// The first request of the second check uses sequence number 3, whose reply is lost
+		err := checker.Check(context.Background())
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		if seq := checker.sequence.Load(); seq != 4 {
+			t.Errorf("expected sequence 4, got %d", seq)
+		}
+	})
+
+	t.Run("Packet Loss Within Threshold", func(t *testing.T) {
+		t.Parallel()
+
+		checker := &ICMPChecker{
+			Name:          "TestChecker",
+			Address:       "127.0.0.1",
+			Protocol:      newEchoMockProtocol(map[int]bool{2: true}),
+			ReadTimeout:   time.Second,
+			Count:         5,
+			MaxPacketLoss: 20,
+		}
+
+		if err := checker.Check(context.Background()); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if checker.stats.Sent != 5 || checker.stats.Received != 4 {
+			t.Errorf("expected 4/5 replies, got %d/%d", checker.stats.Received, checker.stats.Sent)
+		}
+
+		attrs := checker.Stats()
+		if len(attrs) != 7 || attrs[2].String() != "loss=20.0%" {
+			t.Errorf("unexpected stats: %v", attrs)
+		}
+	})
+
+	t.Run("Packet Loss Exceeds Threshold", func(t *testing.T) {
+		t.Parallel()
+
+		checker := &ICMPChecker{
+			Name:          "TestChecker",
+			Address:       "127.0.0.1",
+			Protocol:      newEchoMockProtocol(map[int]bool{1: true, 3: true}),
+			ReadTimeout:   time.Second,
+			Count:         4,
+			MaxPacketLoss: 25,
+		}
+
+		err := checker.Check(context.Background())
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := "packet loss 50.0% exceeds 25.0% (2/4 replies received)"
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("All Replies Lost", func(t *testing.T) {
+		t.Parallel()
+
+		checker := &ICMPChecker{
+			Name:          "TestChecker",
+			Address:       "127.0.0.1",
+			Protocol:      newEchoMockProtocol(map[int]bool{1: true, 2: true}),
+			ReadTimeout:   time.Second,
+			Count:         2,
+			MaxPacketLoss: 100,
+		}
+
+		err := checker.Check(context.Background())
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := "failed to read ICMP reply from 127.0.0.1: i/o timeout"
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+
+		attrs := checker.Stats()
+		if len(attrs) != 3 || attrs[2].String() != "loss=100.0%" {
+			t.Errorf("unexpected stats: %v", attrs)
+		}
+	})
+
+	t.Run("Average RTT Exceeds Limit", func(t *testing.T) {
+		t.Parallel()
+
+		checker := &ICMPChecker{
+			Name:        "TestChecker",
+			Address:     "127.0.0.1",
+			Protocol:    newEchoMockProtocol(nil),
+			ReadTimeout: time.Second,
+			Count:       2,
+			MaxRTT:      time.Nanosecond,
+		}
+
+		err := checker.Check(context.Background())
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		if !strings.HasPrefix(err.Error(), "average round-trip time ") || !strings.HasSuffix(err.Error(), " exceeds 1ns") {
+			t.Errorf("unexpected error %q", err.Error())
+		}
+	})
+}
+
+func TestICMPCheckerAddresses(t *testing.T) {
+	t.Parallel()
+
+	// The replies from 192.0.2.2 are lost
+	newProtocol := func() *testutils.MockProtocol {
+		protocol := newEchoMockProtocol(nil)
+		conn, _ := protocol.ListenPacket(context.Background(), "", "")
+		mockConn := conn.(*testutils.MockPacketConn)
+		writeTo := mockConn.WriteToFunc
+		mockConn.WriteToFunc = func(b []byte, addr net.Addr) (int, error) {
+			if addr.(*net.IPAddr).IP.Equal(net.IPv4(192, 0, 2, 2)) {
+				return len(b), nil
+			}
+			return writeTo(b, addr)
+		}
+		return protocol
+	}
+
+	resolve := func(ctx context.Context, host, family string) ([]net.IP, error) {
+		return []net.IP{net.IPv4(192, 0, 2, 1), net.IPv4(192, 0, 2, 2)}, nil
+	}
+
+	tests := []struct {
+		name      string
+		addresses string
+		expected  string
+		sent      int
+	}{
+		{
+			name:      "First Address",
+			addresses: addressModeFirst,
+			sent:      1,
+		},
+		{
+			name:      "Any Address",
+			addresses: addressModeAny,
+			sent:      1,
+		},
+		{
+			name:      "All Addresses",
+			addresses: addressModeAll,
+			expected:  "192.0.2.2: failed to read ICMP reply from example.com: i/o timeout",
+			sent:      2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			checker := &ICMPChecker{
+				Name:        "TestChecker",
+				Address:     "example.com",
+				Protocol:    newProtocol(),
+				ReadTimeout: time.Second,
+				Addresses:   tt.addresses,
+				resolve:     resolve,
+			}
+
+			err := checker.Check(context.Background())
+			if tt.expected == "" && err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if tt.expected != "" && (err == nil || err.Error() != tt.expected) {
+				t.Fatalf("expected error %q, got %v", tt.expected, err)
+			}
+
+			if checker.stats.Sent != tt.sent {
+				t.Errorf("expected %d sent requests, got %d", tt.sent, checker.stats.Sent)
+			}
+		})
+	}
+
+	t.Run("Source Address Of Another Family", func(t *testing.T) {
+		t.Parallel()
+
+		checker := &ICMPChecker{
+			Name:        "TestChecker",
+			Address:     "example.com",
+			Protocol:    newProtocol(),
+			ReadTimeout: time.Second,
+			source:      source{IP: net.ParseIP("2001:db8::1")},
+			resolve:     resolve,
+		}
+
+		err := checker.Check(context.Background())
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := "source address 2001:db8::1 does not match the address family of 192.0.2.1"
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Any Address Fails", func(t *testing.T) {
+		t.Parallel()
+
+		checker := &ICMPChecker{
+			Name:        "TestChecker",
+			Address:     "example.com",
+			Protocol:    newEchoMockProtocol(map[int]bool{1: true, 2: true}),
+			ReadTimeout: time.Second,
+			Addresses:   addressModeAny,
+			resolve:     resolve,
+		}
+
+		err := checker.Check(context.Background())
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := "192.0.2.1: failed to read ICMP reply from example.com: i/o timeout\n192.0.2.2: failed to read ICMP reply from example.com: i/o timeout"
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+}
+
+func TestICMPCheckerTrace(t *testing.T) {
+	t.Parallel()
+
+	// newPathMockProtocol returns a mock protocol where the target is 3 hops away and the routers report expired requests
+	newPathMockProtocol := func() *testutils.MockProtocol {
+		icmpv4 := &ICMPv4{}
+		replies := make(chan []byte, 1)
+		from := make(chan net.Addr, 1)
+		ttl := 64
+
+		mockPacketConn := &testutils.MockPacketConn{
+			WriteToFunc: func(b []byte, addr net.Addr) (int, error) {
+				msg, _ := icmp.ParseMessage(icmpv4ProtocolNumber, b)
+				if ttl >= 3 {
+					reply, _ := (&icmp.Message{Type: ipv4.ICMPTypeEchoReply, Body: msg.Body}).Marshal(nil)
+					replies <- reply
+					from <- addr
+					return len(b), nil
+				}
+
+				header := &ipv4.Header{Version: ipv4.Version, Len: ipv4.HeaderLen, TotalLen: ipv4.HeaderLen + len(b), Protocol: icmpv4ProtocolNumber, Dst: addr.(*net.IPAddr).IP}
+				data, _ := header.Marshal()
+				reply, _ := (&icmp.Message{Type: ipv4.ICMPTypeTimeExceeded, Body: &icmp.TimeExceeded{Data: append(data, b[:8]...)}}).Marshal(nil)
+				replies <- reply
+				from <- &net.IPAddr{IP: net.IPv4(192, 0, 2, byte(ttl))}
+				return len(b), nil
+			},
+			ReadFromFunc: func(b []byte) (int, net.Addr, error) {
+				select {
+				case reply := <-replies:
+					return copy(b, reply), <-from, nil
+				case <-time.After(50 * time.Millisecond):
+					return 0, nil, os.ErrDeadlineExceeded
+				}
+			},
+		}
+
+		return &testutils.MockProtocol{
+			MakeRequestFunc:   icmpv4.MakeRequest,
+			ValidateReplyFunc: icmpv4.ValidateReply,
+			NetworkFunc: func() string {
+				return "ip4:icmp"
+			},
+			ListenPacketFunc: func(ctx context.Context, network, address string) (net.PacketConn, error) {
+				return mockPacketConn, nil
+			},
+			SetTTLFunc: func(t int) error {
+				ttl = t
+				return nil
+			},
+		}
+	}
+
+	t.Run("Target Reached", func(t *testing.T) {
+		t.Parallel()
+
+		checker := &ICMPChecker{
+			Name:        "TestChecker",
+			Address:     "198.51.100.1",
+			Protocol:    newPathMockProtocol(),
+			ReadTimeout: time.Second,
+		}
+
+		hops, err := checker.Trace(context.Background())
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if len(hops) != 3 {
+			t.Fatalf("expected 3 hops, got %+v", hops)
+		}
+		if hops[0].Address != "192.0.2.1" || hops[1].Address != "192.0.2.2" {
+			t.Errorf("expected routers 192.0.2.1 and 192.0.2.2, got %+v", hops)
+		}
+		if !hops[2].Reached || hops[2].Address != "198.51.100.1" {
+			t.Errorf("expected the target to be reached, got %+v", hops[2])
+		}
+	})
+
+	t.Run("Set TTL Fails", func(t *testing.T) {
+		t.Parallel()
+
+		protocol := newPathMockProtocol()
+		protocol.SetTTLFunc = func(ttl int) error {
+			return fmt.Errorf("operation not permitted")
+		}
+
+		checker := &ICMPChecker{
+			Name:        "TestChecker",
+			Address:     "198.51.100.1",
+			Protocol:    protocol,
+			ReadTimeout: time.Second,
+		}
+
+		_, err := checker.Trace(context.Background())
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := "failed to set TTL: operation not permitted"
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Raw Socket Not Permitted", func(t *testing.T) {
+		t.Parallel()
+
+		protocol := newPathMockProtocol()
+		protocol.ListenPacketFunc = func(ctx context.Context, network, address string) (net.PacketConn, error) {
+			return nil, fmt.Errorf("listen %s: %w", network, os.ErrPermission)
+		}
+
+		checker := &ICMPChecker{
+			Name:        "TestChecker",
+			Address:     "198.51.100.1",
+			Protocol:    protocol,
+			ReadTimeout: time.Second,
+		}
+
+		_, err := checker.Trace(context.Background())
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := "tracing requires raw ICMP privileges: listen ip4:icmp: permission denied"
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Datagram Mode Traces With Raw Socket", func(t *testing.T) {
+		t.Parallel()
+
+		checker := &ICMPChecker{
+			Name:        "TestChecker",
+			Address:     "127.0.0.1",
+			Mode:        icmpModeUDP,
+			ReadTimeout: time.Second,
+		}
+
+		// Without privileges the trace fails explicitly instead of reporting silent hops
+		hops, err := checker.Trace(context.Background())
+		if errors.Is(err, os.ErrPermission) {
+			if !strings.HasPrefix(err.Error(), "tracing requires raw ICMP privileges: ") {
+				t.Errorf("expected a privileges error, got %q", err.Error())
+			}
+			return
+		}
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if len(hops) != 1 || !hops[0].Reached {
+			t.Errorf("expected the target to be reached at the first hop, got %+v", hops)
+		}
+	})
+
+	t.Run("Source Address Of Another Family", func(t *testing.T) {
+		t.Parallel()
+
+		checker := &ICMPChecker{
+			Name:        "TestChecker",
+			Address:     "198.51.100.1",
+			Protocol:    newPathMockProtocol(),
+			ReadTimeout: time.Second,
+			source:      source{IP: net.ParseIP("::1")},
+		}
+
+		_, err := checker.Trace(context.Background())
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := "source address ::1 does not match the address family of 198.51.100.1"
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+}
+
+func TestICMPStats(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Round-Trip Times", func(t *testing.T) {
+		t.Parallel()
+
+		stats := newICMPStats(4, []time.Duration{10 * time.Millisecond, 30 * time.Millisecond, 20 * time.Millisecond})
+
+		expected := ICMPStats{
+			Sent:     4,
+			Received: 3,
+			Min:      10 * time.Millisecond,
+			Avg:      20 * time.Millisecond,
+			Max:      30 * time.Millisecond,
+			Jitter:   15 * time.Millisecond,
+		}
+		if stats != expected {
+			t.Errorf("expected %+v, got %+v", expected, stats)
+		}
+
+		if stats.Loss() != 25 {
+			t.Errorf("expected loss of 25%%, got %v", stats.Loss())
+		}
+	})
+
+	t.Run("No Replies", func(t *testing.T) {
+		t.Parallel()
+
+		stats := newICMPStats(3, nil)
+		if stats.Loss() != 100 || stats.Avg != 0 {
+			t.Errorf("unexpected stats %+v", stats)
+		}
+	})
+}
+
 func TestMakeICMPRequest(t *testing.T) {
 	t.Parallel()
 
@@ -696,7 +1347,7 @@ func TestReadICMPReply(t *testing.T) {
 	mockConn := &testutils.MockPacketConn{
 		ReadFromFunc: func(b []byte) (int, net.Addr, error) {
 			copy(b, []byte("valid"))
-			return 5, &net.IPAddr{}, nil // Return the exact number of bytes written.
+			return 5, &net.IPAddr{IP: net.IPv4(127, 0, 0, 1)}, nil // Return the exact number of bytes written.
 		},
 	}
 
@@ -704,7 +1355,7 @@ func TestReadICMPReply(t *testing.T) {
 		t.Parallel()
 
 		ctx := context.Background()
-		reply, err := c.readICMPReply(ctx, mockConn)
+		reply, peer, err := c.readICMPReply(ctx, mockConn)
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
 		}
@@ -712,6 +1363,10 @@ func TestReadICMPReply(t *testing.T) {
 		if string(reply) != "valid" {
 			t.Fatalf("expected 'valid', got %v", string(reply))
 		}
+
+		if peer.String() != "127.0.0.1" {
+			t.Fatalf("expected peer 127.0.0.1, got %v", peer)
+		}
 	})
 
 	t.Run("Context Canceled", func(t *testing.T) {
@@ -729,30 +1384,156 @@ func TestReadICMPReply(t *testing.T) {
 		ctx, cancel := context.WithCancel(context.Background())
 		cancel()
 
-		_, err := c.readICMPReply(ctx, mockConn)
+		_, _, err := c.readICMPReply(ctx, mockConn)
 		if err == nil {
 			t.Fatalf("expected context canceled error, got nil")
 		}
 	})
+}
+
+func TestAwaitICMPReply(t *testing.T) {
+	t.Parallel()
+
+	icmpv4 := &ICMPv4{}
+	request, _ := icmpv4.MakeRequest(1234, 2)
+
+	marshal := func(msg icmp.Message) []byte {
+		b, _ := msg.Marshal(nil)
+		return b
+	}
+
+	// quote returns the IPv4 header and echo request quoted by an ICMP error message
+	quote := func(request []byte) []byte {
+		header := &ipv4.Header{
+			Version:  ipv4.Version,
+			Len:      ipv4.HeaderLen,
+			TotalLen: ipv4.HeaderLen + len(request),
+			TTL:      1,
+			Protocol: icmpv4ProtocolNumber,
+			Src:      net.IPv4(192, 0, 2, 10),
+			Dst:      net.IPv4(198, 51, 100, 1),
+		}
+		b, _ := header.Marshal()
+		return append(b, request[:8]...)
+	}
+
+	// newConn returns a connection reading the given packets sent by from, followed by a timeout
+	newConn := func(from net.IP, packets ...[]byte) *testutils.MockPacketConn {
+		return &testutils.MockPacketConn{
+			ReadFromFunc: func(b []byte) (int, net.Addr, error) {
+				if len(packets) == 0 {
+					return 0, nil, fmt.Errorf("i/o timeout")
+				}
+				packet := packets[0]
+				packets = packets[1:]
+				return copy(b, packet), &net.IPAddr{IP: from}, nil
+			},
+		}
+	}
+
+	c := &ICMPChecker{
+		Address:     "198.51.100.1",
+		ReadTimeout: time.Second,
+	}
+	dst := &net.IPAddr{IP: net.IPv4(198, 51, 100, 1)}
+	router := net.IPv4(192, 0, 2, 254)
+
+	tests := []struct {
+		name     string
+		from     net.IP
+		packets  [][]byte
+		expected string
+	}{
+		{
+			name: "Unrelated Packets Discarded",
+			from: dst.IP,
+			packets: [][]byte{
+				marshal(icmp.Message{Type: ipv4.ICMPTypeEchoReply, Body: &icmp.Echo{ID: 4321, Seq: 2}}),
+				marshal(icmp.Message{Type: ipv4.ICMPTypeEchoReply, Body: &icmp.Echo{ID: 1234, Seq: 1}}),
+				marshal(icmp.Message{Type: ipv4.ICMPTypeEcho, Body: &icmp.Echo{ID: 1234, Seq: 2}}),
+				{0xff},
+				marshal(icmp.Message{Type: ipv4.ICMPTypeEchoReply, Body: &icmp.Echo{ID: 1234, Seq: 2}}),
+			},
+		},
+		{
+			name: "Only Unrelated Packets",
+			from: dst.IP,
+			packets: [][]byte{
+				marshal(icmp.Message{Type: ipv4.ICMPTypeEchoReply, Body: &icmp.Echo{ID: 4321, Seq: 2}}),
+			},
+			expected: "failed to read ICMP reply from 198.51.100.1: i/o timeout",
+		},
+		{
+			name: "Destination Host Unreachable",
+			from: router,
+			packets: [][]byte{
+				marshal(icmp.Message{Type: ipv4.ICMPTypeDestinationUnreachable, Code: 1, Body: &icmp.DstUnreach{Data: quote(request)}}),
+			},
+			expected: "destination host unreachable (reported by 192.0.2.254)",
+		},
+		{
+			name: "Time Exceeded",
+			from: router,
+			packets: [][]byte{
+				marshal(icmp.Message{Type: ipv4.ICMPTypeTimeExceeded, Code: 0, Body: &icmp.TimeExceeded{Data: quote(request)}}),
+			},
+			expected: "time to live exceeded in transit (reported by 192.0.2.254)",
+		},
+		{
+			name: "Reply From Another Host Discarded",
+			from: net.IPv4(203, 0, 113, 7),
+			packets: [][]byte{
+				marshal(icmp.Message{Type: ipv4.ICMPTypeEchoReply, Body: &icmp.Echo{ID: 1234, Seq: 2}}),
+			},
+			expected: "failed to read ICMP reply from 198.51.100.1: i/o timeout",
+		},
+		{
+			name: "Error For Another Request Discarded",
+			from: dst.IP,
+			packets: [][]byte{
+				marshal(icmp.Message{Type: ipv4.ICMPTypeDestinationUnreachable, Code: 1, Body: &icmp.DstUnreach{Data: quote(marshal(icmp.Message{Type: ipv4.ICMPTypeEcho, Body: &icmp.Echo{ID: 1234, Seq: 1}}))}}),
+				marshal(icmp.Message{Type: ipv4.ICMPTypeEchoReply, Body: &icmp.Echo{ID: 1234, Seq: 2}}),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := c.awaitICMPReply(context.Background(), icmpv4, newConn(tt.from, tt.packets...), dst, 1234, 2)
+			if tt.expected == "" {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				return
+			}
+
+			if err == nil {
+				t.Fatal("expected an error, got none")
+			}
+			if err.Error() != tt.expected {
+				t.Errorf("expected error %q, got %q", tt.expected, err.Error())
+			}
+		})
+	}
 
 	t.Run("Read Deadline Error", func(t *testing.T) {
 		t.Parallel()
 
+		mockConn := newConn(dst.IP)
 		mockConn.SetReadDeadlineFunc = func(t time.Time) error {
 			return fmt.Errorf("mock set read deadline error")
 		}
 
-		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
-		defer cancel()
-
-		_, err := c.readICMPReply(ctx, mockConn)
+		err := c.awaitICMPReply(context.Background(), icmpv4, mockConn, dst, 1234, 2)
 		if err == nil {
 			t.Fatal("expected an error, got none")
 		}
 
 		expected := "failed to set read deadline: mock set read deadline error"
 		if err.Error() != expected {
-			t.Fatalf("expected write deadline error, got %v", err)
+			t.Fatalf("expected read deadline error, got %v", err)
 		}
 	})
 }
@@ -775,7 +1556,7 @@ func TestValidateICMPReply(t *testing.T) {
 		t.Parallel()
 
 		ctx := context.Background()
-		err := c.validateICMPReply(ctx, []byte("valid"), 1234, 1)
+		err := c.validateICMPReply(ctx, c.Protocol, []byte("valid"), 1234, 1)
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
 		}
@@ -785,7 +1566,7 @@ func TestValidateICMPReply(t *testing.T) {
 		t.Parallel()
 
 		ctx := context.Background()
-		err := c.validateICMPReply(ctx, []byte("invalid"), 1234, 1)
+		err := c.validateICMPReply(ctx, c.Protocol, []byte("invalid"), 1234, 1)
 		if err == nil {
 			t.Fatalf("expected validation error, got nil")
 		}
@@ -802,7 +1583,7 @@ func TestValidateICMPReply(t *testing.T) {
 		ctx, cancel := context.WithCancel(context.Background())
 		cancel()
 
-		err := c.validateICMPReply(ctx, []byte("valid"), 1234, 1)
+		err := c.validateICMPReply(ctx, c.Protocol, []byte("valid"), 1234, 1)
 		if err == nil {
 			t.Fatalf("expected context canceled error, got nil")
 		}
@@ -2,8 +2,11 @@ package checker
 
 import (
 	"context"
+	"encoding/binary"
+	"errors"
 	"fmt"
 	"net"
+	"os"
 	"time"
 
 	"golang.org/x/net/icmp"
@@ -14,6 +17,10 @@ import (
 const (
 	icmpv4ProtocolNumber int = 1
 	icmpv6ProtocolNumber int = 58
+
+	icmpModeAuto string = "auto" // Use raw sockets if permitted, otherwise unprivileged datagram sockets.
+	icmpModeRaw  string = "raw"  // Use raw sockets, which require CAP_NET_RAW.
+	icmpModeUDP  string = "udp"  // Use unprivileged datagram sockets, which require net.ipv4.ping_group_range to include the group.
 )
 
 // Protocol defines the interface for an ICMP protocol.
@@ -23,31 +30,208 @@ type Protocol interface {
 	Network() string
 	ListenPacket(ctx context.Context, network, address string) (net.PacketConn, error)
 	SetDeadline(t time.Time) error
+	SetTTL(ttl int) error
+}
+
+// unrelatedPacketError is returned by ValidateReply for packets not belonging to the echo request,
+// like replies to other processes, which are discarded while waiting for the reply.
+type unrelatedPacketError struct {
+	err error // The reason the packet does not belong to the echo request.
+}
+
+// Error returns the reason the packet does not belong to the echo request.
+func (e *unrelatedPacketError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the reason the packet does not belong to the echo request.
+func (e *unrelatedPacketError) Unwrap() error {
+	return e.err
+}
+
+// icmpError is returned by ValidateReply for an ICMP error message sent in response to the echo request.
+type icmpError struct {
+	reason       string // The meaning of the error message, e.g. "destination host unreachable".
+	from         string // The address of the host reporting the error, if known.
+	timeExceeded bool   // Whether the packet expired in transit, which is expected while tracing the path.
+}
+
+// Error returns the meaning of the error message and the reporting host.
+func (e *icmpError) Error() string {
+	if e.from == "" {
+		return e.reason
+	}
+	return fmt.Sprintf("%s (reported by %s)", e.reason, e.from)
+}
+
+// icmpv4UnreachableReasons maps the codes of ICMPv4 destination unreachable messages to their meaning.
+var icmpv4UnreachableReasons = map[int]string{
+	0:  "destination network unreachable",
+	1:  "destination host unreachable",
+	2:  "destination protocol unreachable",
+	3:  "destination port unreachable",
+	4:  "fragmentation needed",
+	5:  "source route failed",
+	6:  "destination network unknown",
+	7:  "destination host unknown",
+	9:  "destination network administratively prohibited",
+	10: "destination host administratively prohibited",
+	13: "communication administratively prohibited",
+}
+
+// icmpv6UnreachableReasons maps the codes of ICMPv6 destination unreachable messages to their meaning.
+var icmpv6UnreachableReasons = map[int]string{
+	0: "no route to destination",
+	1: "communication administratively prohibited",
+	2: "beyond scope of source address",
+	3: "destination address unreachable",
+	4: "destination port unreachable",
+	5: "source address failed ingress/egress policy",
+	6: "reject route to destination",
 }
 
-// newProtocol creates a new ICMP protocol based on the given address.
-// If the address is not an IP, it will be resolved as a domain name.
-func newProtocol(address string) (Protocol, error) {
-	ip := net.ParseIP(address)
-	if ip == nil {
-		// If the address is not an IP, try resolving it as a domain name
-		ips, err := net.LookupIP(address)
-		if err != nil || len(ips) == 0 {
-			return nil, fmt.Errorf("invalid or unresolvable address: %s", address)
+// quotesEchoRequest reports whether the ICMP message quoted in an error message is the echo request.
+// Error messages quote at least the first 8 bytes of the offending message, which contain the identifier and sequence number.
+func quotesEchoRequest(quoted []byte, requestType byte, identifier, sequence uint16, checkIdentifier bool) bool {
+	if len(quoted) < 8 || quoted[0] != requestType {
+		return false
+	}
+	if checkIdentifier && binary.BigEndian.Uint16(quoted[4:6]) != identifier {
+		return false
+	}
+	return binary.BigEndian.Uint16(quoted[6:8]) == sequence
+}
+
+// quotedPacket is the beginning of the packet an ICMP error message was sent for.
+type quotedPacket struct {
+	Protocol int    // The protocol of the payload, e.g. ICMP or TCP.
+	Dst      net.IP // The destination of the packet.
+	Payload  []byte // The beginning of the payload.
+}
+
+// parseICMPError returns the meaning of a destination unreachable or time exceeded message and the packet it quotes.
+// It reports false for other messages and if the quoted packet cannot be parsed.
+func parseICMPError(msg *icmp.Message) (*icmpError, *quotedPacket, bool) {
+	var data []byte
+	switch body := msg.Body.(type) {
+	case *icmp.DstUnreach:
+		data = body.Data
+	case *icmp.TimeExceeded:
+		data = body.Data
+	default:
+		return nil, nil, false
+	}
+
+	switch msg.Type {
+	case ipv4.ICMPTypeDestinationUnreachable, ipv4.ICMPTypeTimeExceeded:
+		// The error message quotes the IPv4 header and the beginning of the payload
+		header, err := ipv4.ParseHeader(data)
+		if err != nil || len(data) < header.Len {
+			return nil, nil, false
 		}
-		ip = ips[0] // Use the first resolved IP address
+		quoted := &quotedPacket{Protocol: header.Protocol, Dst: header.Dst, Payload: data[header.Len:]}
+
+		if msg.Type == ipv4.ICMPTypeTimeExceeded {
+			if msg.Code == 1 {
+				return &icmpError{reason: "fragment reassembly time exceeded"}, quoted, true
+			}
+			return &icmpError{reason: "time to live exceeded in transit", timeExceeded: true}, quoted, true
+		}
+
+		reason, ok := icmpv4UnreachableReasons[msg.Code]
+		if !ok {
+			reason = fmt.Sprintf("destination unreachable (code %d)", msg.Code)
+		}
+		return &icmpError{reason: reason}, quoted, true
+
+	case ipv6.ICMPTypeDestinationUnreachable, ipv6.ICMPTypeTimeExceeded:
+		// The error message quotes the IPv6 header and the beginning of the payload
+		header, err := ipv6.ParseHeader(data)
+		if err != nil {
+			return nil, nil, false
+		}
+		quoted := &quotedPacket{Protocol: header.NextHeader, Dst: header.Dst, Payload: data[ipv6.HeaderLen:]}
+
+		if msg.Type == ipv6.ICMPTypeTimeExceeded {
+			if msg.Code == 1 {
+				return &icmpError{reason: "fragment reassembly time exceeded"}, quoted, true
+			}
+			return &icmpError{reason: "hop limit exceeded in transit", timeExceeded: true}, quoted, true
+		}
+
+		reason, ok := icmpv6UnreachableReasons[msg.Code]
+		if !ok {
+			reason = fmt.Sprintf("destination unreachable (code %d)", msg.Code)
+		}
+		return &icmpError{reason: reason}, quoted, true
+
+	default:
+		return nil, nil, false
+	}
+}
+
+// newProtocol creates the ICMP protocol matching the family of the given address, using sockets of the given mode
+// bound to the given network interface.
+func newProtocol(ip net.IP, mode, iface string) Protocol {
+	if ip.To4() == nil {
+		return &ICMPv6{Mode: mode, Interface: iface}
+	}
+
+	return &ICMPv4{Mode: mode, Interface: iface}
+}
+
+// listenICMP opens a raw socket on rawNetwork or an unprivileged datagram socket on udpNetwork, depending on the mode.
+// In auto mode, the datagram socket is only used if opening the raw socket is not permitted.
+// If iface is set, the socket is bound to the network interface.
+// It reports whether the returned connection is unprivileged.
+func listenICMP(ctx context.Context, mode, iface, rawNetwork, udpNetwork, address string) (net.PacketConn, bool, error) {
+	if mode != icmpModeUDP {
+		var lc net.ListenConfig
+		if iface != "" {
+			lc.Control = source{Interface: iface}.control
+		}
+		conn, err := lc.ListenPacket(ctx, rawNetwork, address)
+		if err == nil || mode != icmpModeAuto || !errors.Is(err, os.ErrPermission) {
+			return conn, false, err
+		}
+	}
+
+	// icmp.ListenPacket cannot bind datagram sockets to an interface, so they listen on the address of the interface instead
+	if ip := net.ParseIP(address); iface != "" && (address == "" || ip != nil && ip.IsUnspecified()) {
+		ifaceIP, err := interfaceIP(iface, udpNetwork == "udp6")
+		if err != nil {
+			return nil, true, err
+		}
+		address = ifaceIP.String()
 	}
 
-	if ip.To16() != nil && ip.To4() == nil {
-		return &ICMPv6{}, nil
+	conn, err := icmp.ListenPacket(udpNetwork, address)
+	if err != nil {
+		return nil, true, err
 	}
 
-	return &ICMPv4{}, nil
+	return &datagramConn{PacketConn: conn}, true, nil
+}
+
+// datagramConn wraps an unprivileged ICMP datagram socket, which is addressed with UDP addresses, so it accepts IP addresses.
+type datagramConn struct {
+	net.PacketConn
+}
+
+// WriteTo writes a packet to the given address, converting an IP address into a UDP address.
+func (c *datagramConn) WriteTo(b []byte, addr net.Addr) (int, error) {
+	if ipAddr, ok := addr.(*net.IPAddr); ok {
+		addr = &net.UDPAddr{IP: ipAddr.IP, Zone: ipAddr.Zone}
+	}
+	return c.PacketConn.WriteTo(b, addr)
 }
 
 // ICMPv4 implements the ICMP protocol for IPv4.
 type ICMPv4 struct {
-	conn net.PacketConn
+	Mode         string         // The socket mode (auto, raw or udp). If empty, raw sockets are used.
+	Interface    string         // The network interface the socket is bound to. If empty, the routing table decides.
+	conn         net.PacketConn // The connection of the last ListenPacket call.
+	unprivileged bool           // Whether the connection is an unprivileged datagram socket.
 }
 
 // MakeRequest creates an ICMP echo request message.
@@ -67,19 +251,31 @@ func (p *ICMPv4) MakeRequest(identifier, sequence uint16) ([]byte, error) {
 }
 
 // ValidateReply validates an ICMP echo reply message.
+// Destination unreachable and time exceeded messages quoting the echo request are returned as an icmpError,
+// all other packets not belonging to the echo request as an unrelatedPacketError.
 func (p *ICMPv4) ValidateReply(reply []byte, identifier, sequence uint16) error {
 	parsedMsg, err := icmp.ParseMessage(icmpv4ProtocolNumber, reply)
 	if err != nil {
-		return fmt.Errorf("failed to parse ICMPv4 message: %w", err)
+		return &unrelatedPacketError{err: fmt.Errorf("failed to parse ICMPv4 message: %w", err)}
 	}
 
-	if parsedMsg.Type != ipv4.ICMPTypeEchoReply {
-		return fmt.Errorf("unexpected ICMPv4 message type: %v", parsedMsg.Type)
+	switch parsedMsg.Type {
+	case ipv4.ICMPTypeEchoReply:
+	case ipv4.ICMPTypeDestinationUnreachable, ipv4.ICMPTypeTimeExceeded:
+		icmpErr, quoted, ok := parseICMPError(parsedMsg)
+		if !ok || quoted.Protocol != icmpv4ProtocolNumber ||
+			!quotesEchoRequest(quoted.Payload, byte(ipv4.ICMPTypeEcho), identifier, sequence, !p.unprivileged) {
+			return &unrelatedPacketError{err: fmt.Errorf("ICMPv4 %v message for another packet", parsedMsg.Type)}
+		}
+		return icmpErr
+	default:
+		return &unrelatedPacketError{err: fmt.Errorf("unexpected ICMPv4 message type: %v", parsedMsg.Type)}
 	}
 
+	// The kernel replaces the identifier of requests sent over datagram sockets with the local port
 	body, ok := parsedMsg.Body.(*icmp.Echo)
-	if !ok || body.ID != int(identifier) || body.Seq != int(sequence) {
-		return fmt.Errorf("identifier or sequence mismatch")
+	if !ok || (!p.unprivileged && body.ID != int(identifier)) || body.Seq != int(sequence) {
+		return &unrelatedPacketError{err: fmt.Errorf("identifier or sequence mismatch")}
 	}
 
 	return nil
@@ -91,13 +287,14 @@ func (p *ICMPv4) Network() string {
 }
 
 // ListenPacket creates a new ICMPv4 packet connection.
+// Depending on the mode, an unprivileged udp4 socket is used instead of a raw socket on the given network.
 func (p *ICMPv4) ListenPacket(ctx context.Context, network, address string) (net.PacketConn, error) {
-	var lc net.ListenConfig
-	conn, err := lc.ListenPacket(ctx, network, address)
+	conn, unprivileged, err := listenICMP(ctx, p.Mode, p.Interface, network, "udp4", address)
 	if err != nil {
 		return nil, fmt.Errorf("failed to listen for ICMP packets: %w", err)
 	}
 	p.conn = conn
+	p.unprivileged = unprivileged
 
 	return p.conn, nil
 }
@@ -107,9 +304,20 @@ func (p *ICMPv4) SetDeadline(t time.Time) error {
 	return p.conn.SetDeadline(t)
 }
 
+// SetTTL sets the time to live of the echo requests sent over the connection.
+func (p *ICMPv4) SetTTL(ttl int) error {
+	if conn, ok := p.conn.(*datagramConn); ok {
+		return conn.PacketConn.(*icmp.PacketConn).IPv4PacketConn().SetTTL(ttl)
+	}
+	return ipv4.NewPacketConn(p.conn).SetTTL(ttl)
+}
+
 // ICMPv6 implements the ICMP protocol for IPv6.
 type ICMPv6 struct {
-	conn net.PacketConn
+	Mode         string         // The socket mode (auto, raw or udp). If empty, raw sockets are used.
+	Interface    string         // The network interface the socket is bound to. If empty, the routing table decides.
+	conn         net.PacketConn // The connection of the last ListenPacket call.
+	unprivileged bool           // Whether the connection is an unprivileged datagram socket.
 }
 
 // MakeRequest creates an ICMP echo request message.
@@ -129,19 +337,31 @@ func (p *ICMPv6) MakeRequest(identifier, sequence uint16) ([]byte, error) {
 }
 
 // ValidateReply validates an ICMP echo reply message.
+// Destination unreachable and time exceeded messages quoting the echo request are returned as an icmpError,
+// all other packets not belonging to the echo request as an unrelatedPacketError.
 func (p *ICMPv6) ValidateReply(reply []byte, identifier, sequence uint16) error {
 	parsedMsg, err := icmp.ParseMessage(icmpv6ProtocolNumber, reply)
 	if err != nil {
-		return fmt.Errorf("failed to parse ICMPv6 message: %w", err)
+		return &unrelatedPacketError{err: fmt.Errorf("failed to parse ICMPv6 message: %w", err)}
 	}
 
-	if parsedMsg.Type != ipv6.ICMPTypeEchoReply {
-		return fmt.Errorf("unexpected ICMPv6 message type: %v", parsedMsg.Type)
+	switch parsedMsg.Type {
+	case ipv6.ICMPTypeEchoReply:
+	case ipv6.ICMPTypeDestinationUnreachable, ipv6.ICMPTypeTimeExceeded:
+		icmpErr, quoted, ok := parseICMPError(parsedMsg)
+		if !ok || quoted.Protocol != icmpv6ProtocolNumber ||
+			!quotesEchoRequest(quoted.Payload, byte(ipv6.ICMPTypeEchoRequest), identifier, sequence, !p.unprivileged) {
+			return &unrelatedPacketError{err: fmt.Errorf("ICMPv6 %v message for another packet", parsedMsg.Type)}
+		}
+		return icmpErr
+	default:
+		return &unrelatedPacketError{err: fmt.Errorf("unexpected ICMPv6 message type: %v", parsedMsg.Type)}
 	}
 
+	// The kernel replaces the identifier of requests sent over datagram sockets with the local port
 	body, ok := parsedMsg.Body.(*icmp.Echo)
-	if !ok || body.ID != int(identifier) || body.Seq != int(sequence) {
-		return fmt.Errorf("identifier or sequence mismatch")
+	if !ok || (!p.unprivileged && body.ID != int(identifier)) || body.Seq != int(sequence) {
+		return &unrelatedPacketError{err: fmt.Errorf("identifier or sequence mismatch")}
 	}
 
 	return nil
@@ -153,13 +373,14 @@ func (p *ICMPv6) Network() string {
 }
 
 // ListenPacket creates a new ICMPv6 packet connection.
+// Depending on the mode, an unprivileged udp6 socket is used instead of a raw socket on the given network.
 func (p *ICMPv6) ListenPacket(ctx context.Context, network, address string) (net.PacketConn, error) {
-	var lc net.ListenConfig
-	conn, err := lc.ListenPacket(ctx, network, address)
+	conn, unprivileged, err := listenICMP(ctx, p.Mode, p.Interface, network, "udp6", address)
 	if err != nil {
 		return nil, fmt.Errorf("failed to listen for ICMP packets: %w", err)
 	}
 	p.conn = conn
+	p.unprivileged = unprivileged
 
 	return p.conn, nil
 }
@@ -168,3 +389,11 @@ func (p *ICMPv6) ListenPacket(ctx context.Context, network, address string) (net
 func (p *ICMPv6) SetDeadline(t time.Time) error {
 	return p.conn.SetDeadline(t)
 }
+
+// SetTTL sets the hop limit of the echo requests sent over the connection.
+func (p *ICMPv6) SetTTL(ttl int) error {
+	if conn, ok := p.conn.(*datagramConn); ok {
+		return conn.PacketConn.(*icmp.PacketConn).IPv6PacketConn().SetHopLimit(ttl)
+	}
+	return ipv6.NewPacketConn(p.conn).SetHopLimit(ttl)
+}
@@ -2,6 +2,9 @@ package checker
 
 import (
 	"context"
+	"errors"
+	"net"
+	"os"
 	"testing"
 	"time"
 
@@ -15,57 +18,43 @@ import (
 func TestNewProtocol(t *testing.T) {
 	t.Parallel()
 
-	t.Run("Valid IPv4 Address", func(t *testing.T) {
+	t.Run("IPv4 Address", func(t *testing.T) {
 		t.Parallel()
 
-		protocol, err := newProtocol("192.168.1.1")
-		if err != nil {
-			t.Fatalf("expected no error, got %v", err)
-		}
+		protocol := newProtocol(net.ParseIP("192.168.1.1"), icmpModeAuto, "")
 
-		if _, ok := protocol.(*ICMPv4); !ok {
+		icmpv4, ok := protocol.(*ICMPv4)
+		if !ok {
 			t.Fatalf("expected ICMPv4 protocol, got %T", protocol)
 		}
-	})
-
-	t.Run("Valid IPv6 Address", func(t *testing.T) {
-		t.Parallel()
-
-		protocol, err := newProtocol("2001:db8::1")
-		if err != nil {
-			t.Fatalf("expected no error, got %v", err)
-		}
 
-		if _, ok := protocol.(*ICMPv6); !ok {
-			t.Fatalf("expected ICMPv6 protocol, got %T", protocol)
+		if icmpv4.Mode != icmpModeAuto {
+			t.Errorf("expected mode %q, got %q", icmpModeAuto, icmpv4.Mode)
 		}
 	})
 
-	t.Run("Unresolvable Address", func(t *testing.T) {
+	t.Run("IPv4-Mapped IPv6 Address", func(t *testing.T) {
 		t.Parallel()
 
-		_, err := newProtocol("invalid.domain")
-		if err == nil {
-			t.Fatal("expected an error, got none")
-		}
+		protocol := newProtocol(net.ParseIP("::ffff:192.168.1.1"), icmpModeRaw, "")
 
-		expected := "invalid or unresolvable address: invalid.domain"
-		if err.Error() != expected {
-			t.Errorf("expected error %q, got %q", expected, err.Error())
+		if _, ok := protocol.(*ICMPv4); !ok {
+			t.Fatalf("expected ICMPv4 protocol, got %T", protocol)
 		}
 	})
 
-	t.Run("Unsupported IP Address", func(t *testing.T) {
+	t.Run("IPv6 Address", func(t *testing.T) {
 		t.Parallel()
 
-		_, err := newProtocol("300.300.300.300")
-		if err == nil {
-			t.Fatal("expected an error, got none")
+		protocol := newProtocol(net.ParseIP("2001:db8::1"), icmpModeUDP, "")
+
+		icmpv6, ok := protocol.(*ICMPv6)
+		if !ok {
+			t.Fatalf("expected ICMPv6 protocol, got %T", protocol)
 		}
 
-		expected := "invalid or unresolvable address: 300.300.300.300"
-		if err.Error() != expected {
-			t.Errorf("expected error %q, got %q", expected, err.Error())
+		if icmpv6.Mode != icmpModeUDP {
+			t.Errorf("expected mode %q, got %q", icmpModeUDP, icmpv6.Mode)
 		}
 	})
 }
@@ -117,6 +106,30 @@ func TestICMPv4_SetDeadline(t *testing.T) {
 	})
 }
 
+func TestICMPv4_SetTTL(t *testing.T) {
+	t.Parallel()
+
+	for _, mode := range []string{icmpModeRaw, icmpModeUDP} {
+		t.Run(mode, func(t *testing.T) {
+			t.Parallel()
+
+			protocol := &ICMPv4{Mode: mode}
+			conn, err := protocol.ListenPacket(context.Background(), protocol.Network(), "127.0.0.1")
+			if errors.Is(err, os.ErrPermission) {
+				t.Skip("ICMP sockets are not permitted")
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			defer conn.Close()
+
+			if err := protocol.SetTTL(5); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+		})
+	}
+}
+
 func TestICMPv4_ValidateReply(t *testing.T) {
 	t.Parallel()
 
@@ -250,6 +263,129 @@ func TestICMPv4_ValidateReply(t *testing.T) {
 	})
 }
 
+func TestICMPv4_ValidateReplyError(t *testing.T) {
+	t.Parallel()
+
+	protocol := &ICMPv4{}
+
+	// newError returns an error message of the given type and code quoting the echo request
+	newError := func(typ ipv4.ICMPType, code int, sequence uint16) []byte {
+		request, _ := protocol.MakeRequest(1234, sequence)
+		header := &ipv4.Header{
+			Version:  ipv4.Version,
+			Len:      ipv4.HeaderLen,
+			TotalLen: ipv4.HeaderLen + len(request),
+			TTL:      1,
+			Protocol: icmpv4ProtocolNumber,
+			Dst:      net.IPv4(198, 51, 100, 1),
+		}
+		data, _ := header.Marshal()
+		data = append(data, request[:8]...)
+
+		msg := icmp.Message{Type: typ, Code: code, Body: &icmp.DstUnreach{Data: data}}
+		if typ == ipv4.ICMPTypeTimeExceeded {
+			msg.Body = &icmp.TimeExceeded{Data: data}
+		}
+		b, _ := msg.Marshal(nil)
+		return b
+	}
+
+	tests := []struct {
+		name     string
+		reply    []byte
+		expected string
+	}{
+		{
+			name:     "Network Unreachable",
+			reply:    newError(ipv4.ICMPTypeDestinationUnreachable, 0, 1),
+			expected: "destination network unreachable",
+		},
+		{
+			name:     "Administratively Prohibited",
+			reply:    newError(ipv4.ICMPTypeDestinationUnreachable, 13, 1),
+			expected: "communication administratively prohibited",
+		},
+		{
+			name:     "Unknown Code",
+			reply:    newError(ipv4.ICMPTypeDestinationUnreachable, 15, 1),
+			expected: "destination unreachable (code 15)",
+		},
+		{
+			name:     "Fragment Reassembly Time Exceeded",
+			reply:    newError(ipv4.ICMPTypeTimeExceeded, 1, 1),
+			expected: "fragment reassembly time exceeded",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := protocol.ValidateReply(tt.reply, 1234, 1)
+
+			var icmpErr *icmpError
+			if !errors.As(err, &icmpErr) {
+				t.Fatalf("expected an ICMP error, got %v", err)
+			}
+			if err.Error() != tt.expected {
+				t.Errorf("expected error %q, got %q", tt.expected, err.Error())
+			}
+		})
+	}
+
+	t.Run("Error For Another Request", func(t *testing.T) {
+		t.Parallel()
+
+		err := protocol.ValidateReply(newError(ipv4.ICMPTypeDestinationUnreachable, 1, 2), 1234, 1)
+
+		var unrelatedErr *unrelatedPacketError
+		if !errors.As(err, &unrelatedErr) {
+			t.Fatalf("expected an unrelated packet error, got %v", err)
+		}
+	})
+}
+
+func TestICMPv4_ValidateReplyUnprivileged(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Rewritten Identifier", func(t *testing.T) {
+		t.Parallel()
+
+		protocol := &ICMPv4{Mode: icmpModeUDP, unprivileged: true}
+
+		// The kernel replaces the identifier with the local port of the datagram socket
+		reply, _ := (&icmp.Message{
+			Type: ipv4.ICMPTypeEchoReply,
+			Body: &icmp.Echo{ID: 40123, Seq: 1, Data: []byte("HELLO-R-U-THERE")},
+		}).Marshal(nil)
+
+		if err := protocol.ValidateReply(reply, 1234, 1); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("Sequence Mismatch", func(t *testing.T) {
+		t.Parallel()
+
+		protocol := &ICMPv4{Mode: icmpModeUDP, unprivileged: true}
+
+		reply, _ := (&icmp.Message{
+			Type: ipv4.ICMPTypeEchoReply,
+			Body: &icmp.Echo{ID: 40123, Seq: 2, Data: []byte("HELLO-R-U-THERE")},
+		}).Marshal(nil)
+
+		err := protocol.ValidateReply(reply, 1234, 1)
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := "identifier or sequence mismatch"
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+}
+
 func TestICMPv4_ListenPacket(t *testing.T) {
 	t.Parallel()
 
@@ -313,7 +449,56 @@ func TestICMPv4_ListenPacket(t *testing.T) {
 	})
 }
 
-// HERE
+func TestICMPv4_ListenPacketUnprivileged(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Datagram Socket", func(t *testing.T) {
+		t.Parallel()
+
+		protocol := &ICMPv4{Mode: icmpModeUDP}
+
+		conn, err := protocol.ListenPacket(context.Background(), "ip4:icmp", "127.0.0.1")
+		if errors.Is(err, os.ErrPermission) {
+			t.Skip("unprivileged ICMP sockets are not permitted by net.ipv4.ping_group_range")
+		}
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		defer conn.Close()
+
+		if _, ok := conn.(*datagramConn); !ok {
+			t.Errorf("expected a datagram connection, got %T", conn)
+		}
+		if !protocol.unprivileged {
+			t.Error("expected the protocol to be unprivileged")
+		}
+	})
+}
+
+func TestDatagramConn(t *testing.T) {
+	t.Parallel()
+
+	t.Run("WriteTo Converts IP Address", func(t *testing.T) {
+		t.Parallel()
+
+		var got net.Addr
+		conn := &datagramConn{PacketConn: &testutils.MockPacketConn{
+			WriteToFunc: func(b []byte, addr net.Addr) (int, error) {
+				got = addr
+				return len(b), nil
+			},
+		}}
+
+		if _, err := conn.WriteTo([]byte{0x08}, &net.IPAddr{IP: net.IPv4(192, 0, 2, 1)}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		udpAddr, ok := got.(*net.UDPAddr)
+		if !ok || !udpAddr.IP.Equal(net.IPv4(192, 0, 2, 1)) || udpAddr.Port != 0 {
+			t.Errorf("expected UDP address 192.0.2.1:0, got %#v", got)
+		}
+	})
+}
 
 func TestICMPv6MakeRequest(t *testing.T) {
 	t.Parallel()
@@ -495,6 +680,67 @@ func TestICMPv6_ValidateReply(t *testing.T) {
 	})
 }
 
+func TestICMPv6_ValidateReplyError(t *testing.T) {
+	t.Parallel()
+
+	protocol := &ICMPv6{}
+
+	// newError returns an error message of the given type and code quoting the echo request
+	newError := func(typ ipv6.ICMPType, code int, sequence uint16) []byte {
+		request, _ := protocol.MakeRequest(1234, sequence)
+		data := make([]byte, ipv6.HeaderLen, ipv6.HeaderLen+8)
+		data[0] = ipv6.Version << 4
+		data[6] = byte(icmpv6ProtocolNumber) // Next header
+		data = append(data, request[:8]...)
+
+		msg := icmp.Message{Type: typ, Code: code, Body: &icmp.DstUnreach{Data: data}}
+		if typ == ipv6.ICMPTypeTimeExceeded {
+			msg.Body = &icmp.TimeExceeded{Data: data}
+		}
+		b, _ := msg.Marshal(nil)
+		return b
+	}
+
+	t.Run("Address Unreachable", func(t *testing.T) {
+		t.Parallel()
+
+		err := protocol.ValidateReply(newError(ipv6.ICMPTypeDestinationUnreachable, 3, 1), 1234, 1)
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := "destination address unreachable"
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Hop Limit Exceeded", func(t *testing.T) {
+		t.Parallel()
+
+		err := protocol.ValidateReply(newError(ipv6.ICMPTypeTimeExceeded, 0, 1), 1234, 1)
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := "hop limit exceeded in transit"
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Error For Another Request", func(t *testing.T) {
+		t.Parallel()
+
+		err := protocol.ValidateReply(newError(ipv6.ICMPTypeDestinationUnreachable, 3, 2), 1234, 1)
+
+		var unrelatedErr *unrelatedPacketError
+		if !errors.As(err, &unrelatedErr) {
+			t.Fatalf("expected an unrelated packet error, got %v", err)
+		}
+	})
+}
+
 func TestICMPv6_ListenPacket(t *testing.T) {
 	t.Parallel()
 
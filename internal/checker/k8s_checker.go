@@ -0,0 +1,384 @@
+package checker
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	envK8sAPIServer string = "K8S_API_SERVER"
+	envK8sNamespace string = "K8S_NAMESPACE"
+	envK8sTokenFile string = "K8S_TOKEN_FILE"
+	envK8sCAFile    string = "K8S_CA_FILE"
+	envK8sCondition string = "K8S_CONDITION"
+
+	envK8sServiceHost string = "KUBERNETES_SERVICE_HOST" // Set by Kubernetes in every container.
+	envK8sServicePort string = "KUBERNETES_SERVICE_PORT" // Set by Kubernetes in every container.
+
+	defaultK8sNamespace string = "default"
+
+	k8sServiceAccountDir string = "/var/run/secrets/kubernetes.io/serviceaccount" // The mount path of the service account.
+	k8sMaxResponseSize   int64  = 1 << 20                                         // The maximum size of a response read from the API server.
+)
+
+// k8sResource describes how to fetch and evaluate a kind of Kubernetes resource.
+type k8sResource struct {
+	Kind     string                     // The name of the kind used in messages.
+	APIPath  string                     // The API group path, e.g. "apis/apps/v1".
+	Plural   string                     // The plural resource name used in the URL.
+	Evaluate func(obj *k8sObject) error // The function deciding whether the resource is ready.
+}
+
+// k8sResources maps the supported kinds, including their plural and short names, to their resources.
+var k8sResources = func() map[string]k8sResource {
+	resources := map[string]k8sResource{}
+	register := func(r k8sResource, aliases ...string) {
+		for _, alias := range aliases {
+			resources[alias] = r
+		}
+	}
+
+	register(k8sResource{Kind: "deployment", APIPath: "apis/apps/v1", Plural: "deployments", Evaluate: evaluateK8sDeployment}, "deployment", "deployments", "deploy")
+	register(k8sResource{Kind: "statefulset", APIPath: "apis/apps/v1", Plural: "statefulsets", Evaluate: evaluateK8sStatefulSet}, "statefulset", "statefulsets", "sts")
+	register(k8sResource{Kind: "daemonset", APIPath: "apis/apps/v1", Plural: "daemonsets", Evaluate: evaluateK8sDaemonSet}, "daemonset", "daemonsets", "ds")
+	register(k8sResource{Kind: "pod", APIPath: "api/v1", Plural: "pods", Evaluate: evaluateK8sPod}, "pod", "pods", "po")
+	register(k8sResource{Kind: "job", APIPath: "apis/batch/v1", Plural: "jobs", Evaluate: evaluateK8sJob}, "job", "jobs")
+	register(k8sResource{Kind: "endpoints", APIPath: "api/v1", Plural: "endpoints", Evaluate: evaluateK8sEndpoints}, "endpoints", "ep")
+
+	return resources
+}()
+
+// K8sChecker implements the Checker interface for Kubernetes resource readiness checks.
+type K8sChecker struct {
+	Name         string       // The name of the checker.
+	Address      string       // The resource in the format "[namespace/]kind/name".
+	Namespace    string       // The namespace of the resource.
+	ResourceName string       // The name of the resource.
+	Resource     k8sResource  // The kind of the resource.
+	Condition    string       // The condition which must be "True". If empty, the default evaluation of the kind is used.
+	APIServer    string       // The URL of the API server.
+	TokenFile    string       // The file containing the bearer token. If empty, no token is sent.
+	client       *http.Client // The HTTP client to use for the requests.
+}
+
+// String returns the name of the checker.
+func (c *K8sChecker) String() string {
+	return c.Name
+}
+
+// NewK8sChecker creates a new K8sChecker.
+func NewK8sChecker(name, address string, timeout time.Duration, getEnv func(string) string) (Checker, error) {
+	// The "k8s://" prefix is used to identify the check type and is not needed for further processing,
+	// so it must be removed before passing the address to other functions.
+	address = strings.TrimPrefix(address, "k8s://")
+
+	checker := K8sChecker{
+		Name:      name,
+		Address:   address,
+		Condition: getEnv(envK8sCondition),
+	}
+
+	// Parse the resource in the format "[namespace/]kind/name"
+	parts := strings.Split(address, "/")
+	if len(parts) < 2 || len(parts) > 3 || slices.Contains(parts, "") {
+		return nil, fmt.Errorf("invalid resource %q: expected [namespace/]kind/name", address)
+	}
+	if len(parts) == 3 {
+		checker.Namespace, parts = parts[0], parts[1:]
+	}
+
+	resource, ok := k8sResources[strings.ToLower(parts[0])]
+	if !ok {
+		return nil, fmt.Errorf("unsupported resource kind: %s", parts[0])
+	}
+	checker.Resource = resource
+	checker.ResourceName = parts[1]
+
+	// Determine the namespace: the address takes precedence over the environment and the service account
+	if checker.Namespace == "" {
+		checker.Namespace = getEnv(envK8sNamespace)
+	}
+	if checker.Namespace == "" {
+		checker.Namespace = defaultK8sNamespace
+		if ns, err := readCredentialFile(path.Join(k8sServiceAccountDir, "namespace")); err == nil && ns != "" {
+			checker.Namespace = ns
+		}
+	}
+
+	// Determine the API server, defaulting to the in-cluster service
+	checker.APIServer = strings.TrimSuffix(getEnv(envK8sAPIServer), "/")
+	if checker.APIServer == "" {
+		host, port := getEnv(envK8sServiceHost), getEnv(envK8sServicePort)
+		if host == "" || port == "" {
+			return nil, fmt.Errorf("%s is required when not running in a Kubernetes cluster", envK8sAPIServer)
+		}
+		checker.APIServer = "https://" + net.JoinHostPort(host, port)
+	}
+
+	// Use the service account token unless another token file is specified
+	checker.TokenFile = getEnv(envK8sTokenFile)
+	if checker.TokenFile == "" {
+		if tokenFile := path.Join(k8sServiceAccountDir, "token"); fileExists(tokenFile) {
+			checker.TokenFile = tokenFile
+		}
+	}
+
+	// Trust the service account CA unless another CA file is specified
+	tlsConfig := &tls.Config{}
+	caFile := getEnv(envK8sCAFile)
+	if caFile == "" {
+		if serviceAccountCA := path.Join(k8sServiceAccountDir, "ca.crt"); fileExists(serviceAccountCA) {
+			caFile = serviceAccountCA
+		}
+	}
+	if caFile != "" {
+		pool, err := loadCertPool(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value: %w", envK8sCAFile, err)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	// Connections originate from the configured source address and interface
+	dialer, err := newDialer("tcp", timeout, getEnv)
+	if err != nil {
+		return nil, err
+	}
+
+	// The API server is always reached directly, never through a proxy
+	checker.client = &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:     dialer.DialContext,
+			TLSClientConfig: tlsConfig,
+		},
+	}
+
+	return &checker, nil
+}
+
+// Check fetches the resource from the API server and evaluates its status.
+func (c *K8sChecker) Check(ctx context.Context) error {
+	url := fmt.Sprintf("%s/%s/namespaces/%s/%s/%s", c.APIServer, c.Resource.APIPath, c.Namespace, c.Resource.Plural, c.ResourceName)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	// The token is read on every check, since projected service account tokens are rotated
+	if c.TokenFile != "" {
+		token, err := readCredentialFile(c.TokenFile)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, k8sMaxResponseSize))
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return k8sStatusError(resp.StatusCode, body)
+	}
+
+	var obj k8sObject
+	if err := json.Unmarshal(body, &obj); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", c.Resource.Kind, err)
+	}
+
+	if c.Condition != "" {
+		err = requireK8sCondition(obj.Status.Conditions, c.Condition)
+	} else {
+		err = c.Resource.Evaluate(&obj)
+	}
+	if err != nil {
+		return fmt.Errorf("%s %s/%s is not ready: %w", c.Resource.Kind, c.Namespace, c.ResourceName, err)
+	}
+
+	return nil
+}
+
+// k8sStatusError turns an unsuccessful API response into an error, using the message of the returned Status object if present.
+func k8sStatusError(statusCode int, body []byte) error {
+	var status struct {
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(body, &status); err == nil && status.Message != "" {
+		return fmt.Errorf("unexpected status code %d: %s", statusCode, status.Message)
+	}
+
+	return fmt.Errorf("unexpected status code %d", statusCode)
+}
+
+// k8sCondition is a condition of a Kubernetes resource.
+type k8sCondition struct {
+	Type    string `json:"type"`
+	Status  string `json:"status"`
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+}
+
+// k8sObject holds the fields of all supported kinds which are needed to evaluate their readiness.
+type k8sObject struct {
+	Metadata struct {
+		Generation int64 `json:"generation"`
+	} `json:"metadata"`
+	Spec struct {
+		Replicas *int32 `json:"replicas"`
+	} `json:"spec"`
+	Status struct {
+		ObservedGeneration     int64          `json:"observedGeneration"`
+		Conditions             []k8sCondition `json:"conditions"`
+		ReadyReplicas          int32          `json:"readyReplicas"`
+		AvailableReplicas      int32          `json:"availableReplicas"`
+		UpdatedReplicas        int32          `json:"updatedReplicas"`
+		DesiredNumberScheduled int32          `json:"desiredNumberScheduled"`
+		NumberReady            int32          `json:"numberReady"`
+		UpdatedNumberScheduled int32          `json:"updatedNumberScheduled"`
+	} `json:"status"`
+	Subsets []struct {
+		Addresses []struct {
+			IP string `json:"ip"`
+		} `json:"addresses"`
+	} `json:"subsets"`
+}
+
+// replicas returns the desired number of replicas, which defaults to 1.
+func (o *k8sObject) replicas() int32 {
+	if o.Spec.Replicas == nil {
+		return 1
+	}
+	return *o.Spec.Replicas
+}
+
+// findK8sCondition returns the condition of the given type, or nil.
+func findK8sCondition(conditions []k8sCondition, conditionType string) *k8sCondition {
+	for i := range conditions {
+		if strings.EqualFold(conditions[i].Type, conditionType) {
+			return &conditions[i]
+		}
+	}
+	return nil
+}
+
+// requireK8sCondition returns an error unless the condition of the given type has the status "True".
+func requireK8sCondition(conditions []k8sCondition, conditionType string) error {
+	condition := findK8sCondition(conditions, conditionType)
+	if condition == nil {
+		return fmt.Errorf("condition %s not found", conditionType)
+	}
+	if condition.Status == "True" {
+		return nil
+	}
+	if condition.Message != "" {
+		return fmt.Errorf("condition %s is %s: %s", condition.Type, condition.Status, condition.Message)
+	}
+	return fmt.Errorf("condition %s is %s", condition.Type, condition.Status)
+}
+
+// requireK8sObservedGeneration returns an error unless the controller has observed the latest spec of the resource.
+// Until then, the status still describes the previous spec.
+func requireK8sObservedGeneration(obj *k8sObject) error {
+	if obj.Status.ObservedGeneration < obj.Metadata.Generation {
+		return fmt.Errorf("generation %d not observed yet, status is from generation %d", obj.Metadata.Generation, obj.Status.ObservedGeneration)
+	}
+	return nil
+}
+
+// evaluateK8sDeployment requires the latest spec to be rolled out, the Available condition and all replicas to be available.
+// Like "kubectl rollout status", replicas of the previous version do not count while a rollout is in progress.
+func evaluateK8sDeployment(obj *k8sObject) error {
+	if err := requireK8sObservedGeneration(obj); err != nil {
+		return err
+	}
+	if obj.Status.UpdatedReplicas < obj.replicas() {
+		return fmt.Errorf("%d/%d replicas updated", obj.Status.UpdatedReplicas, obj.replicas())
+	}
+	if err := requireK8sCondition(obj.Status.Conditions, "Available"); err != nil {
+		return err
+	}
+	if obj.Status.AvailableReplicas < obj.replicas() {
+		return fmt.Errorf("%d/%d replicas available", obj.Status.AvailableReplicas, obj.replicas())
+	}
+	return nil
+}
+
+// evaluateK8sStatefulSet requires the latest spec to be rolled out and all replicas to be ready.
+func evaluateK8sStatefulSet(obj *k8sObject) error {
+	if err := requireK8sObservedGeneration(obj); err != nil {
+		return err
+	}
+	if obj.Status.UpdatedReplicas < obj.replicas() {
+		return fmt.Errorf("%d/%d replicas updated", obj.Status.UpdatedReplicas, obj.replicas())
+	}
+	if obj.Status.ReadyReplicas < obj.replicas() {
+		return fmt.Errorf("%d/%d replicas ready", obj.Status.ReadyReplicas, obj.replicas())
+	}
+	return nil
+}
+
+// evaluateK8sDaemonSet requires the latest spec to be rolled out and the pods on all scheduled nodes to be ready.
+func evaluateK8sDaemonSet(obj *k8sObject) error {
+	if err := requireK8sObservedGeneration(obj); err != nil {
+		return err
+	}
+	if obj.Status.UpdatedNumberScheduled < obj.Status.DesiredNumberScheduled {
+		return fmt.Errorf("%d/%d pods updated", obj.Status.UpdatedNumberScheduled, obj.Status.DesiredNumberScheduled)
+	}
+	if obj.Status.NumberReady < obj.Status.DesiredNumberScheduled {
+		return fmt.Errorf("%d/%d pods ready", obj.Status.NumberReady, obj.Status.DesiredNumberScheduled)
+	}
+	return nil
+}
+
+// evaluateK8sPod requires the Ready condition.
+func evaluateK8sPod(obj *k8sObject) error {
+	return requireK8sCondition(obj.Status.Conditions, "Ready")
+}
+
+// evaluateK8sJob requires the Complete condition. A failed job is reported as such.
+func evaluateK8sJob(obj *k8sObject) error {
+	if failed := findK8sCondition(obj.Status.Conditions, "Failed"); failed != nil && failed.Status == "True" {
+		if failed.Message != "" {
+			return fmt.Errorf("job failed: %s", failed.Message)
+		}
+		return fmt.Errorf("job failed: %s", failed.Reason)
+	}
+	return requireK8sCondition(obj.Status.Conditions, "Complete")
+}
+
+// evaluateK8sEndpoints requires at least one ready address.
+func evaluateK8sEndpoints(obj *k8sObject) error {
+	for _, subset := range obj.Subsets {
+		if len(subset.Addresses) > 0 {
+			return nil
+		}
+	}
+	return errors.New("no ready addresses")
+}
+
+// fileExists reports whether the given path exists.
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
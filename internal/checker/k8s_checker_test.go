@@ -0,0 +1,287 @@
+package checker
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// startFakeK8sAPIServer starts an HTTP server which answers requests for the given paths with the given JSON objects.
+// Requests without the expected bearer token are rejected.
+func startFakeK8sAPIServer(t *testing.T, token string, objects map[string]string) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.Header.Get("Authorization") != "Bearer "+token {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"kind":"Status","status":"Failure","message":"Unauthorized","code":401}`)
+			return
+		}
+
+		obj, ok := objects[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprintf(w, `{"kind":"Status","status":"Failure","message":"%s not found","code":404}`, r.URL.Path)
+			return
+		}
+
+		fmt.Fprint(w, obj)
+	}))
+}
+
+func TestNewK8sChecker(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Valid Kubernetes checker config", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envK8sServiceHost: "10.96.0.1",
+				envK8sServicePort: "443",
+				envK8sNamespace:   "prod",
+				envK8sCondition:   "Progressing",
+			}
+			return env[key]
+		}
+
+		checker, err := NewK8sChecker("example", "k8s://deploy/api", 1*time.Second, mockEnv)
+		if err != nil {
+			t.Fatalf("failed to create K8sChecker: %q", err)
+		}
+
+		k8sChecker := checker.(*K8sChecker)
+
+		expected := "https://10.96.0.1:443"
+		if k8sChecker.APIServer != expected {
+			t.Errorf("expected APIServer to be %q, got %q", expected, k8sChecker.APIServer)
+		}
+
+		if k8sChecker.Namespace != "prod" {
+			t.Errorf("expected Namespace to be %q, got %q", "prod", k8sChecker.Namespace)
+		}
+
+		if k8sChecker.Resource.Plural != "deployments" || k8sChecker.ResourceName != "api" {
+			t.Errorf("expected resource deployments/api, got %s/%s", k8sChecker.Resource.Plural, k8sChecker.ResourceName)
+		}
+
+		if k8sChecker.Condition != "Progressing" {
+			t.Errorf("expected Condition to be %q, got %q", "Progressing", k8sChecker.Condition)
+		}
+	})
+
+	t.Run("Namespace in address", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envK8sAPIServer: "https://kubernetes.default.svc/",
+				envK8sNamespace: "prod",
+			}
+			return env[key]
+		}
+
+		checker, err := NewK8sChecker("example", "k8s://batch/job/migrate", 1*time.Second, mockEnv)
+		if err != nil {
+			t.Fatalf("failed to create K8sChecker: %q", err)
+		}
+
+		k8sChecker := checker.(*K8sChecker)
+
+		if k8sChecker.Namespace != "batch" {
+			t.Errorf("expected Namespace to be %q, got %q", "batch", k8sChecker.Namespace)
+		}
+
+		expected := "https://kubernetes.default.svc"
+		if k8sChecker.APIServer != expected {
+			t.Errorf("expected APIServer to be %q, got %q", expected, k8sChecker.APIServer)
+		}
+	})
+
+	t.Run("Invalid resource", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := NewK8sChecker("example", "k8s://deployment/", 1*time.Second, func(string) string { return "" })
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := `invalid resource "deployment/": expected [namespace/]kind/name`
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Unsupported resource kind", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := NewK8sChecker("example", "k8s://cronjob/backup", 1*time.Second, func(string) string { return "" })
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := "unsupported resource kind: cronjob"
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Missing API server", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := NewK8sChecker("example", "k8s://deployment/api", 1*time.Second, func(string) string { return "" })
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := fmt.Sprintf("%s is required when not running in a Kubernetes cluster", envK8sAPIServer)
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Invalid CA file", func(t *testing.T) {
+		t.Parallel()
+
+		caFile := writeCredential(t, "ca.crt", "not a certificate")
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envK8sAPIServer: "https://kubernetes.default.svc",
+				envK8sCAFile:    caFile,
+			}
+			return env[key]
+		}
+
+		_, err := NewK8sChecker("example", "k8s://deployment/api", 1*time.Second, mockEnv)
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := fmt.Sprintf("invalid %s value: no valid PEM certificates found in %s", envK8sCAFile, caFile)
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+}
+
+func TestK8sChecker(t *testing.T) {
+	t.Parallel()
+
+	server := startFakeK8sAPIServer(t, "s3cr3t", map[string]string{
+		"/apis/apps/v1/namespaces/prod/deployments/ready":       `{"metadata":{"generation":4},"spec":{"replicas":2},"status":{"observedGeneration":4,"updatedReplicas":2,"availableReplicas":2,"conditions":[{"type":"Available","status":"True"}]}}`,
+		"/apis/apps/v1/namespaces/prod/deployments/scaling":     `{"metadata":{"generation":4},"spec":{"replicas":3},"status":{"observedGeneration":4,"updatedReplicas":3,"availableReplicas":1,"conditions":[{"type":"Available","status":"True"}]}}`,
+		"/apis/apps/v1/namespaces/prod/deployments/unavailable": `{"metadata":{"generation":1},"spec":{"replicas":1},"status":{"observedGeneration":1,"updatedReplicas":1,"conditions":[{"type":"Available","status":"False","message":"Deployment does not have minimum availability."}]}}`,
+		"/apis/apps/v1/namespaces/prod/deployments/rollout":     `{"metadata":{"generation":5},"spec":{"replicas":2},"status":{"observedGeneration":5,"updatedReplicas":1,"availableReplicas":2,"conditions":[{"type":"Available","status":"True"}]}}`,
+		"/apis/apps/v1/namespaces/prod/deployments/unobserved":  `{"metadata":{"generation":5},"spec":{"replicas":2},"status":{"observedGeneration":4,"updatedReplicas":2,"availableReplicas":2,"conditions":[{"type":"Available","status":"True"}]}}`,
+		"/apis/apps/v1/namespaces/prod/statefulsets/db":         `{"metadata":{"generation":2},"spec":{"replicas":3},"status":{"observedGeneration":2,"updatedReplicas":3,"readyReplicas":2}}`,
+		"/apis/apps/v1/namespaces/prod/statefulsets/cache":      `{"metadata":{"generation":2},"spec":{"replicas":3},"status":{"observedGeneration":2,"updatedReplicas":1,"readyReplicas":3}}`,
+		"/apis/apps/v1/namespaces/prod/daemonsets/agent":        `{"metadata":{"generation":1},"status":{"observedGeneration":1,"desiredNumberScheduled":3,"updatedNumberScheduled":3,"numberReady":3}}`,
+		"/apis/apps/v1/namespaces/prod/daemonsets/new":          `{"metadata":{"generation":1},"status":{"desiredNumberScheduled":0,"numberReady":0}}`,
+		"/api/v1/namespaces/prod/pods/web-0":                    `{"status":{"conditions":[{"type":"Ready","status":"True"}]}}`,
+		"/apis/batch/v1/namespaces/prod/jobs/migrate":           `{"status":{"conditions":[{"type":"Complete","status":"True"}]}}`,
+		"/apis/batch/v1/namespaces/prod/jobs/running":           `{"status":{"active":1}}`,
+		"/apis/batch/v1/namespaces/prod/jobs/failed":            `{"status":{"conditions":[{"type":"Failed","status":"True","reason":"BackoffLimitExceeded","message":"Job has reached the specified backoff limit"}]}}`,
+		"/api/v1/namespaces/prod/endpoints/db":                  `{"subsets":[{"notReadyAddresses":[{"ip":"10.0.0.2"}]},{"addresses":[{"ip":"10.0.0.1"}]}]}`,
+		"/api/v1/namespaces/prod/endpoints/empty":               `{"subsets":[{"notReadyAddresses":[{"ip":"10.0.0.2"}]}]}`,
+	})
+	t.Cleanup(server.Close)
+
+	tokenFile := writeCredential(t, "token", "s3cr3t")
+
+	tests := []struct {
+		name      string
+		address   string
+		condition string
+		expected  string
+	}{
+		{name: "Deployment available", address: "k8s://deployment/ready"},
+		{name: "Deployment scaling", address: "k8s://deployment/scaling", expected: "deployment prod/scaling is not ready: 1/3 replicas available"},
+		{name: "Deployment unavailable", address: "k8s://deployment/unavailable", expected: "deployment prod/unavailable is not ready: condition Available is False: Deployment does not have minimum availability."},
+		{name: "Deployment rollout in progress", address: "k8s://deployment/rollout", expected: "deployment prod/rollout is not ready: 1/2 replicas updated"},
+		{name: "Deployment spec not observed", address: "k8s://deployment/unobserved", expected: "deployment prod/unobserved is not ready: generation 5 not observed yet, status is from generation 4"},
+		{name: "StatefulSet not ready", address: "k8s://sts/db", expected: "statefulset prod/db is not ready: 2/3 replicas ready"},
+		{name: "StatefulSet rollout in progress", address: "k8s://sts/cache", expected: "statefulset prod/cache is not ready: 1/3 replicas updated"},
+		{name: "DaemonSet ready", address: "k8s://daemonset/agent"},
+		{name: "DaemonSet not observed", address: "k8s://daemonset/new", expected: "daemonset prod/new is not ready: generation 1 not observed yet, status is from generation 0"},
+		{name: "Pod ready", address: "k8s://pod/web-0"},
+		{name: "Job complete", address: "k8s://job/migrate"},
+		{name: "Job running", address: "k8s://job/running", expected: "job prod/running is not ready: condition Complete not found"},
+		{name: "Job failed", address: "k8s://job/failed", expected: "job prod/failed is not ready: job failed: Job has reached the specified backoff limit"},
+		{name: "Endpoints ready", address: "k8s://endpoints/db"},
+		{name: "Endpoints without ready addresses", address: "k8s://ep/empty", expected: "endpoints prod/empty is not ready: no ready addresses"},
+		{name: "Custom condition", address: "k8s://deployment/unavailable", condition: "Progressing", expected: "deployment prod/unavailable is not ready: condition Progressing not found"},
+		{name: "Resource not found", address: "k8s://deployment/missing", expected: "unexpected status code 404: /apis/apps/v1/namespaces/prod/deployments/missing not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockEnv := func(key string) string {
+				env := map[string]string{
+					envK8sAPIServer: server.URL,
+					envK8sNamespace: "prod",
+					envK8sTokenFile: tokenFile,
+					envK8sCondition: tt.condition,
+				}
+				return env[key]
+			}
+
+			checker, err := NewK8sChecker("example", tt.address, 1*time.Second, mockEnv)
+			if err != nil {
+				t.Fatalf("failed to create K8sChecker: %q", err)
+			}
+
+			err = checker.Check(context.Background())
+			if tt.expected == "" {
+				if err != nil {
+					t.Fatalf("expected no error, got %q", err)
+				}
+				return
+			}
+
+			if err == nil {
+				t.Fatal("expected an error, got none")
+			}
+			if err.Error() != tt.expected {
+				t.Errorf("expected error %q, got %q", tt.expected, err.Error())
+			}
+		})
+	}
+
+	t.Run("Invalid token", func(t *testing.T) {
+		t.Parallel()
+
+		expiredTokenFile := writeCredential(t, "token", "expired")
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envK8sAPIServer: server.URL,
+				envK8sNamespace: "prod",
+				envK8sTokenFile: expiredTokenFile,
+			}
+			return env[key]
+		}
+
+		checker, err := NewK8sChecker("example", "k8s://deployment/ready", 1*time.Second, mockEnv)
+		if err != nil {
+			t.Fatalf("failed to create K8sChecker: %q", err)
+		}
+
+		err = checker.Check(context.Background())
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := "unexpected status code 401: Unauthorized"
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+}
@@ -0,0 +1,363 @@
+package checker
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+const (
+	envKafkaTopic    string = "KAFKA_TOPIC"
+	envKafkaClientID string = "KAFKA_CLIENT_ID"
+
+	defaultKafkaClientID string = "portpatrol"
+
+	kafkaAPIKeyMetadata     int16 = 3
+	kafkaAPIKeyAPIVersions  int16 = 18
+	kafkaMetadataVersion    int16 = 4       // Metadata v4 allows disabling automatic topic creation.
+	kafkaMaxResponseSize    int32 = 1 << 24 // The maximum accepted response size (16 MiB).
+	kafkaErrorNone          int16 = 0
+	kafkaErrorUnknownTopic  int16 = 3
+	kafkaErrorLeaderMissing int16 = 5
+)
+
+// KafkaChecker implements the Checker interface for Kafka broker readiness checks.
+type KafkaChecker struct {
+	Name     string        // The name of the checker.
+	Address  string        // The address of the broker.
+	Topic    string        // The topic which must exist with a leader on every partition. If empty, topics are not checked.
+	ClientID string        // The client ID sent with every request.
+	dialer   contextDialer // The dialer to use for the connection.
+	timeout  time.Duration // The timeout for the whole exchange with the server.
+}
+
+// String returns the name of the checker.
+func (c *KafkaChecker) String() string {
+	return c.Name
+}
+
+// NewKafkaChecker creates a new KafkaChecker.
+func NewKafkaChecker(name, address string, timeout time.Duration, getEnv func(string) string) (Checker, error) {
+	// The "kafka://" prefix is used to identify the check type and is not needed for further processing,
+	// so it must be removed before passing the address to other functions.
+	address = strings.TrimPrefix(address, "kafka://")
+
+	// Connections originate from the configured source address and interface and go through the configured proxy
+	dialer, err := newProxyDialer(timeout, getEnv)
+	if err != nil {
+		return nil, err
+	}
+
+	checker := KafkaChecker{
+		Name:     name,
+		Address:  address,
+		Topic:    getEnv(envKafkaTopic),
+		ClientID: defaultKafkaClientID,
+		dialer:   dialer,
+		timeout:  timeout,
+	}
+
+	// Override the default client ID if specified
+	if clientID := getEnv(envKafkaClientID); clientID != "" {
+		checker.ClientID = clientID
+	}
+
+	return &checker, nil
+}
+
+// Check sends an ApiVersions and a Metadata request to the broker and validates the responses.
+func (c *KafkaChecker) Check(ctx context.Context) error {
+	conn, err := c.dialer.DialContext(ctx, "tcp", c.Address)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// A broker may accept the connection long before it answers requests
+	stop, err := deadlineConn(ctx, conn, c.timeout)
+	if err != nil {
+		return err
+	}
+	defer stop()
+
+	// Verify the broker answers and supports the Metadata version used below
+	resp, err := c.roundTrip(conn, 1, kafkaAPIKeyAPIVersions, 0, nil)
+	if err != nil {
+		return fmt.Errorf("failed to request API versions: %w", err)
+	}
+	if err := validateKafkaAPIVersions(resp); err != nil {
+		return err
+	}
+
+	// Request the cluster metadata, including the topic if configured
+	resp, err = c.roundTrip(conn, 2, kafkaAPIKeyMetadata, kafkaMetadataVersion, encodeKafkaMetadataRequest(c.Topic))
+	if err != nil {
+		return fmt.Errorf("failed to request metadata: %w", err)
+	}
+
+	return validateKafkaMetadata(resp, c.Topic)
+}
+
+// roundTrip writes a request with the given header fields and returns the response body
+// following the correlation ID.
+func (c *KafkaChecker) roundTrip(conn net.Conn, correlationID int32, apiKey, apiVersion int16, body []byte) ([]byte, error) {
+	w := &kafkaWriter{}
+	w.int16(apiKey)
+	w.int16(apiVersion)
+	w.int32(correlationID)
+	w.string(c.ClientID)
+	w.buf = append(w.buf, body...)
+
+	frame := binary.BigEndian.AppendUint32(nil, uint32(len(w.buf)))
+	if _, err := conn.Write(append(frame, w.buf...)); err != nil {
+		return nil, err
+	}
+
+	var size int32
+	if err := binary.Read(conn, binary.BigEndian, &size); err != nil {
+		return nil, err
+	}
+	if size < 4 || size > kafkaMaxResponseSize {
+		return nil, fmt.Errorf("invalid response size: %d", size)
+	}
+
+	resp := make([]byte, size)
+	if _, err := io.ReadFull(conn, resp); err != nil {
+		return nil, err
+	}
+
+	if got := int32(binary.BigEndian.Uint32(resp)); got != correlationID {
+		return nil, fmt.Errorf("correlation ID mismatch: got %d, expected %d", got, correlationID)
+	}
+
+	return resp[4:], nil
+}
+
+// validateKafkaAPIVersions validates an ApiVersions v0 response body and ensures
+// the broker supports the Metadata version used by the checker.
+func validateKafkaAPIVersions(body []byte) error {
+	r := &kafkaReader{buf: body}
+
+	if code := r.int16(); r.err == nil && code != kafkaErrorNone {
+		return fmt.Errorf("broker returned error code %d for API versions request", code)
+	}
+
+	supported := false
+	for i, n := 0, r.arrayLen(); i < n && r.err == nil; i++ {
+		apiKey, minVersion, maxVersion := r.int16(), r.int16(), r.int16()
+		if apiKey == kafkaAPIKeyMetadata && minVersion <= kafkaMetadataVersion && kafkaMetadataVersion <= maxVersion {
+			supported = true
+		}
+	}
+	if r.err != nil {
+		return fmt.Errorf("failed to parse ApiVersions response: %w", r.err)
+	}
+
+	if !supported {
+		return fmt.Errorf("broker does not support Metadata v%d", kafkaMetadataVersion)
+	}
+
+	return nil
+}
+
+// encodeKafkaMetadataRequest encodes a Metadata v4 request body for the given topic.
+// If the topic is empty, no topics are requested.
+func encodeKafkaMetadataRequest(topic string) []byte {
+	w := &kafkaWriter{}
+	if topic == "" {
+		w.int32(0)
+	} else {
+		w.int32(1)
+		w.string(topic)
+	}
+	w.bool(false) // allow_auto_topic_creation
+
+	return w.buf
+}
+
+// validateKafkaMetadata validates a Metadata v4 response body. It requires at least one broker,
+// an active controller and, if a topic is given, a leader for every partition of the topic.
+func validateKafkaMetadata(body []byte, topic string) error {
+	r := &kafkaReader{buf: body}
+
+	r.int32() // throttle_time_ms
+
+	brokers := r.arrayLen()
+	for i := 0; i < brokers && r.err == nil; i++ {
+		r.int32()          // node_id
+		r.string()         // host
+		r.int32()          // port
+		r.nullableString() // rack
+	}
+
+	r.nullableString() // cluster_id
+	controllerID := r.int32()
+
+	type partition struct {
+		index  int32
+		code   int16
+		leader int32
+	}
+	topicFound := false
+	topicCode := kafkaErrorNone
+	var partitions []partition
+
+	for i, n := 0, r.arrayLen(); i < n && r.err == nil; i++ {
+		code := r.int16()
+		name := r.string()
+		r.bool() // is_internal
+
+		for j, m := 0, r.arrayLen(); j < m && r.err == nil; j++ {
+			p := partition{code: r.int16(), index: r.int32(), leader: r.int32()}
+			r.int32Array() // replica_nodes
+			r.int32Array() // isr_nodes
+			if name == topic {
+				partitions = append(partitions, p)
+			}
+		}
+
+		if name == topic {
+			topicFound = true
+			topicCode = code
+		}
+	}
+	if r.err != nil {
+		return fmt.Errorf("failed to parse Metadata response: %w", r.err)
+	}
+
+	if brokers == 0 {
+		return errors.New("no brokers in cluster metadata")
+	}
+	if controllerID < 0 {
+		return errors.New("cluster has no active controller")
+	}
+
+	if topic == "" {
+		return nil
+	}
+
+	switch {
+	case !topicFound || topicCode == kafkaErrorUnknownTopic:
+		return fmt.Errorf("topic %q does not exist", topic)
+	case topicCode == kafkaErrorLeaderMissing:
+		return fmt.Errorf("topic %q has no leader", topic)
+	case topicCode != kafkaErrorNone:
+		return fmt.Errorf("topic %q returned error code %d", topic, topicCode)
+	case len(partitions) == 0:
+		return fmt.Errorf("topic %q has no partitions", topic)
+	}
+
+	for _, p := range partitions {
+		if p.code != kafkaErrorNone && p.code != kafkaErrorLeaderMissing {
+			return fmt.Errorf("partition %d of topic %q returned error code %d", p.index, topic, p.code)
+		}
+		if p.code == kafkaErrorLeaderMissing || p.leader < 0 {
+			return fmt.Errorf("partition %d of topic %q has no leader", p.index, topic)
+		}
+	}
+
+	return nil
+}
+
+// kafkaWriter encodes primitive Kafka protocol types.
+type kafkaWriter struct {
+	buf []byte
+}
+
+func (w *kafkaWriter) int16(v int16) {
+	w.buf = binary.BigEndian.AppendUint16(w.buf, uint16(v))
+}
+
+func (w *kafkaWriter) int32(v int32) {
+	w.buf = binary.BigEndian.AppendUint32(w.buf, uint32(v))
+}
+
+func (w *kafkaWriter) bool(v bool) {
+	if v {
+		w.buf = append(w.buf, 1)
+		return
+	}
+	w.buf = append(w.buf, 0)
+}
+
+func (w *kafkaWriter) string(v string) {
+	w.int16(int16(len(v)))
+	w.buf = append(w.buf, v...)
+}
+
+// kafkaReader decodes primitive Kafka protocol types.
+// The first decoding error is recorded and all subsequent reads return zero values.
+type kafkaReader struct {
+	buf []byte
+	err error
+}
+
+func (r *kafkaReader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || len(r.buf) < n {
+		r.err = io.ErrUnexpectedEOF
+		return nil
+	}
+	b := r.buf[:n]
+	r.buf = r.buf[n:]
+	return b
+}
+
+func (r *kafkaReader) int16() int16 {
+	b := r.next(2)
+	if b == nil {
+		return 0
+	}
+	return int16(binary.BigEndian.Uint16(b))
+}
+
+func (r *kafkaReader) int32() int32 {
+	b := r.next(4)
+	if b == nil {
+		return 0
+	}
+	return int32(binary.BigEndian.Uint32(b))
+}
+
+func (r *kafkaReader) bool() bool {
+	b := r.next(1)
+	return b != nil && b[0] != 0
+}
+
+func (r *kafkaReader) string() string {
+	return string(r.next(int(r.int16())))
+}
+
+func (r *kafkaReader) nullableString() string {
+	n := r.int16()
+	if n < 0 {
+		return ""
+	}
+	return string(r.next(int(n)))
+}
+
+// arrayLen reads an array length. Null arrays are treated as empty.
+func (r *kafkaReader) arrayLen() int {
+	n := r.int32()
+	if n < 0 {
+		return 0
+	}
+	if int(n) > len(r.buf) {
+		r.err = io.ErrUnexpectedEOF // Every element needs at least one byte
+		return 0
+	}
+	return int(n)
+}
+
+func (r *kafkaReader) int32Array() {
+	for i, n := 0, r.arrayLen(); i < n && r.err == nil; i++ {
+		r.int32()
+	}
+}
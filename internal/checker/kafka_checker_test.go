@@ -0,0 +1,387 @@
+package checker
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// kafkaTestPartition describes a partition in a fake Metadata response.
+type kafkaTestPartition struct {
+	code   int16
+	leader int32
+}
+
+// encodeKafkaTestAPIVersions encodes an ApiVersions v0 response body advertising the given Metadata versions.
+func encodeKafkaTestAPIVersions(minMetadata, maxMetadata int16) []byte {
+	w := &kafkaWriter{}
+	w.int16(kafkaErrorNone)
+	w.int32(2)
+	w.int16(kafkaAPIKeyAPIVersions)
+	w.int16(0)
+	w.int16(3)
+	w.int16(kafkaAPIKeyMetadata)
+	w.int16(minMetadata)
+	w.int16(maxMetadata)
+	return w.buf
+}
+
+// encodeKafkaTestMetadata encodes a Metadata v4 response body with a single broker.
+// If topic is empty, no topics are included.
+func encodeKafkaTestMetadata(controllerID int32, topic string, topicCode int16, partitions []kafkaTestPartition) []byte {
+	w := &kafkaWriter{}
+	w.int32(0) // throttle_time_ms
+	w.int32(1) // brokers
+	w.int32(1)
+	w.string("broker-1")
+	w.int32(9092)
+	w.int16(-1) // rack
+	w.string("cluster")
+	w.int32(controllerID)
+
+	if topic == "" {
+		w.int32(0)
+		return w.buf
+	}
+
+	w.int32(1)
+	w.int16(topicCode)
+	w.string(topic)
+	w.bool(false)
+	w.int32(int32(len(partitions)))
+	for i, p := range partitions {
+		w.int16(p.code)
+		w.int32(int32(i))
+		w.int32(p.leader)
+		w.int32(1) // replica_nodes
+		w.int32(1)
+		w.int32(0) // isr_nodes
+	}
+	return w.buf
+}
+
+// startFakeKafkaBroker starts a TCP server which answers requests with the given response bodies by API key.
+func startFakeKafkaBroker(t *testing.T, responses map[int16][]byte) net.Listener {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to start fake Kafka broker: %q", err)
+	}
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				for {
+					var size int32
+					if err := binary.Read(conn, binary.BigEndian, &size); err != nil {
+						return
+					}
+					req := make([]byte, size)
+					if _, err := io.ReadFull(conn, req); err != nil {
+						return
+					}
+
+					apiKey := int16(binary.BigEndian.Uint16(req[0:2]))
+					correlationID := req[4:8]
+
+					body, ok := responses[apiKey]
+					if !ok {
+						return
+					}
+
+					resp := binary.BigEndian.AppendUint32(nil, uint32(len(body)+4))
+					resp = append(resp, correlationID...)
+					resp = append(resp, body...)
+					if _, err := conn.Write(resp); err != nil {
+						return
+					}
+				}
+			}(conn)
+		}
+	}()
+
+	return ln
+}
+
+func TestNewKafkaChecker(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Valid Kafka checker config", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envKafkaTopic:    "orders",
+				envKafkaClientID: "init",
+			}
+			return env[key]
+		}
+
+		checker, err := NewKafkaChecker("example", "kafka://kafka:9092", 1*time.Second, mockEnv)
+		if err != nil {
+			t.Fatalf("failed to create KafkaChecker: %q", err)
+		}
+
+		kafkaChecker := checker.(*KafkaChecker)
+
+		expected := "kafka:9092"
+		if kafkaChecker.Address != expected {
+			t.Errorf("expected Address to be %q, got %q", expected, kafkaChecker.Address)
+		}
+
+		expected = "orders"
+		if kafkaChecker.Topic != expected {
+			t.Errorf("expected Topic to be %q, got %q", expected, kafkaChecker.Topic)
+		}
+
+		expected = "init"
+		if kafkaChecker.ClientID != expected {
+			t.Errorf("expected ClientID to be %q, got %q", expected, kafkaChecker.ClientID)
+		}
+	})
+
+	t.Run("Default client ID", func(t *testing.T) {
+		t.Parallel()
+
+		checker, err := NewKafkaChecker("example", "kafka:9092", 1*time.Second, func(string) string { return "" })
+		if err != nil {
+			t.Fatalf("failed to create KafkaChecker: %q", err)
+		}
+
+		if checker.(*KafkaChecker).ClientID != defaultKafkaClientID {
+			t.Errorf("expected ClientID to be %q, got %q", defaultKafkaClientID, checker.(*KafkaChecker).ClientID)
+		}
+	})
+}
+
+func TestKafkaChecker(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Valid Kafka check", func(t *testing.T) {
+		t.Parallel()
+
+		ln := startFakeKafkaBroker(t, map[int16][]byte{
+			kafkaAPIKeyAPIVersions: encodeKafkaTestAPIVersions(0, 12),
+			kafkaAPIKeyMetadata:    encodeKafkaTestMetadata(1, "", 0, nil),
+		})
+		defer ln.Close()
+
+		checker, err := NewKafkaChecker("example", ln.Addr().String(), 1*time.Second, func(string) string { return "" })
+		if err != nil {
+			t.Fatalf("failed to create KafkaChecker: %q", err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+
+		if err := checker.Check(ctx); err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+	})
+
+	t.Run("Valid Kafka check with topic", func(t *testing.T) {
+		t.Parallel()
+
+		ln := startFakeKafkaBroker(t, map[int16][]byte{
+			kafkaAPIKeyAPIVersions: encodeKafkaTestAPIVersions(0, 12),
+			kafkaAPIKeyMetadata:    encodeKafkaTestMetadata(1, "orders", kafkaErrorNone, []kafkaTestPartition{{leader: 1}, {leader: 2}}),
+		})
+		defer ln.Close()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envKafkaTopic: "orders",
+			}
+			return env[key]
+		}
+
+		checker, err := NewKafkaChecker("example", ln.Addr().String(), 1*time.Second, mockEnv)
+		if err != nil {
+			t.Fatalf("failed to create KafkaChecker: %q", err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+
+		if err := checker.Check(ctx); err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+	})
+
+	t.Run("Partition without leader", func(t *testing.T) {
+		t.Parallel()
+
+		ln := startFakeKafkaBroker(t, map[int16][]byte{
+			kafkaAPIKeyAPIVersions: encodeKafkaTestAPIVersions(0, 12),
+			kafkaAPIKeyMetadata:    encodeKafkaTestMetadata(1, "orders", kafkaErrorNone, []kafkaTestPartition{{leader: 1}, {leader: -1}}),
+		})
+		defer ln.Close()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envKafkaTopic: "orders",
+			}
+			return env[key]
+		}
+
+		checker, err := NewKafkaChecker("example", ln.Addr().String(), 1*time.Second, mockEnv)
+		if err != nil {
+			t.Fatalf("failed to create KafkaChecker: %q", err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+
+		err = checker.Check(ctx)
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := `partition 1 of topic "orders" has no leader`
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Unsupported Metadata version", func(t *testing.T) {
+		t.Parallel()
+
+		ln := startFakeKafkaBroker(t, map[int16][]byte{
+			kafkaAPIKeyAPIVersions: encodeKafkaTestAPIVersions(0, 2),
+		})
+		defer ln.Close()
+
+		checker, err := NewKafkaChecker("example", ln.Addr().String(), 1*time.Second, func(string) string { return "" })
+		if err != nil {
+			t.Fatalf("failed to create KafkaChecker: %q", err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+
+		err = checker.Check(ctx)
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := "broker does not support Metadata v4"
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Broker closes connection", func(t *testing.T) {
+		t.Parallel()
+
+		ln := startFakeKafkaBroker(t, map[int16][]byte{})
+		defer ln.Close()
+
+		checker, err := NewKafkaChecker("example", ln.Addr().String(), 1*time.Second, func(string) string { return "" })
+		if err != nil {
+			t.Fatalf("failed to create KafkaChecker: %q", err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+
+		err = checker.Check(ctx)
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := "failed to request API versions: EOF"
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Broker not listening", func(t *testing.T) {
+		t.Parallel()
+
+		checker, err := NewKafkaChecker("example", "127.0.0.1:1", 1*time.Second, func(string) string { return "" })
+		if err != nil {
+			t.Fatalf("failed to create KafkaChecker: %q", err)
+		}
+
+		err = checker.Check(context.Background())
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := "connection refused"
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected error containing %q, got %q", expected, err.Error())
+		}
+	})
+}
+
+func TestValidateKafkaMetadata(t *testing.T) {
+	t.Parallel()
+
+	t.Run("No active controller", func(t *testing.T) {
+		t.Parallel()
+
+		err := validateKafkaMetadata(encodeKafkaTestMetadata(-1, "", 0, nil), "")
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := "cluster has no active controller"
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Unknown topic", func(t *testing.T) {
+		t.Parallel()
+
+		err := validateKafkaMetadata(encodeKafkaTestMetadata(1, "orders", kafkaErrorUnknownTopic, nil), "orders")
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := `topic "orders" does not exist`
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Topic missing in response", func(t *testing.T) {
+		t.Parallel()
+
+		err := validateKafkaMetadata(encodeKafkaTestMetadata(1, "", 0, nil), "orders")
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := `topic "orders" does not exist`
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Truncated response", func(t *testing.T) {
+		t.Parallel()
+
+		body := encodeKafkaTestMetadata(1, "orders", kafkaErrorNone, []kafkaTestPartition{{leader: 1}})
+		err := validateKafkaMetadata(body[:len(body)-3], "orders")
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := "failed to parse Metadata response: unexpected EOF"
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+}
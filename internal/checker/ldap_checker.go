@@ -0,0 +1,424 @@
+package checker
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	envLDAPBindDN        string = "LDAP_BIND_DN"
+	envLDAPPasswordFile  string = "LDAP_PASSWORD_FILE"
+	envLDAPBaseDN        string = "LDAP_BASE_DN"
+	envLDAPCAFile        string = "LDAP_CA_FILE"
+	envLDAPSkipTLSVerify string = "LDAP_SKIP_TLS_VERIFY"
+
+	defaultLDAPSkipTLSVerify bool = false
+
+	ldapVersion        int = 3
+	ldapMaxMessageSize int = 1 << 20 // The maximum size of a message accepted from the server.
+
+	// BER tags of the LDAP protocol operations used by the checker (RFC 4511).
+	ldapTagBindRequest          byte = 0x60
+	ldapTagBindResponse         byte = 0x61
+	ldapTagUnbindRequest        byte = 0x42
+	ldapTagSearchRequest        byte = 0x63
+	ldapTagSearchResultEntry    byte = 0x64
+	ldapTagSearchResultDone     byte = 0x65
+	ldapTagSearchResultRef      byte = 0x73
+	ldapTagExtendedResponse     byte = 0x78
+	ldapTagSimpleAuthentication byte = 0x80
+	ldapTagPresentFilter        byte = 0x87
+
+	// Universal BER tags.
+	berTagBoolean     byte = 0x01
+	berTagInteger     byte = 0x02
+	berTagOctetString byte = 0x04
+	berTagEnumerated  byte = 0x0a
+	berTagSequence    byte = 0x30
+)
+
+// ldapResultCodes maps the most common LDAP result codes to their names.
+var ldapResultCodes = map[int]string{
+	0:  "success",
+	1:  "operationsError",
+	2:  "protocolError",
+	3:  "timeLimitExceeded",
+	4:  "sizeLimitExceeded",
+	7:  "authMethodNotSupported",
+	8:  "strongerAuthRequired",
+	32: "noSuchObject",
+	34: "invalidDNSyntax",
+	48: "inappropriateAuthentication",
+	49: "invalidCredentials",
+	50: "insufficientAccessRights",
+	51: "busy",
+	52: "unavailable",
+	53: "unwillingToPerform",
+	80: "other",
+}
+
+// LDAPChecker implements the Checker interface for LDAP checks using a bind and an optional base-object search.
+type LDAPChecker struct {
+	Name         string        // The name of the checker.
+	Address      string        // The address of the target.
+	BindDN       string        // The DN used for a simple bind. If empty, an anonymous bind is performed.
+	PasswordFile string        // The file containing the password for the simple bind.
+	BaseDN       string        // The DN of the entry to search for after binding. If empty, no search is performed.
+	ImplicitTLS  bool          // Whether TLS is used from the start (ldaps://).
+	tlsConfig    *tls.Config   // The TLS configuration for ldaps://.
+	dialer       contextDialer // The dialer to use for the connection.
+	timeout      time.Duration // The timeout for the whole exchange with the server.
+}
+
+// String returns the name of the checker.
+func (c *LDAPChecker) String() string {
+	return c.Name
+}
+
+// NewLDAPChecker creates a new LDAPChecker.
+func NewLDAPChecker(name, address string, timeout time.Duration, getEnv func(string) string) (Checker, error) {
+	// The "ldap://" and "ldaps://" prefixes are used to identify the check type and are not needed for further processing,
+	// so they must be removed before passing the address to other functions.
+	implicitTLS := strings.HasPrefix(address, "ldaps://")
+	address = strings.TrimPrefix(strings.TrimPrefix(address, "ldap://"), "ldaps://")
+
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address %s: %w", address, err)
+	}
+
+	// Connections originate from the configured source address and interface and go through the configured proxy
+	dialer, err := newProxyDialer(timeout, getEnv)
+	if err != nil {
+		return nil, err
+	}
+
+	checker := LDAPChecker{
+		Name:         name,
+		Address:      address,
+		BindDN:       getEnv(envLDAPBindDN),
+		PasswordFile: getEnv(envLDAPPasswordFile),
+		BaseDN:       getEnv(envLDAPBaseDN),
+		ImplicitTLS:  implicitTLS,
+		tlsConfig: &tls.Config{
+			ServerName:         host,
+			InsecureSkipVerify: defaultLDAPSkipTLSVerify,
+		},
+		dialer:  dialer,
+		timeout: timeout,
+	}
+
+	// A password without a DN would silently result in an anonymous bind
+	if checker.PasswordFile != "" && checker.BindDN == "" {
+		return nil, fmt.Errorf("%s requires %s to be set", envLDAPPasswordFile, envLDAPBindDN)
+	}
+
+	// A DN without a password is an unauthenticated bind (RFC 4513, section 5.1.2),
+	// which many servers accept without verifying any credentials
+	if checker.BindDN != "" && checker.PasswordFile == "" {
+		return nil, fmt.Errorf("%s requires %s to be set", envLDAPBindDN, envLDAPPasswordFile)
+	}
+
+	// Load the CA certificates if specified
+	if caFile := getEnv(envLDAPCAFile); caFile != "" {
+		pool, err := loadCertPool(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value: %w", envLDAPCAFile, err)
+		}
+		checker.tlsConfig.RootCAs = pool
+	}
+
+	// Determine if TLS verification should be skipped
+	if skipVerifyStr := getEnv(envLDAPSkipTLSVerify); skipVerifyStr != "" {
+		skipVerify, err := strconv.ParseBool(skipVerifyStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value: %w", envLDAPSkipTLSVerify, err)
+		}
+		checker.tlsConfig.InsecureSkipVerify = skipVerify
+	}
+
+	return &checker, nil
+}
+
+// Check binds to the directory and, if configured, searches for the base object.
+func (c *LDAPChecker) Check(ctx context.Context) error {
+	// The password is read on every check, so rotated secrets are picked up
+	var password string
+	if c.PasswordFile != "" {
+		var err error
+		if password, err = readCredentialFile(c.PasswordFile); err != nil {
+			return err
+		}
+	}
+
+	conn, err := c.dialer.DialContext(ctx, "tcp", c.Address)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// The TLS handshake and the bind must complete within the timeout
+	stop, err := deadlineConn(ctx, conn, c.timeout)
+	if err != nil {
+		return err
+	}
+	defer stop()
+
+	if c.ImplicitTLS {
+		tlsConn := tls.Client(conn, c.tlsConfig)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			return fmt.Errorf("TLS handshake failed: %w", err)
+		}
+		conn = tlsConn
+	}
+
+	br := bufio.NewReader(conn)
+
+	// BindRequest ::= [APPLICATION 0] SEQUENCE { version, name, authentication }
+	bind := berInteger(berTagInteger, ldapVersion)
+	bind = append(bind, berTLV(berTagOctetString, []byte(c.BindDN))...)
+	bind = append(bind, berTLV(ldapTagSimpleAuthentication, []byte(password))...)
+	if err := writeLDAPMessage(conn, 1, berTLV(ldapTagBindRequest, bind)); err != nil {
+		return fmt.Errorf("failed to send bind request: %w", err)
+	}
+
+	tag, content, err := readLDAPMessage(br, 1)
+	if err != nil {
+		return fmt.Errorf("failed to read bind response: %w", err)
+	}
+	if tag != ldapTagBindResponse {
+		return fmt.Errorf("unexpected response to bind request: tag 0x%02x", tag)
+	}
+	if err := parseLDAPResult(content); err != nil {
+		return fmt.Errorf("bind failed: %w", err)
+	}
+
+	if c.BaseDN != "" {
+		if err := c.searchBaseObject(conn, br); err != nil {
+			return err
+		}
+	}
+
+	// UnbindRequest ::= [APPLICATION 2] NULL
+	_ = writeLDAPMessage(conn, 3, berTLV(ldapTagUnbindRequest, nil))
+
+	return nil
+}
+
+// searchBaseObject searches for the base DN with scope baseObject and requires the search to succeed.
+func (c *LDAPChecker) searchBaseObject(conn net.Conn, br *bufio.Reader) error {
+	// SearchRequest ::= [APPLICATION 3] SEQUENCE { baseObject, scope, derefAliases, sizeLimit, timeLimit, typesOnly, filter, attributes }
+	search := berTLV(berTagOctetString, []byte(c.BaseDN))
+	search = append(search, berInteger(berTagEnumerated, 0)...) // scope: baseObject
+	search = append(search, berInteger(berTagEnumerated, 0)...) // derefAliases: neverDerefAliases
+	search = append(search, berInteger(berTagInteger, 1)...)    // sizeLimit
+	search = append(search, berInteger(berTagInteger, 0)...)    // timeLimit
+	search = append(search, berTLV(berTagBoolean, []byte{0})...)
+	search = append(search, berTLV(ldapTagPresentFilter, []byte("objectClass"))...)
+	search = append(search, berTLV(berTagSequence, berTLV(berTagOctetString, []byte("1.1")))...) // no attributes
+	if err := writeLDAPMessage(conn, 2, berTLV(ldapTagSearchRequest, search)); err != nil {
+		return fmt.Errorf("failed to send search request: %w", err)
+	}
+
+	for {
+		tag, content, err := readLDAPMessage(br, 2)
+		if err != nil {
+			return fmt.Errorf("failed to read search response: %w", err)
+		}
+
+		switch tag {
+		case ldapTagSearchResultEntry, ldapTagSearchResultRef:
+			continue
+		case ldapTagSearchResultDone:
+			if err := parseLDAPResult(content); err != nil {
+				return fmt.Errorf("search for %q failed: %w", c.BaseDN, err)
+			}
+			return nil
+		default:
+			return fmt.Errorf("unexpected response to search request: tag 0x%02x", tag)
+		}
+	}
+}
+
+// writeLDAPMessage wraps the protocol operation into an LDAPMessage and writes it.
+func writeLDAPMessage(w io.Writer, messageID int, op []byte) error {
+	msg := append(berInteger(berTagInteger, messageID), op...)
+	_, err := w.Write(berTLV(berTagSequence, msg))
+	return err
+}
+
+// readLDAPMessage reads an LDAPMessage and returns the tag and content of its protocol operation.
+// A notice of disconnection sent by the server is reported as an error.
+func readLDAPMessage(r *bufio.Reader, messageID int) (byte, []byte, error) {
+	tag, msg, err := readBER(r)
+	if err != nil {
+		return 0, nil, err
+	}
+	if tag != berTagSequence {
+		return 0, nil, fmt.Errorf("unexpected message tag 0x%02x", tag)
+	}
+
+	idTag, id, msg, err := splitBER(msg)
+	if err != nil || idTag != berTagInteger {
+		return 0, nil, fmt.Errorf("invalid message ID")
+	}
+	opTag, op, _, err := splitBER(msg)
+	if err != nil {
+		return 0, nil, fmt.Errorf("invalid protocol operation: %w", err)
+	}
+
+	if id := berInt(id); id != messageID {
+		if id == 0 && opTag == ldapTagExtendedResponse {
+			return 0, nil, fmt.Errorf("server disconnected: %w", parseLDAPResult(op))
+		}
+		return 0, nil, fmt.Errorf("response to message %d, expected %d", id, messageID)
+	}
+
+	return opTag, op, nil
+}
+
+// parseLDAPResult parses the LDAPResult at the start of a response and returns an error unless the result code is success.
+func parseLDAPResult(content []byte) error {
+	tag, code, rest, err := splitBER(content)
+	if err != nil || tag != berTagEnumerated {
+		return fmt.Errorf("invalid result code")
+	}
+	resultCode := berInt(code)
+	if resultCode == 0 {
+		return nil
+	}
+
+	// The matched DN is followed by the diagnostic message
+	var diagnostic []byte
+	if _, _, rest, err = splitBER(rest); err == nil {
+		_, diagnostic, _, _ = splitBER(rest)
+	}
+
+	name := ldapResultCodes[resultCode]
+	if name == "" {
+		name = "unknown"
+	}
+	if len(diagnostic) > 0 {
+		return fmt.Errorf("result code %d (%s): %s", resultCode, name, diagnostic)
+	}
+	return fmt.Errorf("result code %d (%s)", resultCode, name)
+}
+
+// berTLV encodes a BER element with the given tag and content.
+func berTLV(tag byte, content []byte) []byte {
+	out := []byte{tag}
+
+	switch n := len(content); {
+	case n < 0x80:
+		out = append(out, byte(n))
+	case n <= 0xff:
+		out = append(out, 0x81, byte(n))
+	case n <= 0xffff:
+		out = append(out, 0x82, byte(n>>8), byte(n))
+	default:
+		out = append(out, 0x84, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	}
+
+	return append(out, content...)
+}
+
+// berInteger encodes a non-negative integer with the given tag (INTEGER or ENUMERATED).
+func berInteger(tag byte, v int) []byte {
+	content := []byte{byte(v)}
+	for v >>= 8; v > 0; v >>= 8 {
+		content = append([]byte{byte(v)}, content...)
+	}
+	if content[0]&0x80 != 0 {
+		content = append([]byte{0}, content...) // Keep the value positive
+	}
+
+	return berTLV(tag, content)
+}
+
+// berInt decodes the content of an INTEGER or ENUMERATED element.
+func berInt(content []byte) int {
+	v := 0
+	if len(content) > 0 && content[0]&0x80 != 0 {
+		v = -1
+	}
+	for _, b := range content {
+		v = v<<8 | int(b)
+	}
+	return v
+}
+
+// readBER reads a single BER element from the reader.
+func readBER(r *bufio.Reader) (byte, []byte, error) {
+	tag, err := r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	first, err := r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	// The length is decoded as uint64, so 4 length octets cannot overflow an int on 32-bit platforms
+	length := uint64(first)
+	if first&0x80 != 0 {
+		octets := int(first & 0x7f)
+		if octets == 0 || octets > 4 {
+			return 0, nil, fmt.Errorf("unsupported length encoding 0x%02x", first)
+		}
+		length = 0
+		for range octets {
+			b, err := r.ReadByte()
+			if err != nil {
+				return 0, nil, err
+			}
+			length = length<<8 | uint64(b)
+		}
+	}
+
+	if length > uint64(ldapMaxMessageSize) {
+		return 0, nil, fmt.Errorf("message exceeds %d bytes", ldapMaxMessageSize)
+	}
+
+	content := make([]byte, length)
+	if _, err := io.ReadFull(r, content); err != nil {
+		return 0, nil, err
+	}
+
+	return tag, content, nil
+}
+
+// splitBER splits the first BER element from the data and returns its tag, its content and the remaining data.
+func splitBER(data []byte) (byte, []byte, []byte, error) {
+	if len(data) < 2 {
+		return 0, nil, nil, io.ErrUnexpectedEOF
+	}
+
+	tag, length, offset := data[0], uint64(data[1]), 2
+	if data[1]&0x80 != 0 {
+		octets := int(data[1] & 0x7f)
+		if octets == 0 || octets > 4 || len(data) < offset+octets {
+			return 0, nil, nil, io.ErrUnexpectedEOF
+		}
+		length = 0
+		for _, b := range data[offset : offset+octets] {
+			length = length<<8 | uint64(b)
+		}
+		offset += octets
+	}
+
+	// Compare before converting, so a corrupt length cannot overflow an int on 32-bit platforms
+	if length > uint64(len(data)-offset) {
+		return 0, nil, nil, io.ErrUnexpectedEOF
+	}
+	end := offset + int(length)
+
+	return tag, data[offset:end], data[end:], nil
+}
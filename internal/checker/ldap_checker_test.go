@@ -0,0 +1,385 @@
+package checker
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+)
+
+// fakeLDAPServer is a minimal LDAP server answering bind and base-object search requests.
+type fakeLDAPServer struct {
+	bindDN   string // The DN accepted for simple binds. Anonymous binds are always accepted.
+	password string // The password accepted for simple binds.
+	baseDN   string // The only existing entry.
+	shutdown bool   // Whether to send a notice of disconnection instead of answering.
+}
+
+// berLongTLV encodes a BER element using the long form length with four octets, as Active Directory does.
+func berLongTLV(tag byte, content []byte) []byte {
+	n := len(content)
+	return append([]byte{tag, 0x84, byte(n >> 24), byte(n >> 16), byte(n >> 8), byte(n)}, content...)
+}
+
+// ldapResult encodes an LDAPResult with the given tag.
+func ldapResult(tag byte, code int, diagnostic string) []byte {
+	result := berInteger(berTagEnumerated, code)
+	result = append(result, berTLV(berTagOctetString, nil)...)
+	result = append(result, berTLV(berTagOctetString, []byte(diagnostic))...)
+	return berLongTLV(tag, result)
+}
+
+func (s *fakeLDAPServer) reply(conn net.Conn, messageID int, op []byte) {
+	_, _ = conn.Write(berLongTLV(berTagSequence, append(berInteger(berTagInteger, messageID), op...)))
+}
+
+func (s *fakeLDAPServer) serve(conn net.Conn) {
+	defer conn.Close()
+	br := bufio.NewReader(conn)
+
+	for {
+		_, msg, err := readBER(br)
+		if err != nil {
+			return
+		}
+		_, id, msg, _ := splitBER(msg)
+		tag, op, _, _ := splitBER(msg)
+		messageID := berInt(id)
+
+		if s.shutdown {
+			s.reply(conn, 0, ldapResult(ldapTagExtendedResponse, 52, "server is shutting down"))
+			return
+		}
+
+		switch tag {
+		case ldapTagBindRequest:
+			_, _, rest, _ := splitBER(op) // version
+			_, dn, rest, _ := splitBER(rest)
+			_, password, _, _ := splitBER(rest)
+
+			if len(dn) == 0 || string(dn) == s.bindDN && string(password) == s.password {
+				s.reply(conn, messageID, ldapResult(ldapTagBindResponse, 0, ""))
+			} else {
+				s.reply(conn, messageID, ldapResult(ldapTagBindResponse, 49, "80090308: LdapErr: DSID-0C09044E, comment: AcceptSecurityContext error"))
+			}
+		case ldapTagSearchRequest:
+			_, baseDN, _, _ := splitBER(op)
+
+			if string(baseDN) != s.baseDN {
+				s.reply(conn, messageID, ldapResult(ldapTagSearchResultDone, 32, ""))
+				continue
+			}
+			entry := berTLV(berTagOctetString, baseDN)
+			entry = append(entry, berTLV(berTagSequence, nil)...)
+			s.reply(conn, messageID, berTLV(ldapTagSearchResultEntry, entry))
+			s.reply(conn, messageID, ldapResult(ldapTagSearchResultDone, 0, ""))
+		case ldapTagUnbindRequest:
+			return
+		}
+	}
+}
+
+// startFakeLDAPServer starts the given fake LDAP server on a random port.
+func startFakeLDAPServer(t *testing.T, server *fakeLDAPServer) net.Listener {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to start fake LDAP server: %q", err)
+	}
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn)
+		}
+	}()
+
+	return ln
+}
+
+func TestNewLDAPChecker(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Valid LDAP checker config", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envLDAPBindDN:       "cn=portpatrol,dc=example,dc=com",
+				envLDAPPasswordFile: "/var/run/secrets/ldap/password",
+				envLDAPBaseDN:       "dc=example,dc=com",
+			}
+			return env[key]
+		}
+
+		checker, err := NewLDAPChecker("example", "ldaps://ldap.example.com:636", 1*time.Second, mockEnv)
+		if err != nil {
+			t.Fatalf("failed to create LDAPChecker: %q", err)
+		}
+
+		ldapChecker := checker.(*LDAPChecker)
+
+		expected := "ldap.example.com:636"
+		if ldapChecker.Address != expected {
+			t.Errorf("expected Address to be %q, got %q", expected, ldapChecker.Address)
+		}
+
+		if !ldapChecker.ImplicitTLS {
+			t.Error("expected ImplicitTLS to be true")
+		}
+
+		if ldapChecker.BaseDN != "dc=example,dc=com" {
+			t.Errorf("expected BaseDN to be %q, got %q", "dc=example,dc=com", ldapChecker.BaseDN)
+		}
+	})
+
+	t.Run("Password without bind DN", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envLDAPPasswordFile: "/var/run/secrets/ldap/password",
+			}
+			return env[key]
+		}
+
+		_, err := NewLDAPChecker("example", "ldap.example.com:389", 1*time.Second, mockEnv)
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := fmt.Sprintf("%s requires %s to be set", envLDAPPasswordFile, envLDAPBindDN)
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Bind DN without password", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envLDAPBindDN: "cn=portpatrol,dc=example,dc=com",
+			}
+			return env[key]
+		}
+
+		_, err := NewLDAPChecker("example", "ldap.example.com:389", 1*time.Second, mockEnv)
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := fmt.Sprintf("%s requires %s to be set", envLDAPBindDN, envLDAPPasswordFile)
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Invalid LDAP_SKIP_TLS_VERIFY", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envLDAPSkipTLSVerify: "invalid",
+			}
+			return env[key]
+		}
+
+		_, err := NewLDAPChecker("example", "ldap.example.com:389", 1*time.Second, mockEnv)
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := fmt.Sprintf("invalid %s value: strconv.ParseBool: parsing \"invalid\": invalid syntax", envLDAPSkipTLSVerify)
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+}
+
+func TestLDAPChecker(t *testing.T) {
+	t.Parallel()
+
+	server := &fakeLDAPServer{
+		bindDN:   "cn=portpatrol,dc=example,dc=com",
+		password: "s3cr3t",
+		baseDN:   "dc=example,dc=com",
+	}
+	ln := startFakeLDAPServer(t, server)
+	t.Cleanup(func() { ln.Close() })
+
+	passwordFile := writeCredential(t, "password", "s3cr3t")
+	wrongPasswordFile := writeCredential(t, "wrong-password", "wrong")
+
+	tests := []struct {
+		name     string
+		env      map[string]string
+		expected string
+	}{
+		{
+			name: "Anonymous bind",
+			env:  map[string]string{},
+		},
+		{
+			name: "Simple bind with search",
+			env: map[string]string{
+				envLDAPBindDN:       "cn=portpatrol,dc=example,dc=com",
+				envLDAPPasswordFile: passwordFile,
+				envLDAPBaseDN:       "dc=example,dc=com",
+			},
+		},
+		{
+			name: "Invalid credentials",
+			env: map[string]string{
+				envLDAPBindDN:       "cn=portpatrol,dc=example,dc=com",
+				envLDAPPasswordFile: wrongPasswordFile,
+			},
+			expected: "bind failed: result code 49 (invalidCredentials): 80090308: LdapErr: DSID-0C09044E, comment: AcceptSecurityContext error",
+		},
+		{
+			name: "Base object not found",
+			env: map[string]string{
+				envLDAPBaseDN: "dc=missing,dc=com",
+			},
+			expected: `search for "dc=missing,dc=com" failed: result code 32 (noSuchObject)`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			checker, err := NewLDAPChecker("example", "ldap://"+ln.Addr().String(), 1*time.Second, func(key string) string { return tt.env[key] })
+			if err != nil {
+				t.Fatalf("failed to create LDAPChecker: %q", err)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+
+			err = checker.Check(ctx)
+			if tt.expected == "" {
+				if err != nil {
+					t.Fatalf("expected no error, got %q", err)
+				}
+				return
+			}
+
+			if err == nil {
+				t.Fatal("expected an error, got none")
+			}
+			if err.Error() != tt.expected {
+				t.Errorf("expected error %q, got %q", tt.expected, err.Error())
+			}
+		})
+	}
+
+	t.Run("Notice of disconnection", func(t *testing.T) {
+		t.Parallel()
+
+		ln := startFakeLDAPServer(t, &fakeLDAPServer{shutdown: true})
+		defer ln.Close()
+
+		checker, err := NewLDAPChecker("example", ln.Addr().String(), 1*time.Second, func(string) string { return "" })
+		if err != nil {
+			t.Fatalf("failed to create LDAPChecker: %q", err)
+		}
+
+		err = checker.Check(context.Background())
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := "failed to read bind response: server disconnected: result code 52 (unavailable): server is shutting down"
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Missing password file", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envLDAPBindDN:       "cn=portpatrol,dc=example,dc=com",
+				envLDAPPasswordFile: "/nonexistent/password",
+			}
+			return env[key]
+		}
+
+		checker, err := NewLDAPChecker("example", ln.Addr().String(), 1*time.Second, mockEnv)
+		if err != nil {
+			t.Fatalf("failed to create LDAPChecker: %q", err)
+		}
+
+		err = checker.Check(context.Background())
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := "failed to read credential file: open /nonexistent/password: no such file or directory"
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+}
+
+func TestBER(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Integer round trip", func(t *testing.T) {
+		t.Parallel()
+
+		for _, v := range []int{0, 1, 127, 128, 255, 256, 65535, 1 << 20} {
+			_, content, rest, err := splitBER(berInteger(berTagInteger, v))
+			if err != nil {
+				t.Fatalf("expected no error, got %q", err)
+			}
+			if got := berInt(content); got != v || len(rest) != 0 {
+				t.Errorf("expected %d, got %d", v, got)
+			}
+		}
+	})
+
+	t.Run("Long form length", func(t *testing.T) {
+		t.Parallel()
+
+		content := make([]byte, 300)
+		tag, got, rest, err := splitBER(append(berTLV(berTagOctetString, content), 0xff))
+		if err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+		if tag != berTagOctetString || len(got) != 300 || len(rest) != 1 {
+			t.Errorf("unexpected element: tag 0x%02x, %d bytes, %d remaining", tag, len(got), len(rest))
+		}
+	})
+
+	t.Run("Truncated element", func(t *testing.T) {
+		t.Parallel()
+
+		data := berTLV(berTagOctetString, []byte("portpatrol"))
+		if _, _, _, err := splitBER(data[:len(data)-1]); err == nil {
+			t.Fatal("expected an error, got none")
+		}
+	})
+
+	t.Run("Corrupt length", func(t *testing.T) {
+		t.Parallel()
+
+		// A length with the highest bit set would be negative as a 32-bit int
+		data := []byte{berTagOctetString, 0x84, 0xff, 0xff, 0xff, 0xf0, 'p', 'p'}
+		if _, _, _, err := splitBER(data); err == nil {
+			t.Fatal("expected an error, got none")
+		}
+		if _, _, err := readBER(bufio.NewReader(bytes.NewReader(data))); err == nil {
+			t.Fatal("expected an error, got none")
+		}
+	})
+}
@@ -0,0 +1,343 @@
+package checker
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	envMongoDBRequirePrimary string = "MONGODB_REQUIRE_PRIMARY"
+
+	defaultMongoDBRequirePrimary bool   = false
+	defaultMongoDBPort           string = "27017"
+
+	mongoDBOpMsg          int32 = 2013
+	mongoDBMaxMessageSize int32 = 48 * 1000 * 1000 // The maximum message size accepted by MongoDB.
+	mongoDBHeaderSize     int   = 16
+)
+
+// MongoDBChecker implements the Checker interface for MongoDB checks using the hello command.
+type MongoDBChecker struct {
+	Name           string        // The name of the checker.
+	Address        string        // The address of the target.
+	RequirePrimary bool          // Whether a primary must be elected in the replica set.
+	dialer         contextDialer // The dialer to use for the connection.
+	timeout        time.Duration // The timeout for the whole exchange with the server.
+}
+
+// String returns the name of the checker.
+func (c *MongoDBChecker) String() string {
+	return c.Name
+}
+
+// NewMongoDBChecker creates a new MongoDBChecker.
+func NewMongoDBChecker(name, address string, timeout time.Duration, getEnv func(string) string) (Checker, error) {
+	// The "mongodb://" prefix is used to identify the check type. Like any connection string, the address may also
+	// contain credentials, a database and options, which are not needed, and a seed list, of which the first host is checked.
+	if strings.HasPrefix(address, "mongodb://") {
+		u, err := url.Parse(address)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address: %w", err)
+		}
+		seed, _, _ := strings.Cut(u.Host, ",")
+
+		// Like in the drivers, hosts in connection strings default to the standard port
+		address = seed
+		if seedURL := (&url.URL{Host: seed}); seedURL.Port() == "" {
+			address = net.JoinHostPort(seedURL.Hostname(), defaultMongoDBPort)
+		}
+	}
+
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		return nil, fmt.Errorf("invalid address %s: %w", address, err)
+	}
+
+	// Connections originate from the configured source address and interface and go through the configured proxy
+	dialer, err := newProxyDialer(timeout, getEnv)
+	if err != nil {
+		return nil, err
+	}
+
+	checker := MongoDBChecker{
+		Name:           name,
+		Address:        address,
+		RequirePrimary: defaultMongoDBRequirePrimary,
+		dialer:         dialer,
+		timeout:        timeout,
+	}
+
+	// Determine if a primary must be elected
+	if requirePrimaryStr := getEnv(envMongoDBRequirePrimary); requirePrimaryStr != "" {
+		requirePrimary, err := strconv.ParseBool(requirePrimaryStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value: %w", envMongoDBRequirePrimary, err)
+		}
+		checker.RequirePrimary = requirePrimary
+	}
+
+	return &checker, nil
+}
+
+// Check sends the hello command and evaluates the state of the member.
+func (c *MongoDBChecker) Check(ctx context.Context) error {
+	conn, err := c.dialer.DialContext(ctx, "tcp", c.Address)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// The server must answer the hello command within the timeout
+	stop, err := deadlineConn(ctx, conn, c.timeout)
+	if err != nil {
+		return err
+	}
+	defer stop()
+
+	reply, err := runMongoDBCommand(conn, 1, "hello")
+	if err != nil {
+		return fmt.Errorf("hello command failed: %w", err)
+	}
+
+	// Servers older than MongoDB 4.4.2 do not know the hello command and need the legacy isMaster command
+	if !bsonOK(reply) && strings.Contains(bsonString(reply, "errmsg"), "no such") {
+		reply, err = runMongoDBCommand(conn, 2, "isMaster")
+		if err != nil {
+			return fmt.Errorf("isMaster command failed: %w", err)
+		}
+		reply["isWritablePrimary"] = reply["ismaster"]
+	}
+
+	return evaluateMongoDBHello(reply, c.RequirePrimary)
+}
+
+// runMongoDBCommand sends the given command with value 1 against the admin database and returns the top-level fields of the reply.
+func runMongoDBCommand(conn net.Conn, requestID int32, command string) (map[string]any, error) {
+	doc := &bsonWriter{}
+	doc.int32(command, 1)
+	doc.string("$db", "admin")
+
+	body := binary.LittleEndian.AppendUint32(nil, 0) // flagBits
+	body = append(body, 0)                           // section kind 0: body
+	body = append(body, doc.bytes()...)
+
+	msg := binary.LittleEndian.AppendUint32(nil, uint32(mongoDBHeaderSize+len(body)))
+	msg = binary.LittleEndian.AppendUint32(msg, uint32(requestID))
+	msg = binary.LittleEndian.AppendUint32(msg, 0) // responseTo
+	msg = binary.LittleEndian.AppendUint32(msg, uint32(mongoDBOpMsg))
+	msg = append(msg, body...)
+
+	if _, err := conn.Write(msg); err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, mongoDBHeaderSize)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return nil, err
+	}
+
+	size := int32(binary.LittleEndian.Uint32(header[0:4]))
+	responseTo := int32(binary.LittleEndian.Uint32(header[8:12]))
+	opCode := int32(binary.LittleEndian.Uint32(header[12:16]))
+
+	if size < int32(mongoDBHeaderSize)+5 || size > mongoDBMaxMessageSize {
+		return nil, fmt.Errorf("invalid message size: %d", size)
+	}
+
+	payload := make([]byte, int(size)-mongoDBHeaderSize)
+	if _, err := io.ReadFull(conn, payload); err != nil {
+		return nil, err
+	}
+
+	if opCode != mongoDBOpMsg {
+		return nil, fmt.Errorf("unexpected opcode %d", opCode)
+	}
+	if responseTo != requestID {
+		return nil, fmt.Errorf("response to request %d, expected %d", responseTo, requestID)
+	}
+	if payload[4] != 0 {
+		return nil, fmt.Errorf("unexpected section kind %d", payload[4])
+	}
+
+	return parseBSONDocument(payload[5:])
+}
+
+// evaluateMongoDBHello checks the hello reply. A member is ready if it is a writable primary (standalone servers and mongos
+// report themselves as such) or a secondary. If requirePrimary is set, a primary must also be known to the member.
+func evaluateMongoDBHello(reply map[string]any, requirePrimary bool) error {
+	if !bsonOK(reply) {
+		return fmt.Errorf("command failed: %s", bsonString(reply, "errmsg"))
+	}
+
+	isWritablePrimary, _ := reply["isWritablePrimary"].(bool)
+	secondary, _ := reply["secondary"].(bool)
+
+	if !isWritablePrimary && !secondary {
+		if setName := bsonString(reply, "setName"); setName != "" {
+			return fmt.Errorf("member of replica set %q is neither primary nor secondary", setName)
+		}
+		return fmt.Errorf("server is neither primary nor secondary")
+	}
+
+	if requirePrimary && !isWritablePrimary && bsonString(reply, "primary") == "" {
+		return fmt.Errorf("no primary elected in replica set %q", bsonString(reply, "setName"))
+	}
+
+	return nil
+}
+
+// bsonOK reports whether the "ok" field of a command reply is set to 1.
+func bsonOK(doc map[string]any) bool {
+	switch ok := doc["ok"].(type) {
+	case float64:
+		return ok == 1
+	case int32:
+		return ok == 1
+	case int64:
+		return ok == 1
+	default:
+		return false
+	}
+}
+
+// bsonString returns the string field with the given name, or an empty string.
+func bsonString(doc map[string]any, name string) string {
+	s, _ := doc[name].(string)
+	return s
+}
+
+// bsonWriter encodes a flat BSON document.
+type bsonWriter struct {
+	buf []byte
+}
+
+func (w *bsonWriter) element(kind byte, name string) {
+	w.buf = append(w.buf, kind)
+	w.buf = append(w.buf, name...)
+	w.buf = append(w.buf, 0)
+}
+
+func (w *bsonWriter) int32(name string, v int32) {
+	w.element(0x10, name)
+	w.buf = binary.LittleEndian.AppendUint32(w.buf, uint32(v))
+}
+
+func (w *bsonWriter) string(name, v string) {
+	w.element(0x02, name)
+	w.buf = binary.LittleEndian.AppendUint32(w.buf, uint32(len(v)+1))
+	w.buf = append(w.buf, v...)
+	w.buf = append(w.buf, 0)
+}
+
+// bytes returns the encoded document including its length prefix and terminator.
+func (w *bsonWriter) bytes() []byte {
+	doc := binary.LittleEndian.AppendUint32(nil, uint32(4+len(w.buf)+1))
+	doc = append(doc, w.buf...)
+	return append(doc, 0)
+}
+
+// parseBSONDocument decodes the top-level fields of a BSON document. Doubles, strings, booleans, int32 and int64
+// values are returned as Go values; all other values (e.g. embedded documents) are skipped and set to nil.
+func parseBSONDocument(data []byte) (map[string]any, error) {
+	if len(data) < 5 {
+		return nil, fmt.Errorf("BSON document too short")
+	}
+	size := int(binary.LittleEndian.Uint32(data[0:4]))
+	if size < 5 || size > len(data) || data[size-1] != 0 {
+		return nil, fmt.Errorf("invalid BSON document size: %d", size)
+	}
+
+	doc := make(map[string]any)
+	buf := data[4 : size-1]
+
+	for len(buf) > 0 {
+		kind := buf[0]
+		end := 1
+		for end < len(buf) && buf[end] != 0 {
+			end++
+		}
+		if end == len(buf) {
+			return nil, fmt.Errorf("unterminated BSON element name")
+		}
+		name := string(buf[1:end])
+		buf = buf[end+1:]
+
+		n, value, err := parseBSONValue(kind, buf)
+		if err != nil {
+			return nil, fmt.Errorf("invalid BSON element %q: %w", name, err)
+		}
+		doc[name] = value
+		buf = buf[n:]
+	}
+
+	return doc, nil
+}
+
+// parseBSONValue decodes a single value of the given type and returns the number of bytes consumed.
+func parseBSONValue(kind byte, buf []byte) (int, any, error) {
+	need := func(n int) error {
+		if n < 0 || len(buf) < n {
+			return io.ErrUnexpectedEOF
+		}
+		return nil
+	}
+	lengthPrefixed := func(extra int) (int, error) {
+		if err := need(4); err != nil {
+			return 0, err
+		}
+		n := int(binary.LittleEndian.Uint32(buf[0:4])) + extra
+		return n, need(n)
+	}
+
+	switch kind {
+	case 0x01: // double
+		if err := need(8); err != nil {
+			return 0, nil, err
+		}
+		return 8, math.Float64frombits(binary.LittleEndian.Uint64(buf)), nil
+	case 0x02: // string
+		n, err := lengthPrefixed(4)
+		if err != nil || n < 5 {
+			return 0, nil, io.ErrUnexpectedEOF
+		}
+		return n, string(buf[4 : n-1]), nil
+	case 0x03, 0x04: // embedded document, array
+		n, err := lengthPrefixed(0)
+		return n, nil, err
+	case 0x05: // binary
+		n, err := lengthPrefixed(5)
+		return n, nil, err
+	case 0x07: // ObjectId
+		return 12, nil, need(12)
+	case 0x08: // boolean
+		if err := need(1); err != nil {
+			return 0, nil, err
+		}
+		return 1, buf[0] != 0, nil
+	case 0x09, 0x11: // UTC datetime, timestamp
+		return 8, nil, need(8)
+	case 0x0a, 0x7f, 0xff: // null, max key, min key
+		return 0, nil, nil
+	case 0x10: // int32
+		if err := need(4); err != nil {
+			return 0, nil, err
+		}
+		return 4, int32(binary.LittleEndian.Uint32(buf)), nil
+	case 0x12: // int64
+		if err := need(8); err != nil {
+			return 0, nil, err
+		}
+		return 8, int64(binary.LittleEndian.Uint64(buf)), nil
+	case 0x13: // decimal128
+		return 16, nil, need(16)
+	default:
+		return 0, nil, fmt.Errorf("unsupported BSON type 0x%02x", kind)
+	}
+}
@@ -0,0 +1,389 @@
+package checker
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	envNATSTokenFile     string = "NATS_TOKEN_FILE"
+	envNATSUsernameFile  string = "NATS_USERNAME_FILE"
+	envNATSPasswordFile  string = "NATS_PASSWORD_FILE"
+	envNATSTLS           string = "NATS_TLS"
+	envNATSCAFile        string = "NATS_CA_FILE"
+	envNATSSkipTLSVerify string = "NATS_SKIP_TLS_VERIFY"
+	envNATSJetStream     string = "NATS_JETSTREAM"
+
+	defaultNATSTLS           bool = false
+	defaultNATSSkipTLSVerify bool = false
+	defaultNATSJetStream     bool = false
+
+	natsClientName     string = "portpatrol"
+	natsMaxLineSize    int    = 64 * 1024 // The maximum size of a protocol line, e.g. INFO.
+	natsMaxPayloadSize int    = 64 * 1024 // The maximum size of a message payload read from the server.
+	natsJSAPIInfo      string = "$JS.API.INFO"
+)
+
+// natsInfo holds the fields of the INFO message which are needed for the check.
+type natsInfo struct {
+	TLSRequired  bool `json:"tls_required"`
+	TLSAvailable bool `json:"tls_available"`
+	Headers      bool `json:"headers"`
+	JetStream    bool `json:"jetstream"`
+}
+
+// natsConnect is the payload of the CONNECT message.
+type natsConnect struct {
+	Verbose      bool   `json:"verbose"`
+	Pedantic     bool   `json:"pedantic"`
+	TLSRequired  bool   `json:"tls_required"`
+	Name         string `json:"name"`
+	Lang         string `json:"lang"`
+	Protocol     int    `json:"protocol"`
+	Headers      bool   `json:"headers"`
+	NoResponders bool   `json:"no_responders"`
+	AuthToken    string `json:"auth_token,omitempty"`
+	User         string `json:"user,omitempty"`
+	Pass         string `json:"pass,omitempty"`
+}
+
+// NATSChecker implements the Checker interface for NATS checks.
+type NATSChecker struct {
+	Name         string        // The name of the checker.
+	Address      string        // The address of the target.
+	TokenFile    string        // The file containing the authentication token. If empty, no token is sent.
+	UsernameFile string        // The file containing the username. If empty, no username is sent.
+	PasswordFile string        // The file containing the password. If empty, no password is sent.
+	TLS          bool          // Whether the connection must be upgraded to TLS, even if the server does not require it.
+	JetStream    bool          // Whether JetStream must be available.
+	tlsConfig    *tls.Config   // The TLS configuration used if the server requires TLS or TLS is enabled.
+	dialer       contextDialer // The dialer to use for the connection.
+	timeout      time.Duration // The timeout for the whole exchange with the server.
+}
+
+// String returns the name of the checker.
+func (c *NATSChecker) String() string {
+	return c.Name
+}
+
+// NewNATSChecker creates a new NATSChecker.
+func NewNATSChecker(name, address string, timeout time.Duration, getEnv func(string) string) (Checker, error) {
+	// The "nats://" prefix is used to identify the check type and is not needed for further processing,
+	// so it must be removed before passing the address to other functions.
+	address = strings.TrimPrefix(address, "nats://")
+
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address %s: %w", address, err)
+	}
+
+	// Connections originate from the configured source address and interface and go through the configured proxy
+	dialer, err := newProxyDialer(timeout, getEnv)
+	if err != nil {
+		return nil, err
+	}
+
+	checker := NATSChecker{
+		Name:         name,
+		Address:      address,
+		TokenFile:    getEnv(envNATSTokenFile),
+		UsernameFile: getEnv(envNATSUsernameFile),
+		PasswordFile: getEnv(envNATSPasswordFile),
+		TLS:          defaultNATSTLS,
+		JetStream:    defaultNATSJetStream,
+		tlsConfig: &tls.Config{
+			ServerName:         host,
+			InsecureSkipVerify: defaultNATSSkipTLSVerify,
+		},
+		dialer:  dialer,
+		timeout: timeout,
+	}
+
+	if checker.TokenFile != "" && checker.UsernameFile != "" {
+		return nil, fmt.Errorf("%s and %s are mutually exclusive", envNATSTokenFile, envNATSUsernameFile)
+	}
+	if checker.PasswordFile != "" && checker.UsernameFile == "" {
+		return nil, fmt.Errorf("%s requires %s to be set", envNATSPasswordFile, envNATSUsernameFile)
+	}
+
+	// Determine if the connection must be upgraded to TLS
+	if tlsStr := getEnv(envNATSTLS); tlsStr != "" {
+		useTLS, err := strconv.ParseBool(tlsStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value: %w", envNATSTLS, err)
+		}
+		checker.TLS = useTLS
+	}
+
+	// Load the CA certificates if specified
+	if caFile := getEnv(envNATSCAFile); caFile != "" {
+		pool, err := loadCertPool(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value: %w", envNATSCAFile, err)
+		}
+		checker.tlsConfig.RootCAs = pool
+	}
+
+	// Determine if TLS verification should be skipped
+	if skipVerifyStr := getEnv(envNATSSkipTLSVerify); skipVerifyStr != "" {
+		skipVerify, err := strconv.ParseBool(skipVerifyStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value: %w", envNATSSkipTLSVerify, err)
+		}
+		checker.tlsConfig.InsecureSkipVerify = skipVerify
+	}
+
+	// Determine if JetStream must be available
+	if jetStreamStr := getEnv(envNATSJetStream); jetStreamStr != "" {
+		jetStream, err := strconv.ParseBool(jetStreamStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value: %w", envNATSJetStream, err)
+		}
+		checker.JetStream = jetStream
+	}
+
+	return &checker, nil
+}
+
+// Check reads the INFO message, sends CONNECT and PING and expects PONG.
+// If JetStream must be available, the account information is requested via $JS.API.INFO.
+func (c *NATSChecker) Check(ctx context.Context) error {
+	// Read the credentials on every check, so rotated or late-mounted secrets are picked up
+	connect := natsConnect{
+		Name:     natsClientName,
+		Lang:     "go",
+		Protocol: 1,
+	}
+	if c.TokenFile != "" {
+		token, err := readCredentialFile(c.TokenFile)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", envNATSTokenFile, err)
+		}
+		connect.AuthToken = token
+	}
+	if c.UsernameFile != "" {
+		username, err := readCredentialFile(c.UsernameFile)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", envNATSUsernameFile, err)
+		}
+		connect.User = username
+	}
+	if c.PasswordFile != "" {
+		password, err := readCredentialFile(c.PasswordFile)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", envNATSPasswordFile, err)
+		}
+		connect.Pass = password
+	}
+
+	conn, err := c.dialer.DialContext(ctx, "tcp", c.Address)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// The server must send INFO and answer PING within the timeout
+	stop, err := deadlineConn(ctx, conn, c.timeout)
+	if err != nil {
+		return err
+	}
+	defer stop()
+
+	br := bufio.NewReaderSize(conn, natsMaxLineSize)
+
+	// The server always starts with INFO in plain text, even if TLS is required
+	line, err := readNATSLine(br)
+	if err != nil {
+		return fmt.Errorf("failed to read INFO: %w", err)
+	}
+	infoJSON, ok := strings.CutPrefix(line, "INFO ")
+	if !ok {
+		return fmt.Errorf("unexpected greeting: %q", line)
+	}
+	var info natsInfo
+	if err := json.Unmarshal([]byte(infoJSON), &info); err != nil {
+		return fmt.Errorf("failed to decode INFO: %w", err)
+	}
+
+	if c.TLS || info.TLSRequired {
+		if !info.TLSRequired && !info.TLSAvailable {
+			return errors.New("server does not support TLS")
+		}
+		tlsConn := tls.Client(conn, c.tlsConfig)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			return fmt.Errorf("TLS handshake failed: %w", err)
+		}
+		conn = tlsConn
+		br = bufio.NewReaderSize(conn, natsMaxLineSize)
+		connect.TLSRequired = true
+	}
+
+	// Headers are required to receive a status message instead of a timeout if nobody answers a request
+	connect.Headers = info.Headers
+	connect.NoResponders = info.Headers
+
+	connectJSON, err := json.Marshal(connect)
+	if err != nil {
+		return fmt.Errorf("failed to encode CONNECT: %w", err)
+	}
+	if _, err := fmt.Fprintf(conn, "CONNECT %s\r\nPING\r\n", connectJSON); err != nil {
+		return fmt.Errorf("failed to send CONNECT: %w", err)
+	}
+
+	// An authorization failure is reported with -ERR instead of PONG
+	line, err = readNATSOp(conn, br)
+	if err != nil {
+		return fmt.Errorf("CONNECT failed: %w", err)
+	}
+	if line != "PONG" {
+		return fmt.Errorf("unexpected reply to PING: %q", line)
+	}
+
+	if c.JetStream {
+		if err := c.checkJetStream(conn, br, info); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkJetStream requests the JetStream account information and verifies that no error is returned.
+func (c *NATSChecker) checkJetStream(conn io.Writer, br *bufio.Reader, info natsInfo) error {
+	if !info.JetStream {
+		return errors.New("JetStream is not enabled")
+	}
+
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return fmt.Errorf("failed to generate inbox: %w", err)
+	}
+	inbox := "_INBOX." + hex.EncodeToString(id)
+
+	if _, err := fmt.Fprintf(conn, "SUB %s 1\r\nPUB %s %s 0\r\n\r\n", inbox, natsJSAPIInfo, inbox); err != nil {
+		return fmt.Errorf("failed to send %s request: %w", natsJSAPIInfo, err)
+	}
+
+	line, err := readNATSOp(conn, br)
+	if err != nil {
+		return fmt.Errorf("%s request failed: %w", natsJSAPIInfo, err)
+	}
+
+	headers, payload, err := readNATSMessage(br, line)
+	if err != nil {
+		return fmt.Errorf("%s request failed: %w", natsJSAPIInfo, err)
+	}
+
+	// Without a JetStream responder, the server answers with the status "503" in the headers
+	statusLine, _, _ := strings.Cut(headers, "\r\n")
+	if status := strings.TrimSpace(strings.TrimPrefix(statusLine, "NATS/1.0")); status != "" {
+		if strings.HasPrefix(status, "503") {
+			return errors.New("JetStream is not available: no responders")
+		}
+		return fmt.Errorf("%s request failed: status %s", natsJSAPIInfo, status)
+	}
+
+	var response struct {
+		Error *struct {
+			Code        int    `json:"code"`
+			Description string `json:"description"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(payload, &response); err != nil {
+		return fmt.Errorf("failed to decode %s response: %w", natsJSAPIInfo, err)
+	}
+	if response.Error != nil {
+		return fmt.Errorf("JetStream is not available: %s (%d)", response.Error.Description, response.Error.Code)
+	}
+
+	return nil
+}
+
+// readNATSLine reads a protocol line without the trailing CRLF.
+func readNATSLine(br *bufio.Reader) (string, error) {
+	line, err := br.ReadSlice('\n')
+	if errors.Is(err, bufio.ErrBufferFull) {
+		return "", fmt.Errorf("line exceeds %d bytes", natsMaxLineSize)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(string(line), "\r\n"), nil
+}
+
+// readNATSOp reads the next protocol line which is not INFO or +OK, answering PING from the server.
+// An -ERR line is returned as an error.
+func readNATSOp(w io.Writer, br *bufio.Reader) (string, error) {
+	for {
+		line, err := readNATSLine(br)
+		if err != nil {
+			return "", err
+		}
+
+		op, args, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(op) {
+		case "INFO", "+OK":
+			continue
+		case "PING":
+			if _, err := io.WriteString(w, "PONG\r\n"); err != nil {
+				return "", err
+			}
+		case "-ERR":
+			return "", fmt.Errorf("server error: %s", strings.Trim(args, "' "))
+		default:
+			return line, nil
+		}
+	}
+}
+
+// readNATSMessage reads the payload of a MSG or HMSG line, returning the headers (HMSG only) and the body.
+func readNATSMessage(br *bufio.Reader, line string) (string, []byte, error) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return "", nil, fmt.Errorf("unexpected message: %q", line)
+	}
+
+	// MSG <subject> <sid> [reply-to] <#bytes>
+	// HMSG <subject> <sid> [reply-to] <#header bytes> <#total bytes>
+	var headerSize, totalSize int
+	var err error
+	switch strings.ToUpper(fields[0]) {
+	case "MSG":
+		if len(fields) < 4 || len(fields) > 5 {
+			return "", nil, fmt.Errorf("unexpected message: %q", line)
+		}
+		totalSize, err = strconv.Atoi(fields[len(fields)-1])
+	case "HMSG":
+		if len(fields) < 5 || len(fields) > 6 {
+			return "", nil, fmt.Errorf("unexpected message: %q", line)
+		}
+		headerSize, err = strconv.Atoi(fields[len(fields)-2])
+		if err == nil {
+			totalSize, err = strconv.Atoi(fields[len(fields)-1])
+		}
+	default:
+		return "", nil, fmt.Errorf("unexpected message: %q", line)
+	}
+	if err != nil || headerSize < 0 || totalSize < headerSize || totalSize > natsMaxPayloadSize {
+		return "", nil, fmt.Errorf("invalid message size: %q", line)
+	}
+
+	data := make([]byte, totalSize+2) // The payload is followed by CRLF
+	if _, err := io.ReadFull(br, data); err != nil {
+		return "", nil, fmt.Errorf("failed to read message: %w", err)
+	}
+
+	return string(data[:headerSize]), data[headerSize:totalSize], nil
+}
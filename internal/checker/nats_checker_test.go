@@ -0,0 +1,325 @@
+package checker
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeNATSServer is a minimal NATS server supporting CONNECT, PING, SUB and PUB to $JS.API.INFO.
+type fakeNATSServer struct {
+	token     string      // The required authentication token. If empty, no authentication is required.
+	headers   bool        // Whether the server supports headers.
+	jetStream string      // The reply to $JS.API.INFO. If empty, JetStream is disabled and nobody responds.
+	tlsConfig *tls.Config // The TLS configuration. If set, TLS is required.
+}
+
+func (s *fakeNATSServer) serve(conn net.Conn) {
+	defer conn.Close()
+
+	info, _ := json.Marshal(map[string]any{
+		"server_id":     "NCXFAKE",
+		"version":       "2.10.18",
+		"auth_required": s.token != "",
+		"tls_required":  s.tlsConfig != nil,
+		"headers":       s.headers,
+		"jetstream":     s.jetStream != "",
+	})
+	fmt.Fprintf(conn, "INFO %s\r\n", info)
+
+	if s.tlsConfig != nil {
+		conn = tls.Server(conn, s.tlsConfig)
+	}
+
+	r := bufio.NewReader(conn)
+	sid := ""
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch fields[0] {
+		case "CONNECT":
+			var connect natsConnect
+			_ = json.Unmarshal([]byte(strings.TrimPrefix(strings.TrimSpace(line), "CONNECT ")), &connect)
+			if connect.AuthToken != s.token {
+				fmt.Fprint(conn, "-ERR 'Authorization Violation'\r\n")
+				return
+			}
+			// Servers send updated INFO messages at any time
+			fmt.Fprintf(conn, "INFO %s\r\n", info)
+		case "PING":
+			fmt.Fprint(conn, "PONG\r\n")
+		case "SUB":
+			sid = fields[2]
+		case "PUB":
+			_, _ = r.ReadString('\n') // The empty payload
+			inbox := fields[2]
+			switch {
+			case s.jetStream != "":
+				fmt.Fprintf(conn, "MSG %s %s %d\r\n%s\r\n", inbox, sid, len(s.jetStream), s.jetStream)
+			case s.headers:
+				header := "NATS/1.0 503\r\n\r\n"
+				fmt.Fprintf(conn, "HMSG %s %s %d %d\r\n%s\r\n", inbox, sid, len(header), len(header), header)
+			}
+		}
+	}
+}
+
+// startFakeNATSServer starts the given fake NATS server on a random port.
+func startFakeNATSServer(t *testing.T, server *fakeNATSServer) net.Listener {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to start fake NATS server: %q", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn)
+		}
+	}()
+
+	return ln
+}
+
+func TestNewNATSChecker(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Valid NATS checker config", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envNATSUsernameFile: "/secrets/username",
+				envNATSPasswordFile: "/secrets/password",
+				envNATSTLS:          "true",
+				envNATSJetStream:    "true",
+			}
+			return env[key]
+		}
+
+		checker, err := NewNATSChecker("example", "nats://nats.example.com:4222", 1*time.Second, mockEnv)
+		if err != nil {
+			t.Fatalf("failed to create NATSChecker: %q", err)
+		}
+
+		natsChecker := checker.(*NATSChecker)
+
+		expected := "nats.example.com:4222"
+		if natsChecker.Address != expected {
+			t.Errorf("expected Address to be %q, got %q", expected, natsChecker.Address)
+		}
+
+		if !natsChecker.TLS || !natsChecker.JetStream {
+			t.Errorf("expected TLS and JetStream to be true, got TLS=%t JetStream=%t", natsChecker.TLS, natsChecker.JetStream)
+		}
+
+		if natsChecker.tlsConfig.ServerName != "nats.example.com" {
+			t.Errorf("expected ServerName to be %q, got %q", "nats.example.com", natsChecker.tlsConfig.ServerName)
+		}
+	})
+
+	t.Run("Token and username", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envNATSTokenFile:    "/secrets/token",
+				envNATSUsernameFile: "/secrets/username",
+			}
+			return env[key]
+		}
+
+		_, err := NewNATSChecker("example", "nats.example.com:4222", 1*time.Second, mockEnv)
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := fmt.Sprintf("%s and %s are mutually exclusive", envNATSTokenFile, envNATSUsernameFile)
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Invalid NATS_JETSTREAM", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envNATSJetStream: "invalid",
+			}
+			return env[key]
+		}
+
+		_, err := NewNATSChecker("example", "nats.example.com:4222", 1*time.Second, mockEnv)
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := fmt.Sprintf("invalid %s value: strconv.ParseBool: parsing \"invalid\": invalid syntax", envNATSJetStream)
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+}
+
+func TestNATSChecker(t *testing.T) {
+	t.Parallel()
+
+	// The test server provides a certificate valid for example.com and 127.0.0.1
+	certServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	t.Cleanup(certServer.Close)
+
+	caFile := writeServerCA(t, certServer)
+	tokenFile := writeCredential(t, "token", "s3cr3t")
+	wrongTokenFile := writeCredential(t, "wrong-token", "wrong")
+
+	tests := []struct {
+		name     string
+		server   *fakeNATSServer
+		env      map[string]string
+		expected string
+	}{
+		{
+			name:   "Valid NATS check",
+			server: &fakeNATSServer{},
+			env:    map[string]string{},
+		},
+		{
+			name:   "Valid NATS check with token",
+			server: &fakeNATSServer{token: "s3cr3t"},
+			env: map[string]string{
+				envNATSTokenFile: tokenFile,
+			},
+		},
+		{
+			name:   "Valid NATS check with TLS",
+			server: &fakeNATSServer{tlsConfig: &tls.Config{Certificates: certServer.TLS.Certificates}},
+			env: map[string]string{
+				envNATSCAFile: caFile,
+			},
+		},
+		{
+			name:   "Valid NATS check with JetStream",
+			server: &fakeNATSServer{headers: true, jetStream: `{"type":"io.nats.jetstream.api.v1.account_info_response","memory":0,"storage":0,"streams":0}`},
+			env: map[string]string{
+				envNATSJetStream: "true",
+			},
+		},
+		{
+			name:   "Authorization violation",
+			server: &fakeNATSServer{token: "s3cr3t"},
+			env: map[string]string{
+				envNATSTokenFile: wrongTokenFile,
+			},
+			expected: "CONNECT failed: server error: Authorization Violation",
+		},
+		{
+			name:   "TLS not supported",
+			server: &fakeNATSServer{},
+			env: map[string]string{
+				envNATSTLS: "true",
+			},
+			expected: "server does not support TLS",
+		},
+		{
+			name:   "JetStream not enabled",
+			server: &fakeNATSServer{headers: true},
+			env: map[string]string{
+				envNATSJetStream: "true",
+			},
+			expected: "JetStream is not enabled",
+		},
+		{
+			name:   "JetStream not enabled for account",
+			server: &fakeNATSServer{headers: true, jetStream: `{"type":"io.nats.jetstream.api.v1.account_info_response","error":{"code":503,"err_code":10039,"description":"jetstream not enabled for account"}}`},
+			env: map[string]string{
+				envNATSJetStream: "true",
+			},
+			expected: "JetStream is not available: jetstream not enabled for account (503)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ln := startFakeNATSServer(t, tt.server)
+
+			checker, err := NewNATSChecker("example", "nats://"+ln.Addr().String(), 1*time.Second, func(key string) string { return tt.env[key] })
+			if err != nil {
+				t.Fatalf("failed to create NATSChecker: %q", err)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+
+			err = checker.Check(ctx)
+			if tt.expected == "" {
+				if err != nil {
+					t.Fatalf("expected no error, got %q", err)
+				}
+				return
+			}
+
+			if err == nil {
+				t.Fatal("expected an error, got none")
+			}
+			if err.Error() != tt.expected {
+				t.Errorf("expected error %q, got %q", tt.expected, err.Error())
+			}
+		})
+	}
+}
+
+func TestReadNATSMessage(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Message with headers", func(t *testing.T) {
+		t.Parallel()
+
+		br := bufio.NewReader(strings.NewReader("NATS/1.0 503\r\n\r\n\r\n"))
+		headers, payload, err := readNATSMessage(br, "HMSG _INBOX.1 1 16 16")
+		if err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+		if headers != "NATS/1.0 503\r\n\r\n" || len(payload) != 0 {
+			t.Errorf("unexpected message: headers %q, payload %q", headers, payload)
+		}
+	})
+
+	t.Run("Message too large", func(t *testing.T) {
+		t.Parallel()
+
+		br := bufio.NewReader(strings.NewReader(""))
+		_, _, err := readNATSMessage(br, fmt.Sprintf("MSG _INBOX.1 1 %d", natsMaxPayloadSize+1))
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := fmt.Sprintf("invalid message size: %q", fmt.Sprintf("MSG _INBOX.1 1 %d", natsMaxPayloadSize+1))
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+}
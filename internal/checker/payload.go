@@ -0,0 +1,35 @@
+package checker
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+const (
+	payloadEncodingText string = "text"
+	payloadEncodingHex  string = "hex"
+)
+
+// textEscapes replaces the escape sequences supported in text payloads.
+// Environment variables cannot easily contain control characters, so line protocols need a way to express them.
+var textEscapes = strings.NewReplacer(`\r`, "\r", `\n`, "\n", `\t`, "\t", `\0`, "\x00", `\\`, `\`)
+
+// parsePayload converts a payload string into bytes according to the given encoding.
+// Text payloads support the escape sequences \r, \n, \t, \0 and \\.
+// Hex payloads may contain whitespace and colons between bytes (e.g. "de ad:be ef").
+func parsePayload(payload, encoding string) ([]byte, error) {
+	switch strings.ToLower(encoding) {
+	case "", payloadEncodingText:
+		return []byte(textEscapes.Replace(payload)), nil
+	case payloadEncodingHex:
+		cleaned := strings.NewReplacer(" ", "", ":", "", "\t", "", "\n", "").Replace(payload)
+		data, err := hex.DecodeString(cleaned)
+		if err != nil {
+			return nil, fmt.Errorf("invalid hex payload: %w", err)
+		}
+		return data, nil
+	default:
+		return nil, fmt.Errorf("unsupported payload encoding: %s", encoding)
+	}
+}
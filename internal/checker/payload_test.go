@@ -0,0 +1,79 @@
+package checker
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestParsePayload(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Text payload with escape sequences", func(t *testing.T) {
+		t.Parallel()
+
+		payload, err := parsePayload(`stats\r\n\\`, "text")
+		if err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+
+		expected := []byte("stats\r\n\\")
+		if !bytes.Equal(payload, expected) {
+			t.Errorf("expected %q, got %q", expected, payload)
+		}
+	})
+
+	t.Run("Text payload is the default", func(t *testing.T) {
+		t.Parallel()
+
+		payload, err := parsePayload("PING", "")
+		if err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+
+		if string(payload) != "PING" {
+			t.Errorf("expected %q, got %q", "PING", payload)
+		}
+	})
+
+	t.Run("Hex payload", func(t *testing.T) {
+		t.Parallel()
+
+		payload, err := parsePayload("01 02:0A ff", "HEX")
+		if err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+
+		expected := []byte{0x01, 0x02, 0x0a, 0xff}
+		if !bytes.Equal(payload, expected) {
+			t.Errorf("expected %v, got %v", expected, payload)
+		}
+	})
+
+	t.Run("Invalid hex payload", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := parsePayload("abc", "hex")
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := "invalid hex payload: encoding/hex: odd length hex string"
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Unsupported encoding", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := parsePayload("abc", "base64")
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := "unsupported payload encoding: base64"
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+}
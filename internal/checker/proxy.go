@@ -0,0 +1,243 @@
+package checker
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/http/httpproxy"
+	"golang.org/x/net/proxy"
+)
+
+const (
+	envAllProxy string = "ALL_PROXY"
+	envNoProxy  string = "NO_PROXY"
+
+	proxySchemeHTTP    string = "http"    // HTTP CONNECT proxy.
+	proxySchemeSOCKS5  string = "socks5"  // SOCKS5 proxy.
+	proxySchemeSOCKS5H string = "socks5h" // SOCKS5 proxy, an alias for socks5 since host names are always resolved by the proxy.
+
+	defaultHTTPProxyPort   string = "80"
+	defaultSOCKS5ProxyPort string = "1080"
+)
+
+// contextDialer opens connections, either directly or through a proxy.
+type contextDialer interface {
+	DialContext(ctx context.Context, network, address string) (net.Conn, error)
+}
+
+// proxyDialer opens TCP connections through a SOCKS5 or HTTP CONNECT proxy.
+// Addresses matching NO_PROXY are dialed directly, following the rules of the HTTP checker.
+type proxyDialer struct {
+	URL      *url.URL                         // The URL of the proxy.
+	direct   *net.Dialer                      // The dialer for the proxy and for addresses which are not proxied.
+	socks    proxy.ContextDialer              // The SOCKS5 dialer. Nil for HTTP CONNECT proxies.
+	proxyFor func(*url.URL) (*url.URL, error) // Returns the proxy for a URL, or nil if it must not be proxied.
+}
+
+// newProxyDialer creates a dialer for TCP connections, which go through the proxy configured in ALL_PROXY.
+// Without a proxy, it returns the dialer created by newDialer.
+func newProxyDialer(timeout time.Duration, getEnv func(string) string) (contextDialer, error) {
+	// Connections to the proxy originate from the configured source address and interface
+	dialer, err := newDialer("tcp", timeout, getEnv)
+	if err != nil {
+		return nil, err
+	}
+
+	// Like net/http, the lowercase variables are used if the uppercase ones are not set
+	proxyStr := lookupEnvAnyCase(getEnv, envAllProxy)
+	if proxyStr == "" {
+		return dialer, nil
+	}
+
+	proxyURL, err := parseProxyURL(proxyStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s value: %w", envAllProxy, err)
+	}
+
+	d := &proxyDialer{
+		URL:    proxyURL,
+		direct: dialer,
+		proxyFor: (&httpproxy.Config{
+			HTTPProxy: proxyURL.String(),
+			NoProxy:   lookupEnvAnyCase(getEnv, envNoProxy),
+		}).ProxyFunc(),
+	}
+
+	if proxyURL.Scheme != proxySchemeHTTP {
+		var auth *proxy.Auth
+		if proxyURL.User != nil {
+			password, _ := proxyURL.User.Password()
+			auth = &proxy.Auth{User: proxyURL.User.Username(), Password: password}
+		}
+
+		socks, err := proxy.SOCKS5("tcp", proxyURL.Host, auth, dialer)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value: %w", envAllProxy, err)
+		}
+		d.socks = socks.(proxy.ContextDialer)
+	}
+
+	return d, nil
+}
+
+// lookupEnvAnyCase returns the value of the variable, or of its lowercase variant if the variable is not set.
+func lookupEnvAnyCase(getEnv func(string) string, env string) string {
+	if value := getEnv(env); value != "" {
+		return value
+	}
+	return getEnv(strings.ToLower(env))
+}
+
+// parseProxyURL parses the URL of a SOCKS5 or HTTP CONNECT proxy and adds the default port if it is missing.
+func parseProxyURL(proxyStr string) (*url.URL, error) {
+	proxyURL, err := url.Parse(proxyStr)
+	if err != nil {
+		return nil, err
+	}
+
+	var defaultPort string
+	switch proxyURL.Scheme {
+	case proxySchemeHTTP:
+		defaultPort = defaultHTTPProxyPort
+	case proxySchemeSOCKS5, proxySchemeSOCKS5H:
+		defaultPort = defaultSOCKS5ProxyPort
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q", proxyURL.Scheme)
+	}
+
+	if proxyURL.Hostname() == "" {
+		return nil, fmt.Errorf("missing proxy host in %q", proxyStr)
+	}
+	if proxyURL.Port() == "" {
+		proxyURL.Host = net.JoinHostPort(proxyURL.Hostname(), defaultPort)
+	}
+
+	return proxyURL, nil
+}
+
+// DialContext connects to the address through the proxy, or directly if the address matches NO_PROXY.
+func (d *proxyDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	if !d.proxied(address) {
+		return d.direct.DialContext(ctx, network, address)
+	}
+
+	// The timeout of the dialer covers the handshake with the proxy as well
+	if d.direct.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d.direct.Timeout)
+		defer cancel()
+	}
+
+	// The proxy is reached over any family, since the family of the target is up to the proxy resolving it
+	if d.socks != nil {
+		conn, err := d.socks.DialContext(ctx, "tcp", address)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect through proxy %s: %w", d.URL.Host, err)
+		}
+		return conn, nil
+	}
+
+	return d.dialHTTPConnect(ctx, address)
+}
+
+// proxied reports whether connections to the address go through the proxy.
+func (d *proxyDialer) proxied(address string) bool {
+	proxyURL, err := d.proxyFor(&url.URL{Scheme: proxySchemeHTTP, Host: address})
+	return err == nil && proxyURL != nil
+}
+
+// dialHTTPConnect connects to the proxy and asks it to open a tunnel to the address with a CONNECT request.
+func (d *proxyDialer) dialHTTPConnect(ctx context.Context, address string) (net.Conn, error) {
+	conn, err := d.direct.DialContext(ctx, "tcp", d.URL.Host)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to proxy %s: %w", d.URL.Host, err)
+	}
+
+	// Abort the handshake once the context is done
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	stop := context.AfterFunc(ctx, func() {
+		_ = conn.SetDeadline(time.Now())
+	})
+	defer stop()
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: address},
+		Host:   address,
+		Header: make(http.Header),
+	}
+	if d.URL.User != nil {
+		password, _ := d.URL.User.Password()
+		credentials := base64.StdEncoding.EncodeToString([]byte(d.URL.User.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+credentials)
+	}
+
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to send CONNECT request to proxy %s: %w", d.URL.Host, err)
+	}
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to read CONNECT response from proxy %s: %w", d.URL.Host, err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("proxy %s refused to connect to %s: %s", d.URL.Host, address, resp.Status)
+	}
+
+	if !stop() {
+		conn.Close()
+		return nil, ctx.Err()
+	}
+	if err := conn.SetDeadline(time.Time{}); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to reset deadline: %w", err)
+	}
+
+	// Servers speaking first, like SMTP or SSH, may have sent data along with the response
+	if br.Buffered() > 0 {
+		return &bufferedConn{Conn: conn, reader: br}, nil
+	}
+
+	return conn, nil
+}
+
+// bufferedConn is a connection whose reads are served from a reader which may have buffered data already.
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader // The reader wrapping the connection.
+}
+
+// Read reads from the buffered data first, then from the connection.
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.reader.Read(b)
+}
+
+// directDialer returns the dialer used for direct connections to the address, or false if they go through a proxy.
+func directDialer(dialer contextDialer, address string) (*net.Dialer, bool) {
+	switch d := dialer.(type) {
+	case *net.Dialer:
+		return d, true
+	case *proxyDialer:
+		if !d.proxied(address) {
+			return d.direct, true
+		}
+	case *proxyProtocolDialer:
+		return directDialer(d.dialer, address)
+	}
+	return nil, false
+}
@@ -0,0 +1,124 @@
+package checker
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+const (
+	envProxyProtocol string = "PROXY_PROTOCOL"
+
+	proxyProtocolV1 string = "v1" // Human-readable header, e.g. "PROXY TCP4 10.0.0.1 10.0.0.2 51234 443\r\n".
+	proxyProtocolV2 string = "v2" // Binary header.
+)
+
+// proxyProtocolV2Signature starts every PROXY protocol v2 header.
+var proxyProtocolV2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n") // Slice cannot be consts
+
+// proxyProtocolDialer sends a PROXY protocol header on every connection, as load balancers like HAProxy do.
+// The header announces the local and remote address of the connection.
+type proxyProtocolDialer struct {
+	Version string        // The PROXY protocol version ("v1" or "v2").
+	dialer  contextDialer // The dialer opening the connections.
+}
+
+// withProxyProtocol wraps the dialer to send the PROXY protocol header configured in PROXY_PROTOCOL
+// on connections to the address. Without a configured version, it returns the dialer as it is.
+func withProxyProtocol(dialer contextDialer, address string, getEnv func(string) string) (contextDialer, error) {
+	versionStr := getEnv(envProxyProtocol)
+	if versionStr == "" {
+		return dialer, nil
+	}
+
+	version := strings.ToLower(versionStr)
+	switch version {
+	case proxyProtocolV1, proxyProtocolV2:
+	default:
+		return nil, fmt.Errorf("invalid %s value: %s", envProxyProtocol, versionStr)
+	}
+
+	// Through a proxy, the header would announce the proxy instead of the target as destination
+	if _, ok := directDialer(dialer, address); !ok {
+		return nil, fmt.Errorf("invalid %s value: the header cannot be sent through a proxy", envProxyProtocol)
+	}
+
+	return &proxyProtocolDialer{Version: version, dialer: dialer}, nil
+}
+
+// DialContext connects to the address and sends the PROXY protocol header before anything else.
+func (d *proxyProtocolDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	conn, err := d.dialer.DialContext(ctx, network, address)
+	if err != nil {
+		return nil, err
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetWriteDeadline(deadline)
+	}
+
+	header := proxyProtocolHeader(d.Version, conn.LocalAddr(), conn.RemoteAddr())
+	if _, err := conn.Write(header); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to send PROXY protocol header: %w", err)
+	}
+
+	if err := conn.SetWriteDeadline(time.Time{}); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to reset deadline: %w", err)
+	}
+
+	return conn, nil
+}
+
+// proxyProtocolHeader builds the header announcing a TCP connection from src to dst.
+// If the addresses are not TCP addresses of the same family, the header declares the connection as unknown,
+// so the receiver uses the addresses of the connection itself.
+func proxyProtocolHeader(version string, src, dst net.Addr) []byte {
+	srcAddr, srcOK := src.(*net.TCPAddr)
+	dstAddr, dstOK := dst.(*net.TCPAddr)
+
+	// IPv4 addresses are announced in their 4-byte form
+	var srcIP, dstIP net.IP
+	if srcOK && dstOK {
+		srcIP, dstIP = srcAddr.IP.To4(), dstAddr.IP.To4()
+		if srcIP == nil && dstIP == nil {
+			srcIP, dstIP = srcAddr.IP.To16(), dstAddr.IP.To16()
+		}
+	}
+	known := srcIP != nil && dstIP != nil
+
+	if version == proxyProtocolV1 {
+		if !known {
+			return []byte("PROXY UNKNOWN\r\n")
+		}
+		family := "TCP4"
+		if len(srcIP) == net.IPv6len {
+			family = "TCP6"
+		}
+		return []byte(fmt.Sprintf("PROXY %s %s %s %d %d\r\n", family, srcIP, dstIP, srcAddr.Port, dstAddr.Port))
+	}
+
+	// Version 2 and PROXY command, followed by the address family and transport protocol
+	header := append([]byte{}, proxyProtocolV2Signature...)
+	header = append(header, 0x21)
+	if !known {
+		return append(header, 0x00, 0x00, 0x00) // Unspecified family without addresses
+	}
+
+	family := byte(0x11) // TCP over IPv4
+	if len(srcIP) == net.IPv6len {
+		family = 0x21 // TCP over IPv6
+	}
+	header = append(header, family)
+	header = binary.BigEndian.AppendUint16(header, uint16(2*len(srcIP)+4))
+	header = append(header, srcIP...)
+	header = append(header, dstIP...)
+	header = binary.BigEndian.AppendUint16(header, uint16(srcAddr.Port))
+	header = binary.BigEndian.AppendUint16(header, uint16(dstAddr.Port))
+
+	return header
+}
@@ -0,0 +1,341 @@
+package checker
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// startProxyProtocolServer starts an HTTP server which, like a backend behind HAProxy, requires a PROXY protocol header
+// and drops connections without it. Whether the header announces the connection correctly is sent to the returned channel.
+func startProxyProtocolServer(t *testing.T) (net.Listener, <-chan error) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to start PROXY protocol server: %q", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	results := make(chan error, 10)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+
+				r := bufio.NewReader(conn)
+				header, err := readProxyProtocolHeader(r)
+				if err != nil {
+					return
+				}
+
+				// The client announces its own address as source and the server as destination
+				version := proxyProtocolV2
+				if bytes.HasPrefix(header, []byte("PROXY ")) {
+					version = proxyProtocolV1
+				}
+				expected := proxyProtocolHeader(version, conn.RemoteAddr(), conn.LocalAddr())
+				if !bytes.Equal(header, expected) {
+					results <- fmt.Errorf("expected header %q, got %q", expected, header)
+				} else {
+					results <- nil
+				}
+
+				if _, err := http.ReadRequest(r); err != nil {
+					return
+				}
+				fmt.Fprint(conn, "HTTP/1.1 200 OK\r\nContent-Length: 0\r\nConnection: close\r\n\r\n")
+			}()
+		}
+	}()
+
+	return ln, results
+}
+
+// readProxyProtocolHeader reads a PROXY protocol v1 or v2 header.
+func readProxyProtocolHeader(r *bufio.Reader) ([]byte, error) {
+	prefix, err := r.Peek(len(proxyProtocolV2Signature))
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.HasPrefix(string(prefix), "PROXY ") {
+		line, err := r.ReadString('\n')
+		return []byte(line), err
+	}
+	if !bytes.Equal(prefix, proxyProtocolV2Signature) {
+		return nil, fmt.Errorf("missing PROXY protocol header")
+	}
+
+	header := make([]byte, len(proxyProtocolV2Signature)+4)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	addresses := make([]byte, int(header[len(header)-2])<<8|int(header[len(header)-1]))
+	if _, err := io.ReadFull(r, addresses); err != nil {
+		return nil, err
+	}
+
+	return append(header, addresses...), nil
+}
+
+func TestWithProxyProtocol(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Not configured", func(t *testing.T) {
+		t.Parallel()
+
+		dialer := &net.Dialer{}
+		wrapped, err := withProxyProtocol(dialer, "example.com:80", func(string) string { return "" })
+		if err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+		if wrapped != dialer {
+			t.Errorf("expected the dialer to be returned as it is, got %T", wrapped)
+		}
+	})
+
+	t.Run("Version 2", func(t *testing.T) {
+		t.Parallel()
+
+		wrapped, err := withProxyProtocol(&net.Dialer{}, "example.com:80", func(key string) string {
+			return map[string]string{envProxyProtocol: "V2"}[key]
+		})
+		if err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+
+		d, ok := wrapped.(*proxyProtocolDialer)
+		if !ok {
+			t.Fatalf("expected a *proxyProtocolDialer, got %T", wrapped)
+		}
+		if d.Version != proxyProtocolV2 {
+			t.Errorf("expected Version to be %q, got %q", proxyProtocolV2, d.Version)
+		}
+	})
+
+	t.Run("Invalid version", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := withProxyProtocol(&net.Dialer{}, "example.com:80", func(key string) string {
+			return map[string]string{envProxyProtocol: "v3"}[key]
+		})
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := fmt.Sprintf("invalid %s value: v3", envProxyProtocol)
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Through a proxy", func(t *testing.T) {
+		t.Parallel()
+
+		tests := []struct {
+			name    string
+			newFunc func(name, address string, timeout time.Duration, getEnv func(string) string) (Checker, error)
+			address string
+			env     map[string]string
+		}{
+			{
+				name:    "TCP check with ALL_PROXY",
+				newFunc: NewTCPChecker,
+				address: "db.example.com:5432",
+				env:     map[string]string{envAllProxy: "socks5://bastion:1080"},
+			},
+			{
+				name:    "HTTP check with HTTPS_PROXY",
+				newFunc: NewHTTPChecker,
+				address: "https://api.example.com/healthz",
+				env:     map[string]string{"HTTPS_PROXY": "http://proxy:3128"},
+			},
+		}
+
+		for _, tt := range tests {
+			tt.env[envProxyProtocol] = proxyProtocolV1
+
+			_, err := tt.newFunc("example", tt.address, 1*time.Second, func(key string) string { return tt.env[key] })
+			if err == nil {
+				t.Fatalf("%s: expected an error, got none", tt.name)
+			}
+
+			expected := fmt.Sprintf("invalid %s value: the header cannot be sent through a proxy", envProxyProtocol)
+			if err.Error() != expected {
+				t.Errorf("%s: expected error %q, got %q", tt.name, expected, err.Error())
+			}
+		}
+	})
+
+	t.Run("Excluded from the proxy", func(t *testing.T) {
+		t.Parallel()
+
+		env := map[string]string{
+			envAllProxy:      "socks5://bastion:1080",
+			envNoProxy:       ".svc.cluster.local",
+			envProxyProtocol: proxyProtocolV2,
+		}
+
+		checker, err := NewTCPChecker("example", "db.default.svc.cluster.local:5432", 1*time.Second, func(key string) string { return env[key] })
+		if err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+
+		if _, ok := checker.(*TCPChecker).dialer.(*proxyProtocolDialer); !ok {
+			t.Errorf("expected a *proxyProtocolDialer, got %T", checker.(*TCPChecker).dialer)
+		}
+	})
+}
+
+func TestProxyProtocolHeader(t *testing.T) {
+	t.Parallel()
+
+	tcp4Src := &net.TCPAddr{IP: net.ParseIP("192.168.0.1"), Port: 56324}
+	tcp4Dst := &net.TCPAddr{IP: net.ParseIP("192.168.0.11"), Port: 443}
+	tcp6Src := &net.TCPAddr{IP: net.ParseIP("2001:db8::1"), Port: 56324}
+	tcp6Dst := &net.TCPAddr{IP: net.ParseIP("2001:db8::11"), Port: 443}
+
+	v2Prefix := string(proxyProtocolV2Signature) + "\x21"
+
+	tests := []struct {
+		name     string
+		version  string
+		src      net.Addr
+		dst      net.Addr
+		expected string
+	}{
+		{
+			name:     "Version 1 over IPv4",
+			version:  proxyProtocolV1,
+			src:      tcp4Src,
+			dst:      tcp4Dst,
+			expected: "PROXY TCP4 192.168.0.1 192.168.0.11 56324 443\r\n",
+		},
+		{
+			name:     "Version 1 over IPv6",
+			version:  proxyProtocolV1,
+			src:      tcp6Src,
+			dst:      tcp6Dst,
+			expected: "PROXY TCP6 2001:db8::1 2001:db8::11 56324 443\r\n",
+		},
+		{
+			name:     "Version 1 with mixed families",
+			version:  proxyProtocolV1,
+			src:      tcp4Src,
+			dst:      tcp6Dst,
+			expected: "PROXY UNKNOWN\r\n",
+		},
+		{
+			name:     "Version 2 over IPv4",
+			version:  proxyProtocolV2,
+			src:      tcp4Src,
+			dst:      tcp4Dst,
+			expected: v2Prefix + "\x11\x00\x0c\xc0\xa8\x00\x01\xc0\xa8\x00\x0b\xdc\x04\x01\xbb",
+		},
+		{
+			name:     "Version 2 over IPv6",
+			version:  proxyProtocolV2,
+			src:      tcp6Src,
+			dst:      tcp6Dst,
+			expected: v2Prefix + "\x21\x00\x24" + string(tcp6Src.IP) + string(tcp6Dst.IP) + "\xdc\x04\x01\xbb",
+		},
+		{
+			name:     "Version 2 without TCP addresses",
+			version:  proxyProtocolV2,
+			src:      &net.UnixAddr{Name: "/tmp/src.sock", Net: "unix"},
+			dst:      tcp4Dst,
+			expected: v2Prefix + "\x00\x00\x00",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			header := proxyProtocolHeader(tt.version, tt.src, tt.dst)
+			if string(header) != tt.expected {
+				t.Errorf("expected header %q, got %q", tt.expected, header)
+			}
+		})
+	}
+}
+
+func TestProxyProtocolChecks(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		newFunc func(name, address string, timeout time.Duration, getEnv func(string) string) (Checker, error)
+		scheme  string
+		env     map[string]string
+	}{
+		{
+			name:    "TCP check with version 1",
+			newFunc: NewTCPChecker,
+			env: map[string]string{
+				envProxyProtocol: proxyProtocolV1,
+				envTCPSend:       `GET / HTTP/1.0\r\n\r\n`,
+				envTCPExpect:     `^HTTP/1\.1 200 `,
+			},
+		},
+		{
+			name:    "HTTP check with version 2",
+			newFunc: NewHTTPChecker,
+			scheme:  "http://",
+			env: map[string]string{
+				envProxyProtocol: proxyProtocolV2,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ln, results := startProxyProtocolServer(t)
+
+			checker, err := tt.newFunc("example", tt.scheme+ln.Addr().String(), 1*time.Second, func(key string) string { return tt.env[key] })
+			if err != nil {
+				t.Fatalf("failed to create checker: %q", err)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+
+			if err := checker.Check(ctx); err != nil {
+				t.Fatalf("expected no error, got %q", err)
+			}
+
+			if err := <-results; err != nil {
+				t.Error(err)
+			}
+		})
+	}
+
+	t.Run("Missing header", func(t *testing.T) {
+		t.Parallel()
+
+		ln, _ := startProxyProtocolServer(t)
+
+		checker, err := NewHTTPChecker("example", "http://"+ln.Addr().String(), 1*time.Second, func(string) string { return "" })
+		if err != nil {
+			t.Fatalf("failed to create checker: %q", err)
+		}
+
+		if err := checker.Check(context.Background()); err == nil {
+			t.Fatal("expected an error, got none")
+		}
+	})
+}
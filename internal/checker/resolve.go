@@ -0,0 +1,100 @@
+package checker
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+)
+
+const (
+	envIPFamily string = "IP_FAMILY"
+
+	ipFamilyAny  string = "any"  // Use IPv4 and IPv6 addresses.
+	ipFamilyIPv4 string = "ipv4" // Use IPv4 addresses only.
+	ipFamilyIPv6 string = "ipv6" // Use IPv6 addresses only.
+
+	defaultIPFamily string = ipFamilyAny
+
+	addressModeFirst string = "first" // Check the first resolved address only.
+	addressModeAny   string = "any"   // Check the resolved addresses until one is ready.
+	addressModeAll   string = "all"   // Check all resolved addresses, all must be ready.
+)
+
+// resolveFunc resolves a host into the addresses of the given family.
+type resolveFunc func(ctx context.Context, host, family string) ([]net.IP, error)
+
+// parseIPFamily reads the address family from IP_FAMILY.
+func parseIPFamily(getEnv func(string) string) (string, error) {
+	familyStr := getEnv(envIPFamily)
+	if familyStr == "" {
+		return defaultIPFamily, nil
+	}
+
+	switch family := strings.ToLower(familyStr); family {
+	case ipFamilyAny, ipFamilyIPv4, ipFamilyIPv6:
+		return family, nil
+	default:
+		return "", fmt.Errorf("invalid %s value: %s", envIPFamily, familyStr)
+	}
+}
+
+// parseAddressMode reads the mode deciding which resolved addresses are checked from the given variable.
+func parseAddressMode(getEnv func(string) string, env string) (string, error) {
+	modeStr := getEnv(env)
+	if modeStr == "" {
+		return addressModeFirst, nil
+	}
+
+	switch mode := strings.ToLower(modeStr); mode {
+	case addressModeFirst, addressModeAny, addressModeAll:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid %s value: %s", env, modeStr)
+	}
+}
+
+// lookupIPs resolves the host into the addresses of the given family, in the order returned by the resolver.
+// IP literals are returned as they are, if they belong to the family.
+func lookupIPs(ctx context.Context, host, family string) ([]net.IP, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		if !matchesIPFamily(ip, family) {
+			return nil, fmt.Errorf("%s is not an %s address", host, family)
+		}
+		return []net.IP{ip}, nil
+	}
+
+	ips, err := net.DefaultResolver.LookupIP(ctx, familyNetwork("ip", family), host)
+	if err != nil {
+		return nil, err
+	}
+	if len(ips) == 0 {
+		return nil, fmt.Errorf("no %s addresses found for %s", family, host)
+	}
+
+	return ips, nil
+}
+
+// matchesIPFamily reports whether the address belongs to the given family.
+func matchesIPFamily(ip net.IP, family string) bool {
+	switch family {
+	case ipFamilyIPv4:
+		return ip.To4() != nil
+	case ipFamilyIPv6:
+		return ip.To4() == nil
+	default:
+		return true
+	}
+}
+
+// familyNetwork restricts the network ("ip", "tcp" or "udp") to the given family, e.g. "tcp4" for "tcp" and ipv4.
+func familyNetwork(network, family string) string {
+	switch family {
+	case ipFamilyIPv4:
+		return network + "4"
+	case ipFamilyIPv6:
+		return network + "6"
+	default:
+		return network
+	}
+}
@@ -0,0 +1,108 @@
+package checker
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestParseIPFamily(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{value: "", expected: ipFamilyAny},
+		{value: "IPv4", expected: ipFamilyIPv4},
+		{value: "ipv6", expected: ipFamilyIPv6},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("Value %q", tt.value), func(t *testing.T) {
+			t.Parallel()
+
+			family, err := parseIPFamily(func(string) string { return tt.value })
+			if err != nil {
+				t.Fatalf("expected no error, got %q", err)
+			}
+			if family != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, family)
+			}
+		})
+	}
+
+	t.Run("Invalid value", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := parseIPFamily(func(string) string { return "ipx" })
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := fmt.Sprintf("invalid %s value: ipx", envIPFamily)
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+}
+
+func TestParseAddressMode(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Default", func(t *testing.T) {
+		t.Parallel()
+
+		mode, err := parseAddressMode(func(string) string { return "" }, "TEST_ADDRESSES")
+		if err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+		if mode != addressModeFirst {
+			t.Errorf("expected %q, got %q", addressModeFirst, mode)
+		}
+	})
+
+	t.Run("Invalid value", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := parseAddressMode(func(string) string { return "some" }, "TEST_ADDRESSES")
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := "invalid TEST_ADDRESSES value: some"
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+}
+
+func TestLookupIPs(t *testing.T) {
+	t.Parallel()
+
+	t.Run("IP literal", func(t *testing.T) {
+		t.Parallel()
+
+		ips, err := lookupIPs(context.Background(), "2001:db8::1", ipFamilyIPv6)
+		if err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+		if len(ips) != 1 || ips[0].String() != "2001:db8::1" {
+			t.Errorf("expected [2001:db8::1], got %v", ips)
+		}
+	})
+
+	t.Run("IP literal of another family", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := lookupIPs(context.Background(), "192.0.2.1", ipFamilyIPv6)
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := "192.0.2.1 is not an ipv6 address"
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+}
@@ -0,0 +1,174 @@
+package checker
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	envSMTPHeloName      string = "SMTP_HELO_NAME"
+	envSMTPStartTLS      string = "SMTP_STARTTLS"
+	envSMTPServerName    string = "SMTP_TLS_SERVER_NAME"
+	envSMTPCAFile        string = "SMTP_CA_FILE"
+	envSMTPSkipTLSVerify string = "SMTP_SKIP_TLS_VERIFY"
+
+	defaultSMTPHeloName      string = "localhost"
+	defaultSMTPStartTLS      bool   = false
+	defaultSMTPSkipTLSVerify bool   = false
+)
+
+// SMTPChecker implements the Checker interface for SMTP checks.
+type SMTPChecker struct {
+	Name        string        // The name of the checker.
+	Address     string        // The address of the target.
+	HeloName    string        // The name sent with EHLO.
+	StartTLS    bool          // Whether the connection must be upgraded with STARTTLS.
+	ImplicitTLS bool          // Whether TLS is used from the start (smtps://).
+	tlsConfig   *tls.Config   // The TLS configuration for STARTTLS and smtps://.
+	dialer      contextDialer // The dialer to use for the connection.
+	timeout     time.Duration // The timeout for the whole exchange with the server.
+}
+
+// String returns the name of the checker.
+func (c *SMTPChecker) String() string {
+	return c.Name
+}
+
+// NewSMTPChecker creates a new SMTPChecker.
+func NewSMTPChecker(name, address string, timeout time.Duration, getEnv func(string) string) (Checker, error) {
+	// The "smtp://" and "smtps://" prefixes are used to identify the check type and are not needed for further processing,
+	// so they must be removed before passing the address to other functions.
+	implicitTLS := strings.HasPrefix(address, "smtps://")
+	address = strings.TrimPrefix(strings.TrimPrefix(address, "smtp://"), "smtps://")
+
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address %s: %w", address, err)
+	}
+
+	// Connections originate from the configured source address and interface and go through the configured proxy
+	dialer, err := newProxyDialer(timeout, getEnv)
+	if err != nil {
+		return nil, err
+	}
+
+	checker := SMTPChecker{
+		Name:        name,
+		Address:     address,
+		HeloName:    defaultSMTPHeloName,
+		StartTLS:    defaultSMTPStartTLS,
+		ImplicitTLS: implicitTLS,
+		tlsConfig: &tls.Config{
+			ServerName:         host,
+			InsecureSkipVerify: defaultSMTPSkipTLSVerify,
+		},
+		dialer:  dialer,
+		timeout: timeout,
+	}
+
+	// Override the name sent with EHLO if specified
+	if heloName := getEnv(envSMTPHeloName); heloName != "" {
+		checker.HeloName = heloName
+	}
+
+	// Determine if the connection must be upgraded with STARTTLS
+	if startTLSStr := getEnv(envSMTPStartTLS); startTLSStr != "" {
+		startTLS, err := strconv.ParseBool(startTLSStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value: %w", envSMTPStartTLS, err)
+		}
+		checker.StartTLS = startTLS
+	}
+
+	// Override the server name used for certificate verification if specified
+	if serverName := getEnv(envSMTPServerName); serverName != "" {
+		checker.tlsConfig.ServerName = serverName
+	}
+
+	// Load the CA certificates if specified
+	if caFile := getEnv(envSMTPCAFile); caFile != "" {
+		pool, err := loadCertPool(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value: %w", envSMTPCAFile, err)
+		}
+		checker.tlsConfig.RootCAs = pool
+	}
+
+	// Determine if TLS verification should be skipped
+	if skipVerifyStr := getEnv(envSMTPSkipTLSVerify); skipVerifyStr != "" {
+		skipVerify, err := strconv.ParseBool(skipVerifyStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value: %w", envSMTPSkipTLSVerify, err)
+		}
+		checker.tlsConfig.InsecureSkipVerify = skipVerify
+	}
+
+	return &checker, nil
+}
+
+// Check reads the greeting, sends EHLO, optionally upgrades the connection with STARTTLS and quits.
+func (c *SMTPChecker) Check(ctx context.Context) error {
+	conn, err := c.dialer.DialContext(ctx, "tcp", c.Address)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// Servers delaying the greeting, e.g. to detect spammers, must still answer within the timeout
+	stop, err := deadlineConn(ctx, conn, c.timeout)
+	if err != nil {
+		return err
+	}
+	defer stop()
+
+	if c.ImplicitTLS {
+		tlsConn := tls.Client(conn, c.tlsConfig)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			return fmt.Errorf("TLS handshake failed: %w", err)
+		}
+		conn = tlsConn
+	}
+
+	// Reading the greeting fails unless the server answers with 220
+	client, err := smtp.NewClient(conn, c.tlsConfig.ServerName)
+	if err != nil {
+		return fmt.Errorf("unexpected greeting: %w", smtpReplyError(err))
+	}
+	defer client.Close()
+
+	if err := client.Hello(c.HeloName); err != nil {
+		return fmt.Errorf("EHLO failed: %w", smtpReplyError(err))
+	}
+
+	if c.StartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("server does not support STARTTLS")
+		}
+		if err := client.StartTLS(c.tlsConfig); err != nil {
+			return fmt.Errorf("STARTTLS failed: %w", smtpReplyError(err))
+		}
+	}
+
+	if err := client.Quit(); err != nil {
+		return fmt.Errorf("QUIT failed: %w", smtpReplyError(err))
+	}
+
+	return nil
+}
+
+// smtpReplyError formats unexpected replies of the server as "<code> <message>", leaving other errors untouched.
+func smtpReplyError(err error) error {
+	var replyErr *textproto.Error
+	if errors.As(err, &replyErr) {
+		return fmt.Errorf("%03d %s", replyErr.Code, replyErr.Msg)
+	}
+	return err
+}
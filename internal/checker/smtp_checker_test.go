@@ -0,0 +1,359 @@
+package checker
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeSMTPServer is a minimal SMTP server supporting EHLO, STARTTLS and QUIT.
+type fakeSMTPServer struct {
+	greeting  string      // The greeting sent after connecting.
+	ehloReply string      // The reply to EHLO and HELO. If empty, a default reply is sent.
+	tlsConfig *tls.Config // The TLS configuration. If nil, STARTTLS is not offered.
+	implicit  bool        // Whether TLS is used from the start.
+}
+
+func (s *fakeSMTPServer) serve(conn net.Conn) {
+	defer conn.Close()
+
+	if s.implicit {
+		conn = tls.Server(conn, s.tlsConfig)
+	}
+
+	fmt.Fprintf(conn, "%s\r\n", s.greeting)
+	if !strings.HasPrefix(s.greeting, "220") {
+		return
+	}
+
+	r := bufio.NewReader(conn)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		command := strings.ToUpper(strings.Fields(line + " ")[0])
+
+		switch command {
+		case "EHLO", "HELO":
+			switch {
+			case s.ehloReply != "":
+				fmt.Fprintf(conn, "%s\r\n", s.ehloReply)
+			case s.tlsConfig != nil && !s.implicit:
+				fmt.Fprint(conn, "250-mail.example.com\r\n250-PIPELINING\r\n250 STARTTLS\r\n")
+			default:
+				fmt.Fprint(conn, "250-mail.example.com\r\n250 PIPELINING\r\n")
+			}
+		case "STARTTLS":
+			fmt.Fprint(conn, "220 Ready to start TLS\r\n")
+			conn = tls.Server(conn, s.tlsConfig)
+			r = bufio.NewReader(conn)
+		case "QUIT":
+			fmt.Fprint(conn, "221 Bye\r\n")
+			return
+		default:
+			fmt.Fprint(conn, "502 Command not implemented\r\n")
+		}
+	}
+}
+
+// startFakeSMTPServer starts the given fake SMTP server on a random port.
+func startFakeSMTPServer(t *testing.T, server *fakeSMTPServer) net.Listener {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to start fake SMTP server: %q", err)
+	}
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn)
+		}
+	}()
+
+	return ln
+}
+
+func TestNewSMTPChecker(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Valid SMTP checker config", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envSMTPHeloName:   "portpatrol.example.com",
+				envSMTPStartTLS:   "true",
+				envSMTPServerName: "relay.example.com",
+			}
+			return env[key]
+		}
+
+		checker, err := NewSMTPChecker("example", "smtp://mail.example.com:587", 1*time.Second, mockEnv)
+		if err != nil {
+			t.Fatalf("failed to create SMTPChecker: %q", err)
+		}
+
+		smtpChecker := checker.(*SMTPChecker)
+
+		expected := "mail.example.com:587"
+		if smtpChecker.Address != expected {
+			t.Errorf("expected Address to be %q, got %q", expected, smtpChecker.Address)
+		}
+
+		if smtpChecker.HeloName != "portpatrol.example.com" {
+			t.Errorf("expected HeloName to be %q, got %q", "portpatrol.example.com", smtpChecker.HeloName)
+		}
+
+		if !smtpChecker.StartTLS || smtpChecker.ImplicitTLS {
+			t.Errorf("expected STARTTLS without implicit TLS, got StartTLS=%t ImplicitTLS=%t", smtpChecker.StartTLS, smtpChecker.ImplicitTLS)
+		}
+
+		if smtpChecker.tlsConfig.ServerName != "relay.example.com" {
+			t.Errorf("expected ServerName to be %q, got %q", "relay.example.com", smtpChecker.tlsConfig.ServerName)
+		}
+	})
+
+	t.Run("Valid SMTPS checker config", func(t *testing.T) {
+		t.Parallel()
+
+		checker, err := NewSMTPChecker("example", "smtps://mail.example.com:465", 1*time.Second, func(string) string { return "" })
+		if err != nil {
+			t.Fatalf("failed to create SMTPChecker: %q", err)
+		}
+
+		smtpChecker := checker.(*SMTPChecker)
+
+		if !smtpChecker.ImplicitTLS {
+			t.Error("expected ImplicitTLS to be true")
+		}
+
+		if smtpChecker.tlsConfig.ServerName != "mail.example.com" {
+			t.Errorf("expected ServerName to be %q, got %q", "mail.example.com", smtpChecker.tlsConfig.ServerName)
+		}
+	})
+
+	t.Run("Missing port", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := NewSMTPChecker("example", "smtp://mail.example.com", 1*time.Second, func(string) string { return "" })
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := "invalid address mail.example.com: address mail.example.com: missing port in address"
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Invalid SMTP_STARTTLS", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envSMTPStartTLS: "invalid",
+			}
+			return env[key]
+		}
+
+		_, err := NewSMTPChecker("example", "mail.example.com:25", 1*time.Second, mockEnv)
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := fmt.Sprintf("invalid %s value: strconv.ParseBool: parsing \"invalid\": invalid syntax", envSMTPStartTLS)
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+}
+
+func TestSMTPChecker(t *testing.T) {
+	t.Parallel()
+
+	// The test server provides a certificate valid for example.com and 127.0.0.1
+	certServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	t.Cleanup(certServer.Close)
+
+	caFile := writeServerCA(t, certServer)
+	serverTLSConfig := &tls.Config{Certificates: certServer.TLS.Certificates}
+
+	t.Run("Valid SMTP check", func(t *testing.T) {
+		t.Parallel()
+
+		ln := startFakeSMTPServer(t, &fakeSMTPServer{greeting: "220 mail.example.com ESMTP"})
+		defer ln.Close()
+
+		checker, err := NewSMTPChecker("example", ln.Addr().String(), 1*time.Second, func(string) string { return "" })
+		if err != nil {
+			t.Fatalf("failed to create SMTPChecker: %q", err)
+		}
+
+		if err := checker.Check(context.Background()); err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+	})
+
+	t.Run("Valid SMTP check with STARTTLS", func(t *testing.T) {
+		t.Parallel()
+
+		ln := startFakeSMTPServer(t, &fakeSMTPServer{greeting: "220 mail.example.com ESMTP", tlsConfig: serverTLSConfig})
+		defer ln.Close()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envSMTPStartTLS:   "true",
+				envSMTPCAFile:     caFile,
+				envSMTPServerName: "example.com",
+			}
+			return env[key]
+		}
+
+		checker, err := NewSMTPChecker("example", "smtp://"+ln.Addr().String(), 1*time.Second, mockEnv)
+		if err != nil {
+			t.Fatalf("failed to create SMTPChecker: %q", err)
+		}
+
+		if err := checker.Check(context.Background()); err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+	})
+
+	t.Run("Valid SMTPS check", func(t *testing.T) {
+		t.Parallel()
+
+		ln := startFakeSMTPServer(t, &fakeSMTPServer{greeting: "220 mail.example.com ESMTP", tlsConfig: serverTLSConfig, implicit: true})
+		defer ln.Close()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envSMTPCAFile: caFile,
+			}
+			return env[key]
+		}
+
+		checker, err := NewSMTPChecker("example", "smtps://"+ln.Addr().String(), 1*time.Second, mockEnv)
+		if err != nil {
+			t.Fatalf("failed to create SMTPChecker: %q", err)
+		}
+
+		if err := checker.Check(context.Background()); err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+	})
+
+	t.Run("Untrusted certificate", func(t *testing.T) {
+		t.Parallel()
+
+		ln := startFakeSMTPServer(t, &fakeSMTPServer{greeting: "220 mail.example.com ESMTP", tlsConfig: serverTLSConfig})
+		defer ln.Close()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envSMTPStartTLS: "true",
+			}
+			return env[key]
+		}
+
+		checker, err := NewSMTPChecker("example", ln.Addr().String(), 1*time.Second, mockEnv)
+		if err != nil {
+			t.Fatalf("failed to create SMTPChecker: %q", err)
+		}
+
+		err = checker.Check(context.Background())
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := "STARTTLS failed: tls: failed to verify certificate: x509: certificate signed by unknown authority"
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("STARTTLS not supported", func(t *testing.T) {
+		t.Parallel()
+
+		ln := startFakeSMTPServer(t, &fakeSMTPServer{greeting: "220 mail.example.com ESMTP"})
+		defer ln.Close()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envSMTPStartTLS: "true",
+			}
+			return env[key]
+		}
+
+		checker, err := NewSMTPChecker("example", ln.Addr().String(), 1*time.Second, mockEnv)
+		if err != nil {
+			t.Fatalf("failed to create SMTPChecker: %q", err)
+		}
+
+		err = checker.Check(context.Background())
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := "server does not support STARTTLS"
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Service not available", func(t *testing.T) {
+		t.Parallel()
+
+		ln := startFakeSMTPServer(t, &fakeSMTPServer{greeting: "421 mail.example.com Service not available"})
+		defer ln.Close()
+
+		checker, err := NewSMTPChecker("example", ln.Addr().String(), 1*time.Second, func(string) string { return "" })
+		if err != nil {
+			t.Fatalf("failed to create SMTPChecker: %q", err)
+		}
+
+		err = checker.Check(context.Background())
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := "unexpected greeting: 421 mail.example.com Service not available"
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("EHLO rejected", func(t *testing.T) {
+		t.Parallel()
+
+		ln := startFakeSMTPServer(t, &fakeSMTPServer{greeting: "220 mail.example.com ESMTP", ehloReply: "554 Access denied"})
+		defer ln.Close()
+
+		checker, err := NewSMTPChecker("example", ln.Addr().String(), 1*time.Second, func(string) string { return "" })
+		if err != nil {
+			t.Fatalf("failed to create SMTPChecker: %q", err)
+		}
+
+		err = checker.Check(context.Background())
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := "EHLO failed: 554 Access denied"
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+}
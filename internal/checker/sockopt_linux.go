@@ -0,0 +1,19 @@
+package checker
+
+import "syscall"
+
+// bindToDeviceSupported reports whether sockets can be bound to a network interface.
+const bindToDeviceSupported = true
+
+// bindToDevice binds the socket to the network interface (SO_BINDTODEVICE).
+func bindToDevice(fd uintptr, name string) error {
+	return syscall.BindToDevice(int(fd), name)
+}
+
+// setTTL sets the time to live (IPv4) or hop limit (IPv6) of the packets sent over the socket.
+func setTTL(fd uintptr, ipv6 bool, ttl int) error {
+	if ipv6 {
+		return syscall.SetsockoptInt(int(fd), syscall.IPPROTO_IPV6, syscall.IPV6_UNICAST_HOPS, ttl)
+	}
+	return syscall.SetsockoptInt(int(fd), syscall.IPPROTO_IP, syscall.IP_TTL, ttl)
+}
@@ -0,0 +1,18 @@
+//go:build !linux
+
+package checker
+
+import "errors"
+
+// bindToDeviceSupported reports whether sockets can be bound to a network interface.
+const bindToDeviceSupported = false
+
+// bindToDevice binds the socket to the network interface, which is only supported on Linux.
+func bindToDevice(fd uintptr, name string) error {
+	return errors.New("binding to an interface is only supported on Linux")
+}
+
+// setTTL sets the time to live of the packets sent over the socket, which is only supported on Linux.
+func setTTL(fd uintptr, ipv6 bool, ttl int) error {
+	return errors.New("setting the time to live of a socket is only supported on Linux")
+}
@@ -0,0 +1,132 @@
+package checker
+
+import (
+	"fmt"
+	"net"
+	"syscall"
+	"time"
+)
+
+const (
+	envSourceAddress   string = "SOURCE_ADDRESS"
+	envSourceInterface string = "SOURCE_INTERFACE"
+)
+
+// source is the local address and network interface the connections of a checker originate from.
+type source struct {
+	IP        net.IP // The local address. If nil, the operating system chooses it.
+	Interface string // The network interface. If empty, the routing table decides.
+}
+
+// parseSource reads the source address and interface from SOURCE_ADDRESS and SOURCE_INTERFACE.
+func parseSource(getEnv func(string) string) (source, error) {
+	var src source
+
+	if addressStr := getEnv(envSourceAddress); addressStr != "" {
+		ip := net.ParseIP(addressStr)
+		if ip == nil {
+			return source{}, fmt.Errorf("invalid %s value: %s", envSourceAddress, addressStr)
+		}
+		src.IP = ip
+	}
+
+	if name := getEnv(envSourceInterface); name != "" {
+		if !bindToDeviceSupported {
+			return source{}, fmt.Errorf("invalid %s value: binding to an interface is only supported on Linux", envSourceInterface)
+		}
+		if _, err := net.InterfaceByName(name); err != nil {
+			return source{}, fmt.Errorf("invalid %s value: %w", envSourceInterface, err)
+		}
+		src.Interface = name
+	}
+
+	return src, nil
+}
+
+// newDialer creates a dialer for the given network ("tcp" or "udp"), whose connections originate from the configured source.
+func newDialer(network string, timeout time.Duration, getEnv func(string) string) (*net.Dialer, error) {
+	src, err := parseSource(getEnv)
+	if err != nil {
+		return nil, err
+	}
+
+	return src.dialer(network, timeout), nil
+}
+
+// dialer creates a dialer for the given network ("tcp" or "udp"), whose connections originate from the source.
+func (s source) dialer(network string, timeout time.Duration) *net.Dialer {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+	}
+
+	// The local address must match the network of the dialer
+	if s.IP != nil {
+		if network == "udp" {
+			dialer.LocalAddr = &net.UDPAddr{IP: s.IP}
+		} else {
+			dialer.LocalAddr = &net.TCPAddr{IP: s.IP}
+		}
+	}
+
+	if s.Interface != "" {
+		dialer.Control = s.control
+	}
+
+	return dialer
+}
+
+// control binds the socket to the source interface, so packets leave through it regardless of the routing table.
+func (s source) control(network, address string, c syscall.RawConn) error {
+	var bindErr error
+	if err := c.Control(func(fd uintptr) {
+		bindErr = bindToDevice(fd, s.Interface)
+	}); err != nil {
+		return err
+	}
+	if bindErr != nil {
+		return fmt.Errorf("failed to bind to interface %s: %w", s.Interface, bindErr)
+	}
+
+	return nil
+}
+
+// listenAddress returns the local address to listen on for packets of the address family of ip.
+func (s source) listenAddress(ip net.IP) (string, error) {
+	if s.IP != nil {
+		if (s.IP.To4() == nil) != (ip.To4() == nil) {
+			return "", fmt.Errorf("source address %s does not match the address family of %s", s.IP, ip)
+		}
+		return s.IP.String(), nil
+	}
+
+	if ip.To4() == nil {
+		return "::", nil
+	}
+	return "0.0.0.0", nil
+}
+
+// interfaceIP returns the first address of the network interface in the given family.
+func interfaceIP(name string, ipv6 bool) (net.IP, error) {
+	iface, err := net.InterfaceByName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get addresses of interface %s: %w", name, err)
+	}
+
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if ok && (ipNet.IP.To4() == nil) == ipv6 {
+			return ipNet.IP, nil
+		}
+	}
+
+	family := ipFamilyIPv4
+	if ipv6 {
+		family = ipFamilyIPv6
+	}
+	return nil, fmt.Errorf("interface %s has no %s address", name, family)
+}
@@ -0,0 +1,173 @@
+package checker
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestParseSource(t *testing.T) {
+	t.Parallel()
+
+	t.Run("No source", func(t *testing.T) {
+		t.Parallel()
+
+		src, err := parseSource(func(string) string { return "" })
+		if err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+		if src.IP != nil || src.Interface != "" {
+			t.Errorf("expected an empty source, got %+v", src)
+		}
+	})
+
+	t.Run("Source address", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envSourceAddress: "2001:db8::1",
+			}
+			return env[key]
+		}
+
+		src, err := parseSource(mockEnv)
+		if err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+		if !src.IP.Equal(net.ParseIP("2001:db8::1")) {
+			t.Errorf("expected IP 2001:db8::1, got %v", src.IP)
+		}
+	})
+
+	t.Run("Invalid source address", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envSourceAddress: "10.0.0.300",
+			}
+			return env[key]
+		}
+
+		_, err := parseSource(mockEnv)
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := fmt.Sprintf("invalid %s value: 10.0.0.300", envSourceAddress)
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Unknown interface", func(t *testing.T) {
+		t.Parallel()
+
+		if runtime.GOOS != "linux" {
+			t.Skip("binding to an interface is only supported on Linux")
+		}
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envSourceInterface: "nonexistent0",
+			}
+			return env[key]
+		}
+
+		_, err := parseSource(mockEnv)
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := fmt.Sprintf("invalid %s value: route ip+net: no such network interface", envSourceInterface)
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+}
+
+func TestSourceDialer(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Local address matches network", func(t *testing.T) {
+		t.Parallel()
+
+		src := source{IP: net.IPv4(127, 0, 0, 1)}
+
+		if _, ok := src.dialer("tcp", time.Second).LocalAddr.(*net.TCPAddr); !ok {
+			t.Error("expected a TCP local address for tcp")
+		}
+		if _, ok := src.dialer("udp", time.Second).LocalAddr.(*net.UDPAddr); !ok {
+			t.Error("expected a UDP local address for udp")
+		}
+	})
+
+	t.Run("No source", func(t *testing.T) {
+		t.Parallel()
+
+		dialer := source{}.dialer("tcp", time.Second)
+		if dialer.LocalAddr != nil || dialer.Control != nil {
+			t.Errorf("expected a plain dialer, got %+v", dialer)
+		}
+		if dialer.Timeout != time.Second {
+			t.Errorf("expected timeout 1s, got %s", dialer.Timeout)
+		}
+	})
+
+	t.Run("Bind to interface", func(t *testing.T) {
+		t.Parallel()
+
+		if runtime.GOOS != "linux" {
+			t.Skip("binding to an interface is only supported on Linux")
+		}
+
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("failed to start TCP server: %q", err)
+		}
+		t.Cleanup(func() { ln.Close() })
+
+		conn, err := source{Interface: "lo"}.dialer("tcp", time.Second).Dial("tcp", ln.Addr().String())
+		if errors.Is(err, os.ErrPermission) {
+			t.Skip("binding to an interface is not permitted")
+		}
+		if err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+		conn.Close()
+	})
+}
+
+func TestInterfaceIP(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Loopback address", func(t *testing.T) {
+		t.Parallel()
+
+		if runtime.GOOS != "linux" {
+			t.Skip("the loopback interface is named differently")
+		}
+
+		ip, err := interfaceIP("lo", false)
+		if err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+		if !ip.IsLoopback() {
+			t.Errorf("expected a loopback address, got %v", ip)
+		}
+	})
+
+	t.Run("Unknown interface", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := interfaceIP("nonexistent0", false)
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+	})
+}
@@ -1,17 +1,61 @@
 package checker
 
 import (
+	"bytes"
 	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log/slog"
 	"net"
+	"os"
+	"regexp"
+	"strconv"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
+
+	"golang.org/x/net/icmp"
+)
+
+const (
+	envTCPSend         string = "TCP_SEND"
+	envTCPSendEncoding string = "TCP_SEND_ENCODING"
+	envTCPExpect       string = "TCP_EXPECT"
+	envTCPReadTimeout  string = "TCP_READ_TIMEOUT"
+	envTCPAddresses    string = "TCP_ADDRESSES"
+
+	defaultTCPSendEncoding string        = payloadEncodingText
+	defaultTCPReadTimeout  time.Duration = 1 * time.Second
+
+	tcpMaxResponseSize int = 64 * 1024 // The maximum number of bytes read while waiting for the expected response.
+
+	tcpProtocolNumber    int           = 6
+	tcpTraceGracePeriod  time.Duration = 100 * time.Millisecond // The time to wait for the ICMP error after the kernel aborted a connection.
+	defaultTCPTraceProbe time.Duration = 1 * time.Second        // The time to wait for each probe of a trace, if the dialer has no timeout.
 )
 
 // TCPChecker implements the Checker interface for TCP checks.
 type TCPChecker struct {
-	Name    string      // The name of the checker.
-	Address string      // The address of the target.
-	dialer  *net.Dialer // The dialer to use for the connection.
+	Name         string             // The name of the checker.
+	Address      string             // The address of the target.
+	Send         []byte             // The bytes to send after connecting. If empty, nothing is sent.
+	Expect       *regexp.Regexp     // The pattern the response (or banner) must match. If nil, the response is not read.
+	ReadTimeout  time.Duration      // The timeout for sending and reading the response.
+	TraceMaxHops int                // The maximum number of hops probed when tracing the path to the target.
+	Family       string             // The address family used for the target ("any", "ipv4" or "ipv6").
+	Addresses    string             // Which resolved addresses are checked ("first", "any" or "all").
+	dialer       contextDialer      // The dialer to use for the connection.
+	source       source             // The local address and network interface the connections originate from.
+	resolve      resolveFunc        // The resolver for the addresses of the target. If nil, lookupIPs is used.
+	results      []tcpAddressResult // The results of the last check per address. Empty unless every resolved address is checked.
+}
+
+// tcpAddressResult is the result of checking one resolved address of the target.
+type tcpAddressResult struct {
+	IP  net.IP // The checked address.
+	Err error  // The error of the check. Nil if the address is ready.
 }
 
 // String returns the name of the checker.
@@ -20,29 +64,392 @@ func (c *TCPChecker) String() string {
 }
 
 // NewTCPChecker creates a new TCPChecker.
-func NewTCPChecker(name, address string, timeout time.Duration) (Checker, error) {
+func NewTCPChecker(name, address string, timeout time.Duration, getEnv func(string) string) (Checker, error) {
 	// The "tcp://" prefix is used to identify the check type and is not needed for further processing,
 	// so it must be removed before passing the address to other functions.
 	address = strings.TrimPrefix(address, "tcp://")
 
+	// Connections originate from the configured source address and interface and go through the configured proxy
+	dialer, err := newProxyDialer(timeout, getEnv)
+	if err != nil {
+		return nil, err
+	}
+
+	// Connections start with a PROXY protocol header, if configured
+	dialer, err = withProxyProtocol(dialer, address, getEnv)
+	if err != nil {
+		return nil, err
+	}
+
+	// Traces listen for ICMP errors on the source of the connections
+	src, err := parseSource(getEnv)
+	if err != nil {
+		return nil, err
+	}
+
 	checker := TCPChecker{
-		Address: address,
-		Name:    name,
-		dialer: &net.Dialer{
-			Timeout: timeout,
-		},
+		Address:      address,
+		Name:         name,
+		ReadTimeout:  defaultTCPReadTimeout,
+		TraceMaxHops: defaultTraceMaxHops,
+		dialer:       dialer,
+		source:       src,
+	}
+
+	// Decode the bytes to send after connecting
+	encoding := defaultTCPSendEncoding
+	if encodingStr := getEnv(envTCPSendEncoding); encodingStr != "" {
+		encoding = encodingStr
+	}
+	send, err := parsePayload(getEnv(envTCPSend), encoding)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s value: %w", envTCPSend, err)
+	}
+	checker.Send = send
+
+	// Compile the expected response pattern if specified
+	if expectStr := getEnv(envTCPExpect); expectStr != "" {
+		expect, err := regexp.Compile(expectStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value: %w", envTCPExpect, err)
+		}
+		checker.Expect = expect
+	}
+
+	// Determine the read timeout
+	if readTimeoutStr := getEnv(envTCPReadTimeout); readTimeoutStr != "" {
+		readTimeout, err := time.ParseDuration(readTimeoutStr)
+		if err != nil || readTimeout <= 0 {
+			return nil, fmt.Errorf("invalid %s value: %s", envTCPReadTimeout, readTimeoutStr)
+		}
+		checker.ReadTimeout = readTimeout
+	}
+
+	// Determine the maximum number of hops when tracing the path
+	traceMaxHops, err := parseTraceMaxHops(getEnv)
+	if err != nil {
+		return nil, err
+	}
+	checker.TraceMaxHops = traceMaxHops
+
+	// Determine the address family and which resolved addresses are checked
+	family, err := parseIPFamily(getEnv)
+	if err != nil {
+		return nil, err
 	}
+	checker.Family = family
+
+	addresses, err := parseAddressMode(getEnv, envTCPAddresses)
+	if err != nil {
+		return nil, err
+	}
+	if addresses != addressModeFirst {
+		// The addresses are resolved locally, which defeats a proxy resolving host names itself
+		if _, ok := directDialer(dialer, address); !ok {
+			return nil, fmt.Errorf("invalid %s value: resolved addresses cannot be checked through a proxy", envTCPAddresses)
+		}
+	}
+	checker.Addresses = addresses
 
 	return &checker, nil
 }
 
 // Check performs a TCP connection check.
+// If configured, it sends data after connecting and waits for a response matching the expected pattern.
+// By default, the dialer picks the resolved address, preferring whichever family connects first.
+// With the "any" or "all" mode, every resolved address is checked and the results are reported per address.
 func (c *TCPChecker) Check(ctx context.Context) error {
-	conn, err := c.dialer.DialContext(ctx, "tcp", c.Address)
+	c.results = nil
+
+	if c.Addresses != addressModeAny && c.Addresses != addressModeAll {
+		return c.checkAddress(ctx, familyNetwork("tcp", c.Family), c.Address)
+	}
+
+	host, port, err := net.SplitHostPort(c.Address)
+	if err != nil {
+		return fmt.Errorf("invalid address %s: %w", c.Address, err)
+	}
+
+	// Resolve the addresses on every check, so DNS changes are picked up
+	ips, err := c.resolveIPs(ctx, host)
+	if err != nil {
+		return err
+	}
+
+	// Check all addresses at once, so an unresponsive address does not delay the others
+	results := make([]tcpAddressResult, len(ips))
+	var wg sync.WaitGroup
+	for i, ip := range ips {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = tcpAddressResult{IP: ip, Err: c.checkAddress(ctx, "tcp", net.JoinHostPort(ip.String(), port))}
+		}()
+	}
+	wg.Wait()
+	c.results = results
+
+	var errs []error
+	for _, result := range results {
+		if result.Err == nil {
+			if c.Addresses == addressModeAny {
+				return nil
+			}
+			continue
+		}
+
+		err := result.Err
+		if len(results) > 1 {
+			err = fmt.Errorf("%s: %w", result.IP, err)
+		}
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// checkAddress connects to the address and, if configured, exchanges the data with it.
+func (c *TCPChecker) checkAddress(ctx context.Context, network, address string) error {
+	conn, err := c.dialer.DialContext(ctx, network, address)
 	if err != nil {
 		return err
 	}
 	defer conn.Close()
 
-	return nil
+	if len(c.Send) == 0 && c.Expect == nil {
+		return nil
+	}
+
+	// Set the deadline for the whole exchange
+	deadline := time.Now().Add(c.ReadTimeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		return fmt.Errorf("failed to set deadline: %w", err)
+	}
+
+	if len(c.Send) > 0 {
+		if _, err := conn.Write(c.Send); err != nil {
+			return fmt.Errorf("failed to send data to %s: %w", address, err)
+		}
+	}
+
+	if c.Expect == nil {
+		return nil
+	}
+
+	return c.expectResponse(conn)
+}
+
+// expectResponse reads from the connection until the response matches the expected pattern,
+// the connection is closed or the deadline is reached.
+func (c *TCPChecker) expectResponse(conn net.Conn) error {
+	var response bytes.Buffer
+	buf := make([]byte, 4096)
+
+	for response.Len() < tcpMaxResponseSize {
+		n, err := conn.Read(buf)
+		response.Write(buf[:n])
+
+		if c.Expect.Match(response.Bytes()) {
+			return nil
+		}
+
+		if err != nil {
+			return fmt.Errorf("expected response matching %q, got %q: %w", c.Expect.String(), response.Bytes(), err)
+		}
+	}
+
+	return fmt.Errorf("expected response matching %q not found in the first %d bytes", c.Expect.String(), tcpMaxResponseSize)
+}
+
+// Trace connects to the target with increasing TTLs and returns the hosts answering the connection attempts.
+// Routers report expired SYN packets with ICMP time exceeded messages, which are received on a raw ICMP socket,
+// so tracing requires the CAP_NET_RAW capability. Targets reached through a proxy cannot be traced.
+func (c *TCPChecker) Trace(ctx context.Context) ([]Hop, error) {
+	dialer, ok := directDialer(c.dialer, c.Address)
+	if !ok {
+		return nil, fmt.Errorf("tracing is not supported for connections through a proxy")
+	}
+
+	host, portStr, err := net.SplitHostPort(c.Address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address %s: %w", c.Address, err)
+	}
+	port, err := net.DefaultResolver.LookupPort(ctx, "tcp", portStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid port %s: %w", portStr, err)
+	}
+
+	ips, err := c.resolveIPs(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	ip := ips[0]
+
+	// Listen for the ICMP errors quoting the SYN packets
+	protocol := newProtocol(ip, icmpModeRaw, c.source.Interface)
+	conn, err := listenTrace(ctx, protocol, c.source, ip)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	return traceHops(c.TraceMaxHops, ip, func(ttl int) (time.Duration, error) {
+		return probeTTL(ctx, dialer, conn, ip, port, ttl)
+	})
+}
+
+// probeTTL connects to the target with the given TTL and waits for the connection or an ICMP error quoting the SYN packet.
+// A refused connection counts as reached, since the target itself answered.
+func probeTTL(ctx context.Context, base *net.Dialer, conn net.PacketConn, ip net.IP, port, ttl int) (time.Duration, error) {
+	timeout := base.Timeout
+	if timeout <= 0 {
+		timeout = defaultTCPTraceProbe
+	}
+
+	// Set the TTL before the SYN packet is sent, after binding the configured source
+	dialer := *base
+	dialer.Control = func(network, address string, rc syscall.RawConn) error {
+		if base.Control != nil {
+			if err := base.Control(network, address, rc); err != nil {
+				return err
+			}
+		}
+
+		var ttlErr error
+		if err := rc.Control(func(fd uintptr) {
+			ttlErr = setTTL(fd, ip.To4() == nil, ttl)
+		}); err != nil {
+			return err
+		}
+		return ttlErr
+	}
+
+	probeCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	if err := conn.SetReadDeadline(start.Add(timeout)); err != nil {
+		return 0, fmt.Errorf("failed to set read deadline: %w", err)
+	}
+
+	dialed := make(chan error, 1)
+	go func() {
+		tcpConn, err := dialer.DialContext(probeCtx, "tcp", net.JoinHostPort(ip.String(), strconv.Itoa(port)))
+		if err == nil {
+			tcpConn.Close()
+		}
+		dialed <- err
+	}()
+
+	reported := make(chan error, 1)
+	go func() {
+		reported <- awaitTCPError(conn, ip, port)
+	}()
+
+	var dialErr, reportErr error
+	select {
+	case dialErr = <-dialed:
+		// Stop reading, but leave time for the ICMP error which made the kernel abort the connection
+		deadline := time.Now()
+		if dialErr != nil && !errors.Is(dialErr, syscall.ECONNREFUSED) {
+			deadline = deadline.Add(tcpTraceGracePeriod)
+		}
+		_ = conn.SetReadDeadline(deadline)
+		reportErr = <-reported
+	case reportErr = <-reported:
+		cancel()
+		dialErr = <-dialed
+	}
+	rtt := time.Since(start)
+
+	var icmpErr *icmpError
+	switch {
+	case errors.As(reportErr, &icmpErr):
+		return rtt, icmpErr
+	case dialErr == nil, errors.Is(dialErr, syscall.ECONNREFUSED):
+		return rtt, nil
+	case ctx.Err() != nil:
+		return 0, ctx.Err()
+	case probeCtx.Err() != nil:
+		return 0, os.ErrDeadlineExceeded
+	default:
+		return 0, dialErr
+	}
+}
+
+// awaitTCPError reads ICMP messages until one quotes a TCP packet to the given address and port, or the read deadline expires.
+func awaitTCPError(conn net.PacketConn, ip net.IP, port int) error {
+	protocolNumber := icmpv4ProtocolNumber
+	if ip.To4() == nil {
+		protocolNumber = icmpv6ProtocolNumber
+	}
+
+	buf := make([]byte, 1500)
+	for {
+		n, peer, err := conn.ReadFrom(buf)
+		if err != nil {
+			return err
+		}
+
+		msg, err := icmp.ParseMessage(protocolNumber, buf[:n])
+		if err != nil {
+			continue
+		}
+
+		// The quoted TCP header starts with the source and destination port
+		icmpErr, quoted, ok := parseICMPError(msg)
+		if !ok || quoted.Protocol != tcpProtocolNumber || !quoted.Dst.Equal(ip) ||
+			len(quoted.Payload) < 4 || int(binary.BigEndian.Uint16(quoted.Payload[2:4])) != port {
+			continue
+		}
+
+		if ipAddr, ok := peer.(*net.IPAddr); ok {
+			icmpErr.from = ipAddr.IP.String()
+		}
+		return icmpErr
+	}
+}
+
+// resolveIPs resolves the host of the target into the addresses of the configured family.
+func (c *TCPChecker) resolveIPs(ctx context.Context, host string) ([]net.IP, error) {
+	resolve := c.resolve
+	if resolve == nil {
+		resolve = lookupIPs
+	}
+
+	ips, err := resolve(ctx, host, c.Family)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve IP address: %w", err)
+	}
+
+	return ips, nil
+}
+
+// Stats returns the ready and not ready addresses of the last check, if every resolved address was checked.
+func (c *TCPChecker) Stats() []slog.Attr {
+	if len(c.results) == 0 {
+		return nil
+	}
+
+	var ready, notReady []string
+	for _, result := range c.results {
+		if result.Err == nil {
+			ready = append(ready, result.IP.String())
+		} else {
+			notReady = append(notReady, result.IP.String())
+		}
+	}
+
+	var attrs []slog.Attr
+	if len(ready) > 0 {
+		attrs = append(attrs, slog.String("ready", strings.Join(ready, ",")))
+	}
+	if len(notReady) > 0 {
+		attrs = append(attrs, slog.String("not_ready", strings.Join(notReady, ",")))
+	}
+
+	return attrs
 }
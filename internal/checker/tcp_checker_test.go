@@ -1,12 +1,53 @@
 package checker
 
 import (
+	"bufio"
 	"context"
+	"errors"
+	"fmt"
 	"net"
+	"os"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/containeroo/portpatrol/internal/testutils"
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
 )
 
+// startTCPLineServer starts a TCP server that writes the banner on connect and
+// answers every received line with the given reply.
+func startTCPLineServer(t *testing.T, banner, reply string) net.Listener {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to start TCP server: %q", err)
+	}
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				if banner != "" {
+					_, _ = conn.Write([]byte(banner))
+				}
+				scanner := bufio.NewScanner(conn)
+				for scanner.Scan() {
+					_, _ = conn.Write([]byte(reply))
+				}
+			}(conn)
+		}
+	}()
+
+	return ln
+}
+
 func TestTCPChecker(t *testing.T) {
 	t.Parallel()
 
@@ -19,7 +60,7 @@ func TestTCPChecker(t *testing.T) {
 		}
 		defer ln.Close()
 
-		checker, err := NewTCPChecker("example", ln.Addr().String(), 1*time.Second)
+		checker, err := NewTCPChecker("example", ln.Addr().String(), 1*time.Second, func(string) string { return "" })
 		if err != nil {
 			t.Fatalf("failed to create TCPChecker: %q", err)
 		}
@@ -31,10 +72,50 @@ func TestTCPChecker(t *testing.T) {
 		}
 	})
 
+	t.Run("Valid TCP check from source address", func(t *testing.T) {
+		t.Parallel()
+
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("failed to start TCP server: %q", err)
+		}
+		defer ln.Close()
+
+		remote := make(chan net.Addr, 1)
+		go func() {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			remote <- conn.RemoteAddr()
+			conn.Close()
+		}()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envSourceAddress: "127.0.0.2",
+			}
+			return env[key]
+		}
+
+		checker, err := NewTCPChecker("example", ln.Addr().String(), 1*time.Second, mockEnv)
+		if err != nil {
+			t.Fatalf("failed to create TCPChecker: %q", err)
+		}
+
+		if err := checker.Check(context.Background()); err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+
+		if addr := <-remote; addr.(*net.TCPAddr).IP.String() != "127.0.0.2" {
+			t.Errorf("expected connection from 127.0.0.2, got %v", addr)
+		}
+	})
+
 	t.Run("Failed TCP check", func(t *testing.T) {
 		t.Parallel()
 
-		checker, err := NewTCPChecker("example", "localhost:7090", 1*time.Second)
+		checker, err := NewTCPChecker("example", "localhost:7090", 1*time.Second, func(string) string { return "" })
 		if err != nil {
 			t.Fatalf("failed to create TCPChecker: %q", err)
 		}
@@ -49,4 +130,527 @@ func TestTCPChecker(t *testing.T) {
 			t.Errorf("expected error containing %q, got %q", expected, err)
 		}
 	})
+
+	t.Run("Valid TCP banner check", func(t *testing.T) {
+		t.Parallel()
+
+		ln := startTCPLineServer(t, "SSH-2.0-OpenSSH_9.6\r\n", "")
+		defer ln.Close()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envTCPExpect: "^SSH-2\\.0-",
+			}
+			return env[key]
+		}
+
+		checker, err := NewTCPChecker("example", ln.Addr().String(), 1*time.Second, mockEnv)
+		if err != nil {
+			t.Fatalf("failed to create TCPChecker: %q", err)
+		}
+
+		if err := checker.Check(context.Background()); err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+	})
+
+	t.Run("Valid TCP send/expect check", func(t *testing.T) {
+		t.Parallel()
+
+		ln := startTCPLineServer(t, "", "STAT pid 1\r\nEND\r\n")
+		defer ln.Close()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envTCPSend:   `stats\r\n`,
+				envTCPExpect: `END\r\n$`,
+			}
+			return env[key]
+		}
+
+		checker, err := NewTCPChecker("example", ln.Addr().String(), 1*time.Second, mockEnv)
+		if err != nil {
+			t.Fatalf("failed to create TCPChecker: %q", err)
+		}
+
+		if err := checker.Check(context.Background()); err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+	})
+
+	t.Run("Unexpected TCP response", func(t *testing.T) {
+		t.Parallel()
+
+		ln := startTCPLineServer(t, "", "-ERR unknown command\r\n")
+		defer ln.Close()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envTCPSend:        `PING\r\n`,
+				envTCPExpect:      `\+PONG`,
+				envTCPReadTimeout: "200ms",
+			}
+			return env[key]
+		}
+
+		checker, err := NewTCPChecker("example", ln.Addr().String(), 1*time.Second, mockEnv)
+		if err != nil {
+			t.Fatalf("failed to create TCPChecker: %q", err)
+		}
+
+		err = checker.Check(context.Background())
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := `expected response matching "\\+PONG", got "-ERR unknown command\r\n": `
+		if !strings.HasPrefix(err.Error(), expected) || !strings.Contains(err.Error(), "i/o timeout") {
+			t.Errorf("expected error starting with %q, got %q", expected, err)
+		}
+	})
+
+	t.Run("Connection closed before expected response", func(t *testing.T) {
+		t.Parallel()
+
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("failed to start TCP server: %q", err)
+		}
+		defer ln.Close()
+
+		go func() {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			_, _ = conn.Write([]byte("421 too busy\r\n"))
+			conn.Close()
+		}()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envTCPExpect: "^220 ",
+			}
+			return env[key]
+		}
+
+		checker, err := NewTCPChecker("example", ln.Addr().String(), 1*time.Second, mockEnv)
+		if err != nil {
+			t.Fatalf("failed to create TCPChecker: %q", err)
+		}
+
+		err = checker.Check(context.Background())
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := `expected response matching "^220 ", got "421 too busy\r\n": EOF`
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err)
+		}
+	})
+}
+
+func TestNewTCPChecker(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Valid TCP checker config", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envTCPSend:         "0d0a",
+				envTCPSendEncoding: "hex",
+				envTCPExpect:       "^220",
+				envTCPReadTimeout:  "5s",
+			}
+			return env[key]
+		}
+
+		checker, err := NewTCPChecker("example", "tcp://localhost:25", 1*time.Second, mockEnv)
+		if err != nil {
+			t.Fatalf("failed to create TCPChecker: %q", err)
+		}
+
+		tcpChecker := checker.(*TCPChecker)
+
+		expected := "localhost:25"
+		if tcpChecker.Address != expected {
+			t.Errorf("expected Address to be %q, got %q", expected, tcpChecker.Address)
+		}
+
+		if string(tcpChecker.Send) != "\r\n" {
+			t.Errorf("expected Send to be %q, got %q", "\r\n", tcpChecker.Send)
+		}
+
+		if tcpChecker.Expect.String() != "^220" {
+			t.Errorf("expected Expect to be %q, got %q", "^220", tcpChecker.Expect.String())
+		}
+
+		if tcpChecker.ReadTimeout != 5*time.Second {
+			t.Errorf("expected ReadTimeout to be 5s, got %v", tcpChecker.ReadTimeout)
+		}
+	})
+
+	t.Run("Invalid send encoding", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envTCPSend:         "PING",
+				envTCPSendEncoding: "base64",
+			}
+			return env[key]
+		}
+
+		_, err := NewTCPChecker("example", "localhost:25", 1*time.Second, mockEnv)
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := fmt.Sprintf("invalid %s value: unsupported payload encoding: base64", envTCPSend)
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Invalid expected pattern", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envTCPExpect: "[",
+			}
+			return env[key]
+		}
+
+		_, err := NewTCPChecker("example", "localhost:25", 1*time.Second, mockEnv)
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := fmt.Sprintf("invalid %s value: error parsing regexp: missing closing ]: `[`", envTCPExpect)
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Invalid read timeout", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envTCPReadTimeout: "invalid",
+			}
+			return env[key]
+		}
+
+		_, err := NewTCPChecker("example", "localhost:25", 1*time.Second, mockEnv)
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := fmt.Sprintf("invalid %s value: invalid", envTCPReadTimeout)
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Invalid addresses", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envTCPAddresses: "some",
+			}
+			return env[key]
+		}
+
+		_, err := NewTCPChecker("example", "localhost:25", 1*time.Second, mockEnv)
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := fmt.Sprintf("invalid %s value: some", envTCPAddresses)
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("All addresses through a proxy", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envTCPAddresses: "all",
+				envAllProxy:     "socks5://bastion.example.com",
+			}
+			return env[key]
+		}
+
+		_, err := NewTCPChecker("example", "db.example.com:5432", 1*time.Second, mockEnv)
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := fmt.Sprintf("invalid %s value: resolved addresses cannot be checked through a proxy", envTCPAddresses)
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+}
+
+func TestTCPCheckerAddresses(t *testing.T) {
+	t.Parallel()
+
+	// Both ready addresses listen on the same port, the third one refuses connections
+	ln := startTCPLineServer(t, "220 ready\r\n", "")
+	t.Cleanup(func() { ln.Close() })
+	_, port, _ := net.SplitHostPort(ln.Addr().String())
+
+	ln2, err := net.Listen("tcp", net.JoinHostPort("127.0.0.2", port))
+	if err != nil {
+		t.Skipf("failed to listen on 127.0.0.2: %q", err)
+	}
+	t.Cleanup(func() { ln2.Close() })
+	go func() {
+		for {
+			conn, err := ln2.Accept()
+			if err != nil {
+				return
+			}
+			_, _ = conn.Write([]byte("220 ready\r\n"))
+			conn.Close()
+		}
+	}()
+
+	ready := []net.IP{net.ParseIP("127.0.0.1"), net.ParseIP("127.0.0.2")}
+	halfBroken := []net.IP{net.ParseIP("127.0.0.1"), net.ParseIP("127.0.0.3")}
+
+	tests := []struct {
+		name      string
+		addresses string
+		ips       []net.IP
+		expected  string
+		stats     string
+	}{
+		{
+			name:      "All addresses ready",
+			addresses: addressModeAll,
+			ips:       ready,
+			stats:     "[ready=127.0.0.1,127.0.0.2]",
+		},
+		{
+			name:      "Any address ready",
+			addresses: addressModeAny,
+			ips:       halfBroken,
+			stats:     "[ready=127.0.0.1 not_ready=127.0.0.3]",
+		},
+		{
+			name:      "Not all addresses ready",
+			addresses: addressModeAll,
+			ips:       halfBroken,
+			expected:  fmt.Sprintf("127.0.0.3: dial tcp 127.0.0.3:%s: connect: connection refused", port),
+			stats:     "[ready=127.0.0.1 not_ready=127.0.0.3]",
+		},
+		{
+			name:      "Single address not ready",
+			addresses: addressModeAny,
+			ips:       halfBroken[1:],
+			expected:  fmt.Sprintf("dial tcp 127.0.0.3:%s: connect: connection refused", port),
+			stats:     "[not_ready=127.0.0.3]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockEnv := func(key string) string {
+				env := map[string]string{
+					envTCPAddresses: tt.addresses,
+					envTCPExpect:    "^220 ",
+				}
+				return env[key]
+			}
+
+			checker, err := NewTCPChecker("example", net.JoinHostPort("pods.example.com", port), 1*time.Second, mockEnv)
+			if err != nil {
+				t.Fatalf("failed to create TCPChecker: %q", err)
+			}
+			tcpChecker := checker.(*TCPChecker)
+			tcpChecker.resolve = func(ctx context.Context, host, family string) ([]net.IP, error) {
+				return tt.ips, nil
+			}
+
+			err = tcpChecker.Check(context.Background())
+			if tt.expected == "" && err != nil {
+				t.Fatalf("expected no error, got %q", err)
+			}
+			if tt.expected != "" {
+				if err == nil {
+					t.Fatal("expected an error, got none")
+				}
+				if err.Error() != tt.expected {
+					t.Errorf("expected error %q, got %q", tt.expected, err.Error())
+				}
+			}
+
+			if stats := fmt.Sprint(tcpChecker.Stats()); stats != tt.stats {
+				t.Errorf("expected stats %s, got %s", tt.stats, stats)
+			}
+		})
+	}
+
+	t.Run("First address leaves the choice to the dialer", func(t *testing.T) {
+		t.Parallel()
+
+		checker, err := NewTCPChecker("example", ln.Addr().String(), 1*time.Second, func(string) string { return "" })
+		if err != nil {
+			t.Fatalf("failed to create TCPChecker: %q", err)
+		}
+
+		if err := checker.Check(context.Background()); err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+		if stats := checker.(StatsReporter).Stats(); stats != nil {
+			t.Errorf("expected no stats, got %v", stats)
+		}
+	})
+
+	t.Run("Address of the wrong family", func(t *testing.T) {
+		t.Parallel()
+
+		checker, err := NewTCPChecker("example", ln.Addr().String(), 1*time.Second, func(key string) string {
+			return map[string]string{envIPFamily: ipFamilyIPv6}[key]
+		})
+		if err != nil {
+			t.Fatalf("failed to create TCPChecker: %q", err)
+		}
+
+		err = checker.Check(context.Background())
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := "dial tcp6: address 127.0.0.1: no suitable address found"
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+}
+
+func TestTCPCheckerTrace(t *testing.T) {
+	t.Parallel()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to start TCP server: %q", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	// A closed port refuses the connection, which also proves the target was reached
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to start TCP server: %q", err)
+	}
+	closedAddress := closed.Addr().String()
+	closed.Close()
+
+	tests := []struct {
+		name    string
+		address string
+	}{
+		{name: "Open port", address: ln.Addr().String()},
+		{name: "Closed port", address: closedAddress},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			checker, err := NewTCPChecker("example", tt.address, 1*time.Second, func(string) string { return "" })
+			if err != nil {
+				t.Fatalf("failed to create TCPChecker: %q", err)
+			}
+
+			hops, err := checker.(*TCPChecker).Trace(context.Background())
+			if errors.Is(err, os.ErrPermission) {
+				t.Skip("raw sockets are not permitted")
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %q", err)
+			}
+
+			if len(hops) != 1 || !hops[0].Reached || hops[0].Address != "127.0.0.1" {
+				t.Errorf("expected the target to be reached at the first hop, got %+v", hops)
+			}
+		})
+	}
+
+	t.Run("Source Address Of Another Family", func(t *testing.T) {
+		t.Parallel()
+
+		env := map[string]string{
+			"SOURCE_ADDRESS": "::1",
+		}
+		checker, err := NewTCPChecker("example", ln.Addr().String(), 1*time.Second, func(key string) string { return env[key] })
+		if err != nil {
+			t.Fatalf("failed to create TCPChecker: %q", err)
+		}
+
+		// The ICMP errors are received on the source address, which cannot be one of another family
+		_, err = checker.(*TCPChecker).Trace(context.Background())
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := "source address ::1 does not match the address family of 127.0.0.1"
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+}
+
+func TestAwaitTCPError(t *testing.T) {
+	t.Parallel()
+
+	target := net.IPv4(198, 51, 100, 1)
+
+	// timeExceeded returns a time exceeded message quoting a TCP SYN packet to the target and port
+	timeExceeded := func(port uint16) []byte {
+		header := &ipv4.Header{Version: ipv4.Version, Len: ipv4.HeaderLen, TotalLen: ipv4.HeaderLen + 20, Protocol: tcpProtocolNumber, Dst: target}
+		data, _ := header.Marshal()
+		data = append(data, 0xc3, 0x50, byte(port>>8), byte(port), 0, 0, 0, 1) // Source port 50000, destination port, sequence number
+		msg, _ := (&icmp.Message{Type: ipv4.ICMPTypeTimeExceeded, Body: &icmp.TimeExceeded{Data: data}}).Marshal(nil)
+		return msg
+	}
+
+	packets := [][]byte{
+		timeExceeded(443), // Another connection to the target
+		timeExceeded(8080),
+	}
+	mockConn := &testutils.MockPacketConn{
+		ReadFromFunc: func(b []byte) (int, net.Addr, error) {
+			if len(packets) == 0 {
+				return 0, nil, os.ErrDeadlineExceeded
+			}
+			packet := packets[0]
+			packets = packets[1:]
+			return copy(b, packet), &net.IPAddr{IP: net.IPv4(192, 0, 2, 1)}, nil
+		},
+	}
+
+	err := awaitTCPError(mockConn, target, 8080)
+	if err == nil {
+		t.Fatal("expected an error, got none")
+	}
+
+	expected := "time to live exceeded in transit (reported by 192.0.2.1)"
+	if err.Error() != expected {
+		t.Errorf("expected error %q, got %q", expected, err.Error())
+	}
+	if len(packets) != 0 {
+		t.Errorf("expected all packets to be read, %d left", len(packets))
+	}
 }
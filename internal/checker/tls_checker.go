@@ -0,0 +1,164 @@
+package checker
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	envTLSServerName  string = "TLS_SERVER_NAME"
+	envTLSCAFile      string = "TLS_CA_FILE"
+	envTLSSkipVerify  string = "TLS_SKIP_VERIFY"
+	envTLSMinValidity string = "TLS_MIN_VALIDITY"
+
+	defaultTLSSkipVerify  bool          = false
+	defaultTLSMinValidity time.Duration = 0
+)
+
+// TLSChecker implements the Checker interface for TLS handshake and certificate checks.
+type TLSChecker struct {
+	Name        string        // The name of the checker.
+	Address     string        // The address of the target.
+	MinValidity time.Duration // The minimum remaining validity of every certificate in the chain.
+	tlsConfig   *tls.Config   // The TLS configuration to use for the handshake.
+	dialer      contextDialer // The dialer to use for the connection.
+	timeout     time.Duration // The timeout for the handshake.
+}
+
+// String returns the name of the checker.
+func (c *TLSChecker) String() string {
+	return c.Name
+}
+
+// NewTLSChecker creates a new TLSChecker.
+func NewTLSChecker(name, address string, timeout time.Duration, getEnv func(string) string) (Checker, error) {
+	// The "tls://" prefix is used to identify the check type and is not needed for further processing,
+	// so it must be removed before passing the address to other functions.
+	address = strings.TrimPrefix(address, "tls://")
+
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address %s: %w", address, err)
+	}
+
+	// Connections originate from the configured source address and interface and go through the configured proxy
+	dialer, err := newProxyDialer(timeout, getEnv)
+	if err != nil {
+		return nil, err
+	}
+
+	checker := TLSChecker{
+		Name:        name,
+		Address:     address,
+		MinValidity: defaultTLSMinValidity,
+		tlsConfig: &tls.Config{
+			ServerName: host,
+		},
+		dialer:  dialer,
+		timeout: timeout,
+	}
+
+	// Override the server name used for SNI and hostname verification if specified
+	if serverName := getEnv(envTLSServerName); serverName != "" {
+		checker.tlsConfig.ServerName = serverName
+	}
+
+	// Load the CA bundle used to verify the certificate chain if specified
+	if caFile := getEnv(envTLSCAFile); caFile != "" {
+		pool, err := loadCertPool(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value: %w", envTLSCAFile, err)
+		}
+		checker.tlsConfig.RootCAs = pool
+	}
+
+	// Determine if TLS verification should be skipped
+	skipVerify := defaultTLSSkipVerify
+	if skipVerifyStr := getEnv(envTLSSkipVerify); skipVerifyStr != "" {
+		skipVerify, err = strconv.ParseBool(skipVerifyStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value: %w", envTLSSkipVerify, err)
+		}
+	}
+	checker.tlsConfig.InsecureSkipVerify = skipVerify
+
+	// Determine the minimum remaining validity of the certificates
+	if minValidityStr := getEnv(envTLSMinValidity); minValidityStr != "" {
+		minValidity, err := time.ParseDuration(minValidityStr)
+		if err != nil || minValidity < 0 {
+			return nil, fmt.Errorf("invalid %s value: %s", envTLSMinValidity, minValidityStr)
+		}
+		checker.MinValidity = minValidity
+	}
+
+	return &checker, nil
+}
+
+// Check performs a TLS handshake and validates the presented certificates.
+func (c *TLSChecker) Check(ctx context.Context) error {
+	rawConn, err := c.dialer.DialContext(ctx, "tcp", c.Address)
+	if err != nil {
+		return err
+	}
+	defer rawConn.Close()
+
+	// Bound the handshake, so a server which never speaks TLS does not block the check
+	stop, err := deadlineConn(ctx, rawConn, c.timeout)
+	if err != nil {
+		return err
+	}
+	defer stop()
+
+	conn := tls.Client(rawConn, c.tlsConfig)
+	if err := conn.HandshakeContext(ctx); err != nil {
+		return fmt.Errorf("TLS handshake failed: %w", err)
+	}
+	defer conn.Close()
+
+	certs := conn.ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return fmt.Errorf("no certificate presented by %s", c.Address)
+	}
+
+	return validateCertificates(certs, time.Now(), c.MinValidity)
+}
+
+// validateCertificates checks that every certificate is valid at the given time
+// and does not expire within the minimum validity window.
+func validateCertificates(certs []*x509.Certificate, now time.Time, minValidity time.Duration) error {
+	for _, cert := range certs {
+		if now.Before(cert.NotBefore) {
+			return fmt.Errorf("certificate %q is not valid before %s", cert.Subject, cert.NotBefore.UTC().Format(time.RFC3339))
+		}
+		if now.After(cert.NotAfter) {
+			return fmt.Errorf("certificate %q expired at %s", cert.Subject, cert.NotAfter.UTC().Format(time.RFC3339))
+		}
+		if remaining := cert.NotAfter.Sub(now); remaining < minValidity {
+			return fmt.Errorf("certificate %q expires at %s, within the minimum validity of %s", cert.Subject, cert.NotAfter.UTC().Format(time.RFC3339), minValidity)
+		}
+	}
+
+	return nil
+}
+
+// loadCertPool reads PEM encoded certificates from the given file into a new certificate pool.
+func loadCertPool(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA file: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no valid PEM certificates found in %s", path)
+	}
+
+	return pool, nil
+}
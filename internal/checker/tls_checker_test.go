@@ -0,0 +1,340 @@
+package checker
+
+import (
+	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeServerCA writes the certificate of the TLS test server to a PEM file and returns its path.
+func writeServerCA(t *testing.T, server *httptest.Server) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "ca.crt")
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("failed to write CA file: %q", err)
+	}
+
+	return path
+}
+
+func TestNewTLSChecker(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Valid TLS checker config", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envTLSServerName:  "example.com",
+				envTLSSkipVerify:  "true",
+				envTLSMinValidity: "72h",
+			}
+			return env[key]
+		}
+
+		checker, err := NewTLSChecker("example", "tls://localhost:443", 1*time.Second, mockEnv)
+		if err != nil {
+			t.Fatalf("failed to create TLSChecker: %q", err)
+		}
+
+		tlsChecker := checker.(*TLSChecker)
+
+		expected := "localhost:443"
+		if tlsChecker.Address != expected {
+			t.Errorf("expected Address to be %q, got %q", expected, tlsChecker.Address)
+		}
+
+		expected = "example.com"
+		if tlsChecker.tlsConfig.ServerName != expected {
+			t.Errorf("expected ServerName to be %q, got %q", expected, tlsChecker.tlsConfig.ServerName)
+		}
+
+		if !tlsChecker.tlsConfig.InsecureSkipVerify {
+			t.Error("expected InsecureSkipVerify to be true")
+		}
+
+		if tlsChecker.MinValidity != 72*time.Hour {
+			t.Errorf("expected MinValidity to be 72h, got %v", tlsChecker.MinValidity)
+		}
+	})
+
+	t.Run("Missing port", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := NewTLSChecker("example", "tls://localhost", 1*time.Second, func(string) string { return "" })
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := "invalid address localhost: address localhost: missing port in address"
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Missing CA file", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envTLSCAFile: "/does/not/exist.crt",
+			}
+			return env[key]
+		}
+
+		_, err := NewTLSChecker("example", "localhost:443", 1*time.Second, mockEnv)
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := fmt.Sprintf("invalid %s value: failed to read CA file: open /does/not/exist.crt: no such file or directory", envTLSCAFile)
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Invalid CA file", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "ca.crt")
+		if err := os.WriteFile(path, []byte("not a certificate"), 0o600); err != nil {
+			t.Fatalf("failed to write CA file: %q", err)
+		}
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envTLSCAFile: path,
+			}
+			return env[key]
+		}
+
+		_, err := NewTLSChecker("example", "localhost:443", 1*time.Second, mockEnv)
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := fmt.Sprintf("invalid %s value: no valid PEM certificates found in %s", envTLSCAFile, path)
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Invalid TLS_SKIP_VERIFY", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envTLSSkipVerify: "invalid",
+			}
+			return env[key]
+		}
+
+		_, err := NewTLSChecker("example", "localhost:443", 1*time.Second, mockEnv)
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := fmt.Sprintf("invalid %s value: strconv.ParseBool: parsing \"invalid\": invalid syntax", envTLSSkipVerify)
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Invalid TLS_MIN_VALIDITY", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envTLSMinValidity: "-1h",
+			}
+			return env[key]
+		}
+
+		_, err := NewTLSChecker("example", "localhost:443", 1*time.Second, mockEnv)
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := fmt.Sprintf("invalid %s value: -1h", envTLSMinValidity)
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+}
+
+func TestTLSChecker(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	t.Cleanup(server.Close)
+
+	caFile := writeServerCA(t, server)
+	address := strings.TrimPrefix(server.URL, "https://")
+
+	t.Run("Valid TLS check", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envTLSCAFile:      caFile,
+				envTLSServerName:  "example.com",
+				envTLSMinValidity: "720h",
+			}
+			return env[key]
+		}
+
+		checker, err := NewTLSChecker("example", address, 1*time.Second, mockEnv)
+		if err != nil {
+			t.Fatalf("failed to create TLSChecker: %q", err)
+		}
+
+		if err := checker.Check(context.Background()); err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+	})
+
+	t.Run("Untrusted certificate", func(t *testing.T) {
+		t.Parallel()
+
+		checker, err := NewTLSChecker("example", address, 1*time.Second, func(string) string { return "" })
+		if err != nil {
+			t.Fatalf("failed to create TLSChecker: %q", err)
+		}
+
+		err = checker.Check(context.Background())
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := "TLS handshake failed: tls: failed to verify certificate: x509: certificate signed by unknown authority"
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Hostname mismatch", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envTLSCAFile:     caFile,
+				envTLSServerName: "invalid.example",
+			}
+			return env[key]
+		}
+
+		checker, err := NewTLSChecker("example", address, 1*time.Second, mockEnv)
+		if err != nil {
+			t.Fatalf("failed to create TLSChecker: %q", err)
+		}
+
+		err = checker.Check(context.Background())
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := "not invalid.example"
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected error containing %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Certificate expires within minimum validity", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envTLSSkipVerify:  "true",
+				envTLSMinValidity: "1000000h",
+			}
+			return env[key]
+		}
+
+		checker, err := NewTLSChecker("example", address, 1*time.Second, mockEnv)
+		if err != nil {
+			t.Fatalf("failed to create TLSChecker: %q", err)
+		}
+
+		err = checker.Check(context.Background())
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := "within the minimum validity of 1000000h0m0s"
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected error containing %q, got %q", expected, err.Error())
+		}
+	})
+}
+
+func TestValidateCertificates(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	cert := &x509.Certificate{
+		Subject:   pkix.Name{CommonName: "example.com"},
+		NotBefore: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		NotAfter:  time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	t.Run("Valid certificate", func(t *testing.T) {
+		t.Parallel()
+
+		if err := validateCertificates([]*x509.Certificate{cert}, now, 24*time.Hour); err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+	})
+
+	t.Run("Certificate not yet valid", func(t *testing.T) {
+		t.Parallel()
+
+		err := validateCertificates([]*x509.Certificate{cert}, cert.NotBefore.Add(-time.Hour), 0)
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := `certificate "CN=example.com" is not valid before 2024-01-01T00:00:00Z`
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Expired certificate", func(t *testing.T) {
+		t.Parallel()
+
+		err := validateCertificates([]*x509.Certificate{cert}, cert.NotAfter.Add(time.Hour), 0)
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := `certificate "CN=example.com" expired at 2024-07-01T00:00:00Z`
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Certificate expires within minimum validity", func(t *testing.T) {
+		t.Parallel()
+
+		err := validateCertificates([]*x509.Certificate{cert}, now, 1000*time.Hour)
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := `certificate "CN=example.com" expires at 2024-07-01T00:00:00Z, within the minimum validity of 1000h0m0s`
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+}
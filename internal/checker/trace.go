@@ -0,0 +1,109 @@
+package checker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	envTraceMaxHops string = "TRACE_MAX_HOPS"
+
+	defaultTraceMaxHops int = 30
+
+	traceMaxSilentHops int = 3 // The number of consecutive hops without answer after which a trace stops.
+)
+
+// Hop is the result of a probe sent while tracing the path to the target.
+type Hop struct {
+	TTL     int           // The time to live of the probe.
+	Address string        // The address of the host answering the probe. If empty, no host answered in time.
+	RTT     time.Duration // The round-trip time of the probe.
+	Reached bool          // Whether the probe reached the target.
+	Error   string        // The ICMP error reported by the host, e.g. "destination host unreachable", which ends the trace.
+}
+
+// parseTraceMaxHops reads the maximum number of hops probed when tracing the path from TRACE_MAX_HOPS.
+func parseTraceMaxHops(getEnv func(string) string) (int, error) {
+	maxHopsStr := getEnv(envTraceMaxHops)
+	if maxHopsStr == "" {
+		return defaultTraceMaxHops, nil
+	}
+
+	maxHops, err := strconv.Atoi(maxHopsStr)
+	if err != nil || maxHops < 1 || maxHops > 255 {
+		return 0, fmt.Errorf("invalid %s value: %s", envTraceMaxHops, maxHopsStr)
+	}
+
+	return maxHops, nil
+}
+
+// traceHops calls probe with increasing TTLs until the target is reached, a host reports an error,
+// several consecutive hops stay silent or maxHops is exceeded.
+// The probe returns nil if the target answered, an icmpError if a host on the way answered,
+// and os.ErrDeadlineExceeded if nobody answered in time. Other errors end the trace.
+func traceHops(maxHops int, target net.IP, probe func(ttl int) (time.Duration, error)) ([]Hop, error) {
+	if maxHops < 1 {
+		maxHops = defaultTraceMaxHops
+	}
+
+	var hops []Hop
+	silent := 0
+	for ttl := 1; ttl <= maxHops; ttl++ {
+		rtt, err := probe(ttl)
+
+		hop := Hop{TTL: ttl}
+		var icmpErr *icmpError
+		switch {
+		case err == nil:
+			hop.Address, hop.RTT, hop.Reached = target.String(), rtt, true
+		case errors.As(err, &icmpErr):
+			hop.Address, hop.RTT = icmpErr.from, rtt
+			if !icmpErr.timeExceeded {
+				hop.Error = icmpErr.reason
+			}
+		case errors.Is(err, os.ErrDeadlineExceeded):
+			// No host answered in time
+		default:
+			return hops, err
+		}
+		hops = append(hops, hop)
+
+		if hop.Reached || hop.Error != "" {
+			break
+		}
+
+		if hop.Address != "" {
+			silent = 0
+			continue
+		}
+		if silent++; silent >= traceMaxSilentHops {
+			break
+		}
+	}
+
+	return hops, nil
+}
+
+// listenTrace opens the raw ICMP socket receiving the messages of the hosts answering the probes of a trace.
+// The socket listens on the source address and is bound to the source interface, if configured.
+func listenTrace(ctx context.Context, protocol Protocol, src source, ip net.IP) (net.PacketConn, error) {
+	listenAddress, err := src.listenAddress(ip)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := protocol.ListenPacket(ctx, protocol.Network(), listenAddress)
+	if errors.Is(err, os.ErrPermission) {
+		return nil, fmt.Errorf("tracing requires raw ICMP privileges: %w", err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for ICMP packets: %w", err)
+	}
+
+	return conn, nil
+}
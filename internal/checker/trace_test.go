@@ -0,0 +1,154 @@
+package checker
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestParseTraceMaxHops(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Default", func(t *testing.T) {
+		t.Parallel()
+
+		maxHops, err := parseTraceMaxHops(func(string) string { return "" })
+		if err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+		if maxHops != defaultTraceMaxHops {
+			t.Errorf("expected %d, got %d", defaultTraceMaxHops, maxHops)
+		}
+	})
+
+	t.Run("Invalid value", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := parseTraceMaxHops(func(string) string { return "256" })
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := fmt.Sprintf("invalid %s value: 256", envTraceMaxHops)
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+}
+
+func TestTraceHops(t *testing.T) {
+	t.Parallel()
+
+	target := net.IPv4(198, 51, 100, 1)
+
+	// router returns the answer of the router at the given hop
+	router := func(ttl int) error {
+		return &icmpError{reason: "time to live exceeded in transit", from: fmt.Sprintf("192.0.2.%d", ttl), timeExceeded: true}
+	}
+
+	tests := []struct {
+		name     string
+		maxHops  int
+		answers  map[int]error // The answer per TTL. Missing TTLs are not answered.
+		expected string        // The addresses of the hops, "*" if no host answered.
+	}{
+		{
+			name:     "Target reached",
+			maxHops:  30,
+			answers:  map[int]error{1: router(1), 2: router(2), 3: nil},
+			expected: "[192.0.2.1 192.0.2.2 198.51.100.1]",
+		},
+		{
+			name:     "Silent hops in between",
+			maxHops:  30,
+			answers:  map[int]error{1: router(1), 4: nil},
+			expected: "[192.0.2.1 * * 198.51.100.1]",
+		},
+		{
+			name:     "Path stops",
+			maxHops:  30,
+			answers:  map[int]error{1: router(1), 2: router(2)},
+			expected: "[192.0.2.1 192.0.2.2 * * *]",
+		},
+		{
+			name:    "Destination unreachable",
+			maxHops: 30,
+			answers: map[int]error{
+				1: router(1),
+				2: &icmpError{reason: "destination host unreachable", from: "192.0.2.2"},
+			},
+			expected: "[192.0.2.1 192.0.2.2]",
+		},
+		{
+			name:     "Maximum hops",
+			maxHops:  2,
+			answers:  map[int]error{1: router(1), 2: router(2), 3: nil},
+			expected: "[192.0.2.1 192.0.2.2]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			hops, err := traceHops(tt.maxHops, target, func(ttl int) (time.Duration, error) {
+				answer, ok := tt.answers[ttl]
+				if !ok {
+					return 0, os.ErrDeadlineExceeded
+				}
+				return time.Millisecond, answer
+			})
+			if err != nil {
+				t.Fatalf("expected no error, got %q", err)
+			}
+
+			addresses := make([]string, len(hops))
+			for i, hop := range hops {
+				addresses[i] = hop.Address
+				if hop.Address == "" {
+					addresses[i] = "*"
+				}
+			}
+			if fmt.Sprint(addresses) != tt.expected {
+				t.Errorf("expected hops %s, got %v", tt.expected, addresses)
+			}
+		})
+	}
+
+	t.Run("Reached and error are reported", func(t *testing.T) {
+		t.Parallel()
+
+		hops, _ := traceHops(30, target, func(ttl int) (time.Duration, error) {
+			return time.Millisecond, &icmpError{reason: "communication administratively prohibited", from: "192.0.2.1"}
+		})
+		if len(hops) != 1 || hops[0].Error != "communication administratively prohibited" || hops[0].Reached {
+			t.Errorf("unexpected hops: %+v", hops)
+		}
+
+		hops, _ = traceHops(30, target, func(ttl int) (time.Duration, error) {
+			return time.Millisecond, nil
+		})
+		if len(hops) != 1 || !hops[0].Reached || hops[0].RTT != time.Millisecond {
+			t.Errorf("unexpected hops: %+v", hops)
+		}
+	})
+
+	t.Run("Probe error", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := traceHops(30, target, func(ttl int) (time.Duration, error) {
+			return 0, errors.New("failed to set TTL: operation not permitted")
+		})
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := "failed to set TTL: operation not permitted"
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+}
@@ -0,0 +1,145 @@
+package checker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	envUDPPayload         string = "UDP_PAYLOAD"
+	envUDPPayloadEncoding string = "UDP_PAYLOAD_ENCODING"
+	envUDPExpect          string = "UDP_EXPECT"
+	envUDPReadTimeout     string = "UDP_READ_TIMEOUT"
+	envUDPAllowSilence    string = "UDP_ALLOW_SILENCE"
+
+	defaultUDPPayloadEncoding string        = payloadEncodingText
+	defaultUDPReadTimeout     time.Duration = 1 * time.Second
+)
+
+// UDPChecker implements the Checker interface for UDP request/response checks.
+type UDPChecker struct {
+	Name         string         // The name of the checker.
+	Address      string         // The address of the target.
+	Payload      []byte         // The payload to send to the target.
+	Expect       *regexp.Regexp // The pattern the reply must match. If nil, any reply is accepted.
+	ReadTimeout  time.Duration  // The timeout for reading the reply.
+	AllowSilence bool           // Whether the target is considered ready if it does not reply within the read timeout.
+	dialer       *net.Dialer    // The dialer to use for the connection.
+}
+
+// String returns the name of the checker.
+func (c *UDPChecker) String() string {
+	return c.Name
+}
+
+// NewUDPChecker creates a new UDPChecker.
+func NewUDPChecker(name, address string, timeout time.Duration, getEnv func(string) string) (Checker, error) {
+	// The "udp://" prefix is used to identify the check type and is not needed for further processing,
+	// so it must be removed before passing the address to other functions.
+	address = strings.TrimPrefix(address, "udp://")
+
+	// Connections originate from the configured source address and interface
+	dialer, err := newDialer("udp", timeout, getEnv)
+	if err != nil {
+		return nil, err
+	}
+
+	checker := UDPChecker{
+		Name:        name,
+		Address:     address,
+		ReadTimeout: defaultUDPReadTimeout,
+		dialer:      dialer,
+	}
+
+	// Decode the payload with the configured encoding
+	encoding := defaultUDPPayloadEncoding
+	if encodingStr := getEnv(envUDPPayloadEncoding); encodingStr != "" {
+		encoding = encodingStr
+	}
+	payload, err := parsePayload(getEnv(envUDPPayload), encoding)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s value: %w", envUDPPayload, err)
+	}
+	checker.Payload = payload
+
+	// Compile the expected reply pattern if specified
+	if expectStr := getEnv(envUDPExpect); expectStr != "" {
+		expect, err := regexp.Compile(expectStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value: %w", envUDPExpect, err)
+		}
+		checker.Expect = expect
+	}
+
+	// Determine the read timeout
+	if readTimeoutStr := getEnv(envUDPReadTimeout); readTimeoutStr != "" {
+		readTimeout, err := time.ParseDuration(readTimeoutStr)
+		if err != nil || readTimeout <= 0 {
+			return nil, fmt.Errorf("invalid %s value: %s", envUDPReadTimeout, readTimeoutStr)
+		}
+		checker.ReadTimeout = readTimeout
+	}
+
+	// Determine whether a silent target is considered ready
+	if allowSilenceStr := getEnv(envUDPAllowSilence); allowSilenceStr != "" {
+		allowSilence, err := strconv.ParseBool(allowSilenceStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value: %w", envUDPAllowSilence, err)
+		}
+		checker.AllowSilence = allowSilence
+	}
+
+	// A silent target cannot prove that it answers with the expected reply
+	if checker.AllowSilence && checker.Expect != nil {
+		return nil, fmt.Errorf("invalid %s value: silence cannot be allowed if %s is set", envUDPAllowSilence, envUDPExpect)
+	}
+
+	return &checker, nil
+}
+
+// Check sends the payload to the target and validates the reply.
+//
+// By default, the target must reply within the read timeout. If silence is allowed, the target is also
+// considered ready if it does not reply, unless the host reports the port as unreachable (ICMP port unreachable).
+func (c *UDPChecker) Check(ctx context.Context) error {
+	conn, err := c.dialer.DialContext(ctx, "udp", c.Address)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// Set the deadline for the whole exchange
+	deadline := time.Now().Add(c.ReadTimeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		return fmt.Errorf("failed to set deadline: %w", err)
+	}
+
+	if _, err := conn.Write(c.Payload); err != nil {
+		return fmt.Errorf("failed to send UDP payload to %s: %w", c.Address, err)
+	}
+
+	reply := make([]byte, 65535)
+	n, err := conn.Read(reply)
+	if err != nil {
+		var netErr net.Error
+		if c.AllowSilence && errors.As(err, &netErr) && netErr.Timeout() {
+			return nil // The target stayed silent, and no error was reported by the host
+		}
+		return fmt.Errorf("failed to read UDP reply from %s: %w", c.Address, err)
+	}
+
+	if c.Expect != nil && !c.Expect.Match(reply[:n]) {
+		return fmt.Errorf("unexpected UDP reply: %q does not match %q", reply[:n], c.Expect.String())
+	}
+
+	return nil
+}
@@ -0,0 +1,372 @@
+package checker
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// startUDPEchoServer starts a UDP server which replies with the given prefix followed by the received payload.
+func startUDPEchoServer(t *testing.T, prefix string) net.PacketConn {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to start UDP server: %q", err)
+	}
+
+	go func() {
+		buf := make([]byte, 1500)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			_, _ = conn.WriteTo(append([]byte(prefix), buf[:n]...), addr)
+		}
+	}()
+
+	return conn
+}
+
+func TestNewUDPChecker(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Valid UDP checker config", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envUDPPayload:         "de ad:be ef",
+				envUDPPayloadEncoding: "hex",
+				envUDPExpect:          "^PONG",
+				envUDPReadTimeout:     "3s",
+			}
+			return env[key]
+		}
+
+		checker, err := NewUDPChecker("example", "udp://localhost:53", 1*time.Second, mockEnv)
+		if err != nil {
+			t.Fatalf("failed to create UDPChecker: %q", err)
+		}
+
+		udpChecker := checker.(*UDPChecker)
+
+		expected := "localhost:53"
+		if udpChecker.Address != expected {
+			t.Errorf("expected Address to be %q, got %q", expected, udpChecker.Address)
+		}
+
+		expectedPayload := []byte{0xde, 0xad, 0xbe, 0xef}
+		if string(udpChecker.Payload) != string(expectedPayload) {
+			t.Errorf("expected Payload to be %v, got %v", expectedPayload, udpChecker.Payload)
+		}
+
+		if udpChecker.Expect.String() != "^PONG" {
+			t.Errorf("expected Expect to be %q, got %q", "^PONG", udpChecker.Expect.String())
+		}
+
+		if udpChecker.ReadTimeout != 3*time.Second {
+			t.Errorf("expected ReadTimeout to be 3s, got %v", udpChecker.ReadTimeout)
+		}
+	})
+
+	t.Run("Invalid payload", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envUDPPayload:         "zz",
+				envUDPPayloadEncoding: "hex",
+			}
+			return env[key]
+		}
+
+		_, err := NewUDPChecker("example", "localhost:53", 1*time.Second, mockEnv)
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := fmt.Sprintf("invalid %s value: invalid hex payload: encoding/hex: invalid byte: U+007A 'z'", envUDPPayload)
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Invalid expected pattern", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envUDPExpect: "(",
+			}
+			return env[key]
+		}
+
+		_, err := NewUDPChecker("example", "localhost:53", 1*time.Second, mockEnv)
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := fmt.Sprintf("invalid %s value: error parsing regexp: missing closing ): `(`", envUDPExpect)
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Invalid read timeout", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envUDPReadTimeout: "-1s",
+			}
+			return env[key]
+		}
+
+		_, err := NewUDPChecker("example", "localhost:53", 1*time.Second, mockEnv)
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := fmt.Sprintf("invalid %s value: -1s", envUDPReadTimeout)
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Invalid allow silence", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envUDPAllowSilence: "maybe",
+			}
+			return env[key]
+		}
+
+		_, err := NewUDPChecker("example", "localhost:53", 1*time.Second, mockEnv)
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := fmt.Sprintf("invalid %s value: strconv.ParseBool: parsing \"maybe\": invalid syntax", envUDPAllowSilence)
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Silence allowed with expected reply", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envUDPExpect:       "^PONG",
+				envUDPAllowSilence: "true",
+			}
+			return env[key]
+		}
+
+		_, err := NewUDPChecker("example", "localhost:53", 1*time.Second, mockEnv)
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := fmt.Sprintf("invalid %s value: silence cannot be allowed if %s is set", envUDPAllowSilence, envUDPExpect)
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+}
+
+func TestUDPChecker(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Valid UDP check with expected reply", func(t *testing.T) {
+		t.Parallel()
+
+		server := startUDPEchoServer(t, "PONG ")
+		defer server.Close()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envUDPPayload: `PING\n`,
+				envUDPExpect:  `^PONG PING\n$`,
+			}
+			return env[key]
+		}
+
+		checker, err := NewUDPChecker("example", server.LocalAddr().String(), 1*time.Second, mockEnv)
+		if err != nil {
+			t.Fatalf("failed to create UDPChecker: %q", err)
+		}
+
+		if err := checker.Check(context.Background()); err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+	})
+
+	t.Run("Unexpected UDP reply", func(t *testing.T) {
+		t.Parallel()
+
+		server := startUDPEchoServer(t, "ERR ")
+		defer server.Close()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envUDPPayload: "PING",
+				envUDPExpect:  "^PONG",
+			}
+			return env[key]
+		}
+
+		checker, err := NewUDPChecker("example", server.LocalAddr().String(), 1*time.Second, mockEnv)
+		if err != nil {
+			t.Fatalf("failed to create UDPChecker: %q", err)
+		}
+
+		err = checker.Check(context.Background())
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := `unexpected UDP reply: "ERR PING" does not match "^PONG"`
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Silent UDP target without expected reply", func(t *testing.T) {
+		t.Parallel()
+
+		server, err := net.ListenPacket("udp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("failed to start UDP server: %q", err)
+		}
+		defer server.Close()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envUDPPayload:     "<14>portpatrol",
+				envUDPReadTimeout: "100ms",
+			}
+			return env[key]
+		}
+
+		checker, err := NewUDPChecker("example", server.LocalAddr().String(), 1*time.Second, mockEnv)
+		if err != nil {
+			t.Fatalf("failed to create UDPChecker: %q", err)
+		}
+
+		err = checker.Check(context.Background())
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := "i/o timeout"
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected error containing %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Silent UDP target with silence allowed", func(t *testing.T) {
+		t.Parallel()
+
+		server, err := net.ListenPacket("udp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("failed to start UDP server: %q", err)
+		}
+		defer server.Close()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envUDPPayload:      "<14>portpatrol",
+				envUDPReadTimeout:  "100ms",
+				envUDPAllowSilence: "true",
+			}
+			return env[key]
+		}
+
+		checker, err := NewUDPChecker("example", server.LocalAddr().String(), 1*time.Second, mockEnv)
+		if err != nil {
+			t.Fatalf("failed to create UDPChecker: %q", err)
+		}
+
+		if err := checker.Check(context.Background()); err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+	})
+
+	t.Run("Silent UDP target with expected reply", func(t *testing.T) {
+		t.Parallel()
+
+		server, err := net.ListenPacket("udp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("failed to start UDP server: %q", err)
+		}
+		defer server.Close()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envUDPPayload:     "PING",
+				envUDPExpect:      "PONG",
+				envUDPReadTimeout: "100ms",
+			}
+			return env[key]
+		}
+
+		checker, err := NewUDPChecker("example", server.LocalAddr().String(), 1*time.Second, mockEnv)
+		if err != nil {
+			t.Fatalf("failed to create UDPChecker: %q", err)
+		}
+
+		err = checker.Check(context.Background())
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := "i/o timeout"
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected error containing %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Closed UDP port", func(t *testing.T) {
+		t.Parallel()
+
+		// Reserve a port and release it again, so nothing is listening on it
+		server, err := net.ListenPacket("udp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("failed to reserve UDP port: %q", err)
+		}
+		address := server.LocalAddr().String()
+		server.Close()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envUDPPayload:      "PING",
+				envUDPReadTimeout:  "500ms",
+				envUDPAllowSilence: "true",
+			}
+			return env[key]
+		}
+
+		checker, err := NewUDPChecker("example", address, 1*time.Second, mockEnv)
+		if err != nil {
+			t.Fatalf("failed to create UDPChecker: %q", err)
+		}
+
+		err = checker.Check(context.Background())
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := "connection refused"
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected error containing %q, got %q", expected, err.Error())
+		}
+	})
+}
@@ -0,0 +1,101 @@
+package checker
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/net/http2"
+)
+
+const (
+	envUnixHTTPPath string = "UNIX_HTTP_PATH"
+
+	unixHTTPHost string = "http://localhost" // The host used in HTTP requests sent over the socket.
+)
+
+// UnixChecker implements the Checker interface for Unix domain socket checks.
+type UnixChecker struct {
+	Name        string       // The name of the checker.
+	Address     string       // The path of the socket.
+	HTTPChecker *HTTPChecker // The HTTP checker used to speak HTTP over the socket. If nil, the socket is only dialed.
+	dialer      *net.Dialer  // The dialer to use for the connection.
+}
+
+// String returns the name of the checker.
+func (c *UnixChecker) String() string {
+	return c.Name
+}
+
+// NewUnixChecker creates a new UnixChecker.
+func NewUnixChecker(name, address string, timeout time.Duration, getEnv func(string) string) (Checker, error) {
+	// The "unix://" prefix is used to identify the check type and is not needed for further processing,
+	// so it must be removed before passing the address to other functions.
+	address = strings.TrimPrefix(address, "unix://")
+
+	// Connections to a socket have no source address and no TCP addresses to announce in a PROXY protocol header
+	for _, env := range []string{envSourceAddress, envSourceInterface, envProxyProtocol} {
+		if getEnv(env) != "" {
+			return nil, fmt.Errorf("invalid %s value: not supported for Unix sockets", env)
+		}
+	}
+
+	checker := UnixChecker{
+		Name:    name,
+		Address: address,
+		dialer: &net.Dialer{
+			Timeout: timeout,
+		},
+	}
+
+	// Speak HTTP over the socket if a request path is specified
+	if httpPath := getEnv(envUnixHTTPPath); httpPath != "" {
+		if !strings.HasPrefix(httpPath, "/") {
+			return nil, fmt.Errorf("invalid %s value: path must start with '/': %s", envUnixHTTPPath, httpPath)
+		}
+
+		httpChecker, err := NewHTTPChecker(name, unixHTTPHost+httpPath, timeout, getEnv)
+		if err != nil {
+			return nil, err
+		}
+
+		// Route every request to the socket, regardless of the host in the URL, and never use a proxy
+		dialSocket := func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return checker.dialer.DialContext(ctx, "unix", checker.Address)
+		}
+		switch transport := httpChecker.(*HTTPChecker).client.Transport.(type) {
+		case *http.Transport:
+			transport.Proxy = nil
+			transport.DialContext = dialSocket
+		case *http2.Transport:
+			transport.DialTLSContext = func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+				return dialSocket(ctx, network, addr)
+			}
+		default:
+			return nil, fmt.Errorf("invalid %s value: %s is not supported for Unix sockets", envHTTPVersion, getEnv(envHTTPVersion))
+		}
+
+		checker.HTTPChecker = httpChecker.(*HTTPChecker)
+	}
+
+	return &checker, nil
+}
+
+// Check dials the socket and, if configured, performs an HTTP request over it.
+func (c *UnixChecker) Check(ctx context.Context) error {
+	if c.HTTPChecker != nil {
+		return c.HTTPChecker.Check(ctx)
+	}
+
+	conn, err := c.dialer.DialContext(ctx, "unix", c.Address)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return nil
+}
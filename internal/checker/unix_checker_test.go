@@ -0,0 +1,333 @@
+package checker
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// unixSocketPath returns a path for a Unix socket in a short temporary directory,
+// since socket paths are limited to about 100 characters.
+func unixSocketPath(t *testing.T) string {
+	t.Helper()
+
+	dir, err := os.MkdirTemp("", "portpatrol")
+	if err != nil {
+		t.Fatalf("failed to create temporary directory: %q", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	return filepath.Join(dir, "app.sock")
+}
+
+func TestNewUnixChecker(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Valid Unix checker config", func(t *testing.T) {
+		t.Parallel()
+
+		checker, err := NewUnixChecker("example", "unix:///var/run/app.sock", 1*time.Second, func(string) string { return "" })
+		if err != nil {
+			t.Fatalf("failed to create UnixChecker: %q", err)
+		}
+
+		unixChecker := checker.(*UnixChecker)
+
+		expected := "/var/run/app.sock"
+		if unixChecker.Address != expected {
+			t.Errorf("expected Address to be %q, got %q", expected, unixChecker.Address)
+		}
+
+		if unixChecker.HTTPChecker != nil {
+			t.Error("expected HTTPChecker to be nil")
+		}
+	})
+
+	t.Run("Valid Unix checker config with HTTP", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envUnixHTTPPath:            "/v1.43/_ping",
+				envHTTPExpectedStatusCodes: "200-299",
+			}
+			return env[key]
+		}
+
+		checker, err := NewUnixChecker("example", "unix:///var/run/docker.sock", 1*time.Second, mockEnv)
+		if err != nil {
+			t.Fatalf("failed to create UnixChecker: %q", err)
+		}
+
+		unixChecker := checker.(*UnixChecker)
+		if unixChecker.HTTPChecker == nil {
+			t.Fatal("expected HTTPChecker to be set")
+		}
+
+		expected := "http://localhost/v1.43/_ping"
+		if unixChecker.HTTPChecker.Address != expected {
+			t.Errorf("expected HTTP address to be %q, got %q", expected, unixChecker.HTTPChecker.Address)
+		}
+
+		if len(unixChecker.HTTPChecker.ExpectedStatusCodes) != 100 {
+			t.Errorf("expected 100 expected status codes, got %d", len(unixChecker.HTTPChecker.ExpectedStatusCodes))
+		}
+	})
+
+	t.Run("Invalid HTTP path", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envUnixHTTPPath: "healthz",
+			}
+			return env[key]
+		}
+
+		_, err := NewUnixChecker("example", "unix:///var/run/app.sock", 1*time.Second, mockEnv)
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := fmt.Sprintf("invalid %s value: path must start with '/': healthz", envUnixHTTPPath)
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Invalid HTTP settings", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envUnixHTTPPath:            "/healthz",
+				envHTTPExpectedStatusCodes: "abc",
+			}
+			return env[key]
+		}
+
+		_, err := NewUnixChecker("example", "unix:///var/run/app.sock", 1*time.Second, mockEnv)
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := fmt.Sprintf("invalid %s value: invalid status code: abc", envHTTPExpectedStatusCodes)
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Unsupported connection settings", func(t *testing.T) {
+		t.Parallel()
+
+		for _, name := range []string{envSourceAddress, envSourceInterface, envProxyProtocol} {
+			mockEnv := func(key string) string {
+				env := map[string]string{
+					envUnixHTTPPath: "/healthz",
+					name:            "v1",
+				}
+				return env[key]
+			}
+
+			_, err := NewUnixChecker("example", "unix:///var/run/app.sock", 1*time.Second, mockEnv)
+			if err == nil {
+				t.Fatalf("expected an error for %s, got none", name)
+			}
+
+			expected := fmt.Sprintf("invalid %s value: not supported for Unix sockets", name)
+			if err.Error() != expected {
+				t.Errorf("expected error %q, got %q", expected, err.Error())
+			}
+		}
+	})
+
+	t.Run("HTTP/3 over Unix socket", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envUnixHTTPPath: "/healthz",
+				envHTTPVersion:  httpVersion3,
+			}
+			return env[key]
+		}
+
+		_, err := NewUnixChecker("example", "unix:///var/run/app.sock", 1*time.Second, mockEnv)
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := fmt.Sprintf("invalid %s value: HTTP/3 requires an https address", envHTTPVersion)
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+}
+
+func TestUnixChecker(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Valid Unix check", func(t *testing.T) {
+		t.Parallel()
+
+		path := unixSocketPath(t)
+		ln, err := net.Listen("unix", path)
+		if err != nil {
+			t.Fatalf("failed to listen on Unix socket: %q", err)
+		}
+		defer ln.Close()
+
+		checker, err := NewUnixChecker("example", "unix://"+path, 1*time.Second, func(string) string { return "" })
+		if err != nil {
+			t.Fatalf("failed to create UnixChecker: %q", err)
+		}
+
+		if err := checker.Check(context.Background()); err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+	})
+
+	t.Run("Missing socket", func(t *testing.T) {
+		t.Parallel()
+
+		path := unixSocketPath(t)
+
+		checker, err := NewUnixChecker("example", "unix://"+path, 1*time.Second, func(string) string { return "" })
+		if err != nil {
+			t.Fatalf("failed to create UnixChecker: %q", err)
+		}
+
+		err = checker.Check(context.Background())
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := fmt.Sprintf("dial unix %s: connect: no such file or directory", path)
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Valid HTTP over Unix check", func(t *testing.T) {
+		t.Parallel()
+
+		path := unixSocketPath(t)
+		ln, err := net.Listen("unix", path)
+		if err != nil {
+			t.Fatalf("failed to listen on Unix socket: %q", err)
+		}
+
+		server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/healthz" || r.Header.Get("X-Check") != "portpatrol" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		})}
+		go func() { _ = server.Serve(ln) }()
+		defer server.Close()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envUnixHTTPPath:            "/healthz",
+				envHTTPHeaders:             "X-Check=portpatrol",
+				envHTTPExpectedStatusCodes: "204",
+			}
+			return env[key]
+		}
+
+		checker, err := NewUnixChecker("example", "unix://"+path, 1*time.Second, mockEnv)
+		if err != nil {
+			t.Fatalf("failed to create UnixChecker: %q", err)
+		}
+
+		if err := checker.Check(context.Background()); err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+	})
+
+	t.Run("h2c over Unix socket", func(t *testing.T) {
+		t.Parallel()
+
+		path := unixSocketPath(t)
+		ln, err := net.Listen("unix", path)
+		if err != nil {
+			t.Fatalf("failed to listen on Unix socket: %q", err)
+		}
+
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ProtoMajor != 2 {
+				w.WriteHeader(http.StatusHTTPVersionNotSupported)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		})
+		server := &http.Server{Handler: h2c.NewHandler(handler, &http2.Server{})}
+		go func() { _ = server.Serve(ln) }()
+		defer server.Close()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envUnixHTTPPath: "/healthz",
+				envHTTPVersion:  httpVersionH2C,
+			}
+			return env[key]
+		}
+
+		checker, err := NewUnixChecker("example", "unix://"+path, 1*time.Second, mockEnv)
+		if err != nil {
+			t.Fatalf("failed to create UnixChecker: %q", err)
+		}
+
+		if err := checker.Check(context.Background()); err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+	})
+
+	t.Run("Unexpected status code over Unix socket", func(t *testing.T) {
+		t.Parallel()
+
+		path := unixSocketPath(t)
+		ln, err := net.Listen("unix", path)
+		if err != nil {
+			t.Fatalf("failed to listen on Unix socket: %q", err)
+		}
+
+		server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		})}
+		go func() { _ = server.Serve(ln) }()
+		defer server.Close()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envUnixHTTPPath: "/healthz",
+			}
+			return env[key]
+		}
+
+		checker, err := NewUnixChecker("example", "unix://"+path, 1*time.Second, mockEnv)
+		if err != nil {
+			t.Fatalf("failed to create UnixChecker: %q", err)
+		}
+
+		err = checker.Check(context.Background())
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := "unexpected status code: got 503, expected one of [200]"
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected error containing %q, got %q", expected, err.Error())
+		}
+	})
+}
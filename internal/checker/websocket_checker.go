@@ -0,0 +1,421 @@
+package checker
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/containeroo/portpatrol/pkg/httputils"
+)
+
+const (
+	envWebSocketHeaders       string = "WS_HEADERS"
+	envWebSocketSkipTLSVerify string = "WS_SKIP_TLS_VERIFY"
+	envWebSocketSend          string = "WS_SEND"
+	envWebSocketSendEncoding  string = "WS_SEND_ENCODING"
+	envWebSocketExpect        string = "WS_EXPECT"
+	envWebSocketReadTimeout   string = "WS_READ_TIMEOUT"
+
+	defaultWebSocketSkipTLSVerify bool          = false
+	defaultWebSocketSendEncoding  string        = payloadEncodingText
+	defaultWebSocketReadTimeout   time.Duration = 1 * time.Second
+
+	webSocketGUID           string = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11" // The GUID used to compute Sec-WebSocket-Accept (RFC 6455).
+	webSocketMaxMessageSize int    = 64 * 1024                              // The maximum size of a message read while waiting for the expected reply.
+
+	webSocketOpContinuation byte = 0x0
+	webSocketOpText         byte = 0x1
+	webSocketOpBinary       byte = 0x2
+	webSocketOpClose        byte = 0x8
+	webSocketOpPing         byte = 0x9
+	webSocketOpPong         byte = 0xa
+)
+
+// WebSocketChecker implements the Checker interface for WebSocket checks.
+type WebSocketChecker struct {
+	Name        string            // The name of the checker.
+	Address     string            // The URL of the target.
+	Headers     map[string]string // The HTTP headers to include in the upgrade request.
+	Send        []byte            // The message to send after the handshake. If empty, nothing is sent.
+	SendOpcode  byte              // The opcode of the message to send (text or binary).
+	Expect      *regexp.Regexp    // The pattern a received message must match. If nil, no message is read.
+	ReadTimeout time.Duration     // The timeout for sending the message and reading the reply.
+	url         *url.URL          // The parsed URL of the target.
+	tlsConfig   *tls.Config       // The TLS configuration for wss:// targets.
+	dialer      contextDialer     // The dialer to use for the connection.
+	timeout     time.Duration     // The timeout for the TLS and upgrade handshakes.
+}
+
+// String returns the name of the checker.
+func (c *WebSocketChecker) String() string {
+	return c.Name
+}
+
+// NewWebSocketChecker creates a new WebSocketChecker.
+func NewWebSocketChecker(name, address string, timeout time.Duration, getEnv func(string) string) (Checker, error) {
+	// Without a scheme, the target is reached via ws://
+	if !strings.Contains(address, "://") {
+		address = "ws://" + address
+	}
+
+	u, err := url.Parse(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address %s: %w", address, err)
+	}
+	if u.Scheme != "ws" && u.Scheme != "wss" {
+		return nil, fmt.Errorf("invalid address %s: unsupported scheme %q", address, u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("invalid address %s: missing host", address)
+	}
+
+	// Connections originate from the configured source address and interface and go through the configured proxy
+	dialer, err := newProxyDialer(timeout, getEnv)
+	if err != nil {
+		return nil, err
+	}
+
+	checker := WebSocketChecker{
+		Name:        name,
+		Address:     address,
+		SendOpcode:  webSocketOpText,
+		ReadTimeout: defaultWebSocketReadTimeout,
+		url:         u,
+		dialer:      dialer,
+		timeout:     timeout,
+	}
+
+	// Parse the headers of the upgrade request, e.g. for authentication
+	headers, err := httputils.ParseHeaders(getEnv(envWebSocketHeaders), false)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s value: %w", envWebSocketHeaders, err)
+	}
+	checker.Headers = headers
+
+	// Determine if TLS verification should be skipped
+	skipTLSVerify := defaultWebSocketSkipTLSVerify
+	if skipTLSVerifyStr := getEnv(envWebSocketSkipTLSVerify); skipTLSVerifyStr != "" {
+		skipTLSVerify, err = strconv.ParseBool(skipTLSVerifyStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value: %w", envWebSocketSkipTLSVerify, err)
+		}
+	}
+	checker.tlsConfig = &tls.Config{
+		ServerName:         u.Hostname(),
+		InsecureSkipVerify: skipTLSVerify,
+	}
+
+	// Decode the message to send after the handshake. Hex payloads are sent as binary messages.
+	encoding := defaultWebSocketSendEncoding
+	if encodingStr := getEnv(envWebSocketSendEncoding); encodingStr != "" {
+		encoding = encodingStr
+	}
+	send, err := parsePayload(getEnv(envWebSocketSend), encoding)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s value: %w", envWebSocketSend, err)
+	}
+	checker.Send = send
+	if strings.EqualFold(encoding, payloadEncodingHex) {
+		checker.SendOpcode = webSocketOpBinary
+	}
+
+	// Compile the expected reply pattern if specified
+	if expectStr := getEnv(envWebSocketExpect); expectStr != "" {
+		expect, err := regexp.Compile(expectStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value: %w", envWebSocketExpect, err)
+		}
+		checker.Expect = expect
+	}
+
+	// Determine the read timeout
+	if readTimeoutStr := getEnv(envWebSocketReadTimeout); readTimeoutStr != "" {
+		readTimeout, err := time.ParseDuration(readTimeoutStr)
+		if err != nil || readTimeout <= 0 {
+			return nil, fmt.Errorf("invalid %s value: %s", envWebSocketReadTimeout, readTimeoutStr)
+		}
+		checker.ReadTimeout = readTimeout
+	}
+
+	return &checker, nil
+}
+
+// Check performs the WebSocket handshake and, if configured, sends a message and waits for a matching reply.
+func (c *WebSocketChecker) Check(ctx context.Context) error {
+	host := c.url.Host
+	if c.url.Port() == "" {
+		port := "80"
+		if c.url.Scheme == "wss" {
+			port = "443"
+		}
+		host = net.JoinHostPort(c.url.Hostname(), port)
+	}
+
+	conn, err := c.dialer.DialContext(ctx, "tcp", host)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// Bound the TLS and upgrade handshakes, so a silent server does not block the check
+	stop, err := deadlineConn(ctx, conn, c.timeout)
+	if err != nil {
+		return err
+	}
+	defer stop()
+
+	if c.url.Scheme == "wss" {
+		tlsConn := tls.Client(conn, c.tlsConfig)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			return fmt.Errorf("TLS handshake failed: %w", err)
+		}
+		conn = tlsConn
+	}
+
+	br := bufio.NewReader(conn)
+	if err := c.handshake(conn, br); err != nil {
+		return err
+	}
+
+	if len(c.Send) == 0 && c.Expect == nil {
+		_ = writeWebSocketFrame(conn, webSocketOpClose, binary.BigEndian.AppendUint16(nil, 1000), true)
+		return nil
+	}
+
+	// Set the deadline for the message exchange
+	deadline := time.Now().Add(c.ReadTimeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		return fmt.Errorf("failed to set deadline: %w", err)
+	}
+
+	if len(c.Send) > 0 {
+		if err := writeWebSocketFrame(conn, c.SendOpcode, c.Send, true); err != nil {
+			return fmt.Errorf("failed to send message: %w", err)
+		}
+	}
+
+	if c.Expect != nil {
+		if err := c.expectMessage(conn, br); err != nil {
+			return err
+		}
+	}
+
+	_ = writeWebSocketFrame(conn, webSocketOpClose, binary.BigEndian.AppendUint16(nil, 1000), true)
+	return nil
+}
+
+// handshake sends the upgrade request and validates the response of the server.
+func (c *WebSocketChecker) handshake(conn net.Conn, br *bufio.Reader) error {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("failed to generate key: %w", err)
+	}
+	key := base64.StdEncoding.EncodeToString(nonce)
+
+	req, err := http.NewRequest(http.MethodGet, "http://"+c.url.Host+c.url.RequestURI(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	for name, value := range c.Headers {
+		req.Header.Set(name, value)
+	}
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Sec-WebSocket-Key", key)
+	req.Header.Set("Sec-WebSocket-Version", "13")
+
+	if err := req.Write(conn); err != nil {
+		return fmt.Errorf("failed to send upgrade request: %w", err)
+	}
+
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		return fmt.Errorf("failed to read upgrade response: %w", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		return fmt.Errorf("unexpected status code: got %d, expected %d", resp.StatusCode, http.StatusSwitchingProtocols)
+	}
+	if !strings.EqualFold(resp.Header.Get("Upgrade"), "websocket") {
+		return fmt.Errorf("unexpected Upgrade header: %q", resp.Header.Get("Upgrade"))
+	}
+	if accept := resp.Header.Get("Sec-WebSocket-Accept"); accept != webSocketAccept(key) {
+		return fmt.Errorf("invalid Sec-WebSocket-Accept header: %q", accept)
+	}
+
+	return nil
+}
+
+// expectMessage reads messages until one matches the expected pattern, the connection is closed or the deadline is reached.
+// Pings are answered, so servers do not consider the connection dead while waiting.
+func (c *WebSocketChecker) expectMessage(conn net.Conn, br *bufio.Reader) error {
+	var last []byte
+
+	for {
+		message, err := readWebSocketMessage(conn, br)
+		if err != nil {
+			if last != nil {
+				return fmt.Errorf("expected message matching %q, got %q: %w", c.Expect.String(), last, err)
+			}
+			return fmt.Errorf("expected message matching %q: %w", c.Expect.String(), err)
+		}
+
+		if c.Expect.Match(message) {
+			return nil
+		}
+		last = message
+	}
+}
+
+// webSocketAccept computes the expected Sec-WebSocket-Accept value for the given key.
+func webSocketAccept(key string) string {
+	sum := sha1.Sum([]byte(key + webSocketGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// readWebSocketMessage reads a complete (possibly fragmented) data message.
+// Ping frames are answered with pong frames and close frames are reported as an error.
+func readWebSocketMessage(conn net.Conn, br *bufio.Reader) ([]byte, error) {
+	var message []byte
+
+	for {
+		fin, opcode, payload, err := readWebSocketFrame(br)
+		if err != nil {
+			return nil, err
+		}
+
+		switch opcode {
+		case webSocketOpPing:
+			if err := writeWebSocketFrame(conn, webSocketOpPong, payload, true); err != nil {
+				return nil, err
+			}
+			continue
+		case webSocketOpPong:
+			continue
+		case webSocketOpClose:
+			if len(payload) >= 2 {
+				return nil, fmt.Errorf("connection closed by server: %d %s", binary.BigEndian.Uint16(payload), payload[2:])
+			}
+			return nil, errors.New("connection closed by server")
+		case webSocketOpText, webSocketOpBinary, webSocketOpContinuation:
+			message = append(message, payload...)
+		default:
+			return nil, fmt.Errorf("unexpected opcode 0x%x", opcode)
+		}
+
+		if len(message) > webSocketMaxMessageSize {
+			return nil, fmt.Errorf("message exceeds %d bytes", webSocketMaxMessageSize)
+		}
+		if fin {
+			return message, nil
+		}
+	}
+}
+
+// readWebSocketFrame reads a single frame and returns its payload, unmasked if necessary.
+func readWebSocketFrame(r io.Reader) (fin bool, opcode byte, payload []byte, err error) {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return false, 0, nil, err
+	}
+
+	fin = header[0]&0x80 != 0
+	opcode = header[0] & 0x0f
+	masked := header[1]&0x80 != 0
+	length := uint64(header[1] & 0x7f)
+
+	switch length {
+	case 126:
+		ext := make([]byte, 2)
+		if _, err := io.ReadFull(r, ext); err != nil {
+			return false, 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext))
+	case 127:
+		ext := make([]byte, 8)
+		if _, err := io.ReadFull(r, ext); err != nil {
+			return false, 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(ext)
+	}
+
+	if length > uint64(webSocketMaxMessageSize) {
+		return false, 0, nil, fmt.Errorf("frame exceeds %d bytes", webSocketMaxMessageSize)
+	}
+
+	var mask []byte
+	if masked {
+		mask = make([]byte, 4)
+		if _, err := io.ReadFull(r, mask); err != nil {
+			return false, 0, nil, err
+		}
+	}
+
+	payload = make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return false, 0, nil, err
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+
+	return fin, opcode, payload, nil
+}
+
+// writeWebSocketFrame writes a single final frame. Clients must mask their frames, servers must not.
+func writeWebSocketFrame(w io.Writer, opcode byte, payload []byte, masked bool) error {
+	frame := []byte{0x80 | opcode}
+
+	var maskBit byte
+	if masked {
+		maskBit = 0x80
+	}
+
+	switch length := len(payload); {
+	case length < 126:
+		frame = append(frame, maskBit|byte(length))
+	case length <= 0xffff:
+		frame = append(frame, maskBit|126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(length))
+	default:
+		frame = append(frame, maskBit|127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(length))
+	}
+
+	if !masked {
+		_, err := w.Write(append(frame, payload...))
+		return err
+	}
+
+	mask := make([]byte, 4)
+	if _, err := rand.Read(mask); err != nil {
+		return err
+	}
+	frame = append(frame, mask...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+
+	_, err := w.Write(frame)
+	return err
+}
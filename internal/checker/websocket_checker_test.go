@@ -0,0 +1,335 @@
+package checker
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// startFakeWebSocketServer starts an HTTP server which accepts WebSocket upgrades on "/socket"
+// and hands the upgraded connection to the given function.
+func startFakeWebSocketServer(t *testing.T, secure bool, handle func(conn net.Conn, br *bufio.Reader)) *httptest.Server {
+	t.Helper()
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/socket" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Header.Get("Authorization") != "" && r.Header.Get("Authorization") != "Bearer s3cr3t" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") || r.Header.Get("Sec-WebSocket-Version") != "13" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		fmt.Fprintf(rw, "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: %s\r\n\r\n", webSocketAccept(r.Header.Get("Sec-WebSocket-Key")))
+		if err := rw.Flush(); err != nil {
+			return
+		}
+
+		if handle != nil {
+			handle(conn, rw.Reader)
+		}
+	})
+
+	if secure {
+		return httptest.NewTLSServer(handler)
+	}
+	return httptest.NewServer(handler)
+}
+
+// webSocketEcho answers every data message with the same message, prefixed with "echo: ".
+func webSocketEcho(conn net.Conn, br *bufio.Reader) {
+	for {
+		_, opcode, payload, err := readWebSocketFrame(br)
+		if err != nil || opcode == webSocketOpClose {
+			return
+		}
+		if err := writeWebSocketFrame(conn, opcode, append([]byte("echo: "), payload...), false); err != nil {
+			return
+		}
+	}
+}
+
+func TestNewWebSocketChecker(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Valid WebSocket checker config", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envWebSocketHeaders:      "Authorization=Bearer s3cr3t",
+				envWebSocketSend:         "de:ad:be:ef",
+				envWebSocketSendEncoding: "hex",
+				envWebSocketExpect:       "pong",
+				envWebSocketReadTimeout:  "5s",
+			}
+			return env[key]
+		}
+
+		checker, err := NewWebSocketChecker("example", "wss://example.com/socket", 1*time.Second, mockEnv)
+		if err != nil {
+			t.Fatalf("failed to create WebSocketChecker: %q", err)
+		}
+
+		wsChecker := checker.(*WebSocketChecker)
+
+		if wsChecker.Headers["Authorization"] != "Bearer s3cr3t" {
+			t.Errorf("expected Authorization header to be %q, got %q", "Bearer s3cr3t", wsChecker.Headers["Authorization"])
+		}
+
+		if string(wsChecker.Send) != "\xde\xad\xbe\xef" || wsChecker.SendOpcode != webSocketOpBinary {
+			t.Errorf("expected a binary message, got %q with opcode %d", wsChecker.Send, wsChecker.SendOpcode)
+		}
+
+		if wsChecker.ReadTimeout != 5*time.Second {
+			t.Errorf("expected ReadTimeout to be 5s, got %v", wsChecker.ReadTimeout)
+		}
+	})
+
+	t.Run("Address without scheme", func(t *testing.T) {
+		t.Parallel()
+
+		checker, err := NewWebSocketChecker("example", "example.com:8080/socket", 1*time.Second, func(string) string { return "" })
+		if err != nil {
+			t.Fatalf("failed to create WebSocketChecker: %q", err)
+		}
+
+		expected := "ws://example.com:8080/socket"
+		if address := checker.(*WebSocketChecker).Address; address != expected {
+			t.Errorf("expected Address to be %q, got %q", expected, address)
+		}
+	})
+
+	t.Run("Unsupported scheme", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := NewWebSocketChecker("example", "http://example.com/socket", 1*time.Second, func(string) string { return "" })
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := `invalid address http://example.com/socket: unsupported scheme "http"`
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Invalid WS_EXPECT", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envWebSocketExpect: "[",
+			}
+			return env[key]
+		}
+
+		_, err := NewWebSocketChecker("example", "ws://example.com/socket", 1*time.Second, mockEnv)
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := fmt.Sprintf("invalid %s value: error parsing regexp: missing closing ]: `[`", envWebSocketExpect)
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Invalid WS_READ_TIMEOUT", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envWebSocketReadTimeout: "0s",
+			}
+			return env[key]
+		}
+
+		_, err := NewWebSocketChecker("example", "ws://example.com/socket", 1*time.Second, mockEnv)
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := fmt.Sprintf("invalid %s value: 0s", envWebSocketReadTimeout)
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+}
+
+func TestWebSocketChecker(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Valid WebSocket check", func(t *testing.T) {
+		t.Parallel()
+
+		server := startFakeWebSocketServer(t, false, nil)
+		defer server.Close()
+
+		checker, err := NewWebSocketChecker("example", "ws://"+server.Listener.Addr().String()+"/socket", 1*time.Second, func(string) string { return "" })
+		if err != nil {
+			t.Fatalf("failed to create WebSocketChecker: %q", err)
+		}
+
+		if err := checker.Check(context.Background()); err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+	})
+
+	t.Run("Valid secure WebSocket check with echo", func(t *testing.T) {
+		t.Parallel()
+
+		server := startFakeWebSocketServer(t, true, webSocketEcho)
+		defer server.Close()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envWebSocketSkipTLSVerify: "true",
+				envWebSocketHeaders:       "Authorization=Bearer s3cr3t",
+				envWebSocketSend:          `{"type":"ping"}`,
+				envWebSocketExpect:        `^echo: \{"type":"ping"\}$`,
+			}
+			return env[key]
+		}
+
+		checker, err := NewWebSocketChecker("example", "wss://"+server.Listener.Addr().String()+"/socket", 1*time.Second, mockEnv)
+		if err != nil {
+			t.Fatalf("failed to create WebSocketChecker: %q", err)
+		}
+
+		if err := checker.Check(context.Background()); err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+	})
+
+	t.Run("Fragmented reply after ping", func(t *testing.T) {
+		t.Parallel()
+
+		server := startFakeWebSocketServer(t, false, func(conn net.Conn, br *bufio.Reader) {
+			_ = writeWebSocketFrame(conn, webSocketOpPing, []byte("hi"), false)
+			if _, opcode, _, err := readWebSocketFrame(br); err != nil || opcode != webSocketOpPong {
+				return
+			}
+			_ = writeWebSocketFrame(conn, webSocketOpText, []byte("welcome"), false)
+			_, _ = conn.Write([]byte{webSocketOpText, 3, 'r', 'e', 'a'}) // first fragment without FIN bit
+			_ = writeWebSocketFrame(conn, webSocketOpContinuation, []byte("dy"), false)
+			_, _, _, _ = readWebSocketFrame(br)
+		})
+		defer server.Close()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envWebSocketExpect: "^ready$",
+			}
+			return env[key]
+		}
+
+		checker, err := NewWebSocketChecker("example", "ws://"+server.Listener.Addr().String()+"/socket", 1*time.Second, mockEnv)
+		if err != nil {
+			t.Fatalf("failed to create WebSocketChecker: %q", err)
+		}
+
+		if err := checker.Check(context.Background()); err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+	})
+
+	t.Run("Server closes connection", func(t *testing.T) {
+		t.Parallel()
+
+		server := startFakeWebSocketServer(t, false, func(conn net.Conn, br *bufio.Reader) {
+			_ = writeWebSocketFrame(conn, webSocketOpText, []byte("starting"), false)
+			_ = writeWebSocketFrame(conn, webSocketOpClose, append(binary.BigEndian.AppendUint16(nil, 1013), "try again later"...), false)
+		})
+		defer server.Close()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envWebSocketExpect: "ready",
+			}
+			return env[key]
+		}
+
+		checker, err := NewWebSocketChecker("example", "ws://"+server.Listener.Addr().String()+"/socket", 1*time.Second, mockEnv)
+		if err != nil {
+			t.Fatalf("failed to create WebSocketChecker: %q", err)
+		}
+
+		err = checker.Check(context.Background())
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := `expected message matching "ready", got "starting": connection closed by server: 1013 try again later`
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Upgrade rejected", func(t *testing.T) {
+		t.Parallel()
+
+		server := startFakeWebSocketServer(t, false, nil)
+		defer server.Close()
+
+		checker, err := NewWebSocketChecker("example", "ws://"+server.Listener.Addr().String()+"/missing", 1*time.Second, func(string) string { return "" })
+		if err != nil {
+			t.Fatalf("failed to create WebSocketChecker: %q", err)
+		}
+
+		err = checker.Check(context.Background())
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := "unexpected status code: got 404, expected 101"
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+
+	t.Run("Invalid Sec-WebSocket-Accept", func(t *testing.T) {
+		t.Parallel()
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Upgrade", "websocket")
+			w.Header().Set("Connection", "Upgrade")
+			w.Header().Set("Sec-WebSocket-Accept", "invalid")
+			w.WriteHeader(http.StatusSwitchingProtocols)
+		}))
+		defer server.Close()
+
+		checker, err := NewWebSocketChecker("example", "ws://"+server.Listener.Addr().String()+"/socket", 1*time.Second, func(string) string { return "" })
+		if err != nil {
+			t.Fatalf("failed to create WebSocketChecker: %q", err)
+		}
+
+		err = checker.Check(context.Background())
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := `invalid Sec-WebSocket-Accept header: "invalid"`
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+}
@@ -0,0 +1,144 @@
+package checker
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	envZooKeeperModes string = "ZOOKEEPER_MODES"
+
+	zooKeeperMaxResponseSize int64 = 64 * 1024 // The maximum size of a response to a four letter word.
+)
+
+var defaultZooKeeperModes = []string{"leader", "follower", "standalone"} // Slice cannot be consts
+
+// ZooKeeperChecker implements the Checker interface for ZooKeeper checks.
+type ZooKeeperChecker struct {
+	Name    string        // The name of the checker.
+	Address string        // The address of the target.
+	Modes   []string      // The accepted server modes (e.g. leader, follower).
+	dialer  contextDialer // The dialer to use for the connection.
+	timeout time.Duration // The timeout for the whole exchange with the server.
+}
+
+// String returns the name of the checker.
+func (c *ZooKeeperChecker) String() string {
+	return c.Name
+}
+
+// NewZooKeeperChecker creates a new ZooKeeperChecker.
+func NewZooKeeperChecker(name, address string, timeout time.Duration, getEnv func(string) string) (Checker, error) {
+	// The "zookeeper://" and "zk://" prefixes are used to identify the check type and are not needed for further processing,
+	// so they must be removed before passing the address to other functions.
+	address = strings.TrimPrefix(strings.TrimPrefix(address, "zookeeper://"), "zk://")
+
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		return nil, fmt.Errorf("invalid address %s: %w", address, err)
+	}
+
+	// Connections originate from the configured source address and interface and go through the configured proxy
+	dialer, err := newProxyDialer(timeout, getEnv)
+	if err != nil {
+		return nil, err
+	}
+
+	checker := ZooKeeperChecker{
+		Name:    name,
+		Address: address,
+		Modes:   defaultZooKeeperModes,
+		dialer:  dialer,
+		timeout: timeout,
+	}
+
+	// Override the accepted server modes if specified
+	if modesStr := getEnv(envZooKeeperModes); modesStr != "" {
+		checker.Modes = nil
+		for _, mode := range strings.Split(modesStr, ",") {
+			mode = strings.ToLower(strings.TrimSpace(mode))
+			switch mode {
+			case "leader", "follower", "observer", "standalone":
+				checker.Modes = append(checker.Modes, mode)
+			default:
+				return nil, fmt.Errorf("invalid %s value: unsupported mode %q", envZooKeeperModes, mode)
+			}
+		}
+	}
+
+	return &checker, nil
+}
+
+// Check sends "ruok" and expects "imok", then sends "mntr" to verify the server mode.
+// A server which is not part of a quorum still answers "imok", but does not report a mode.
+func (c *ZooKeeperChecker) Check(ctx context.Context) error {
+	reply, err := c.command(ctx, "ruok")
+	if err != nil {
+		return err
+	}
+	// Servers answer with an explanation if the command is not in 4lw.commands.whitelist
+	if reply != "imok" {
+		return fmt.Errorf("unexpected reply to ruok: %q", reply)
+	}
+
+	reply, err = c.command(ctx, "mntr")
+	if err != nil {
+		return err
+	}
+
+	mode, err := zooKeeperServerState(reply)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(c.Modes, mode) {
+		return fmt.Errorf("server is %s, expected %s", mode, strings.Join(c.Modes, " or "))
+	}
+
+	return nil
+}
+
+// command sends a four letter word on a new connection and returns the trimmed reply.
+// The server closes the connection after replying.
+func (c *ZooKeeperChecker) command(ctx context.Context, word string) (string, error) {
+	conn, err := c.dialer.DialContext(ctx, "tcp", c.Address)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	// The server must reply within the timeout
+	stop, err := deadlineConn(ctx, conn, c.timeout)
+	if err != nil {
+		return "", err
+	}
+	defer stop()
+
+	if _, err := io.WriteString(conn, word); err != nil {
+		return "", fmt.Errorf("failed to send %s: %w", word, err)
+	}
+
+	reply, err := io.ReadAll(io.LimitReader(conn, zooKeeperMaxResponseSize))
+	if err != nil {
+		return "", fmt.Errorf("failed to read reply to %s: %w", word, err)
+	}
+
+	return strings.TrimSpace(string(reply)), nil
+}
+
+// zooKeeperServerState extracts zk_server_state from the reply to "mntr".
+func zooKeeperServerState(reply string) (string, error) {
+	scanner := bufio.NewScanner(strings.NewReader(reply))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), "\t")
+		if ok && key == "zk_server_state" {
+			return strings.TrimSpace(value), nil
+		}
+	}
+
+	return "", fmt.Errorf("unexpected reply to mntr: %q", reply)
+}
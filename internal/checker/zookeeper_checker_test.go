@@ -0,0 +1,178 @@
+package checker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// startFakeZooKeeperServer starts a TCP server answering four letter words with the given replies.
+// Like ZooKeeper, the server closes the connection after replying.
+func startFakeZooKeeperServer(t *testing.T, replies map[string]string) net.Listener {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to start fake ZooKeeper server: %q", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+
+				word := make([]byte, 4)
+				if _, err := io.ReadFull(conn, word); err != nil {
+					return
+				}
+				reply, ok := replies[string(word)]
+				if !ok {
+					reply = fmt.Sprintf("%s is not executed because it is not in the whitelist.\n", word)
+				}
+				_, _ = io.WriteString(conn, reply)
+			}()
+		}
+	}()
+
+	return ln
+}
+
+func TestNewZooKeeperChecker(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Valid ZooKeeper checker config", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envZooKeeperModes: "Leader, follower",
+			}
+			return env[key]
+		}
+
+		checker, err := NewZooKeeperChecker("example", "zk://zk.example.com:2181", 1*time.Second, mockEnv)
+		if err != nil {
+			t.Fatalf("failed to create ZooKeeperChecker: %q", err)
+		}
+
+		zkChecker := checker.(*ZooKeeperChecker)
+
+		expected := "zk.example.com:2181"
+		if zkChecker.Address != expected {
+			t.Errorf("expected Address to be %q, got %q", expected, zkChecker.Address)
+		}
+
+		if fmt.Sprint(zkChecker.Modes) != "[leader follower]" {
+			t.Errorf("expected Modes to be [leader follower], got %v", zkChecker.Modes)
+		}
+	})
+
+	t.Run("Invalid ZOOKEEPER_MODES", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envZooKeeperModes: "leader,primary",
+			}
+			return env[key]
+		}
+
+		_, err := NewZooKeeperChecker("example", "zookeeper://zk.example.com:2181", 1*time.Second, mockEnv)
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := fmt.Sprintf("invalid %s value: unsupported mode \"primary\"", envZooKeeperModes)
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+}
+
+func TestZooKeeperChecker(t *testing.T) {
+	t.Parallel()
+
+	mntr := "zk_version\t3.8.4-9316c2a7a97e1666d8f4593f34dd6fc36ecc436c\nzk_server_state\tfollower\nzk_znode_count\t5\n"
+
+	tests := []struct {
+		name     string
+		replies  map[string]string
+		modes    string
+		expected string
+	}{
+		{
+			name:    "Server is in a default mode",
+			replies: map[string]string{"ruok": "imok", "mntr": mntr},
+		},
+		{
+			name:     "Observer is not accepted by default",
+			replies:  map[string]string{"ruok": "imok", "mntr": strings.Replace(mntr, "follower", "observer", 1)},
+			expected: "server is observer, expected leader or follower or standalone",
+		},
+		{
+			name:    "Server is in an accepted mode",
+			replies: map[string]string{"ruok": "imok", "mntr": mntr},
+			modes:   "leader,follower",
+		},
+		{
+			name:     "Server is in another mode",
+			replies:  map[string]string{"ruok": "imok", "mntr": mntr},
+			modes:    "leader",
+			expected: "server is follower, expected leader",
+		},
+		{
+			name:     "ruok not whitelisted",
+			replies:  map[string]string{},
+			expected: `unexpected reply to ruok: "ruok is not executed because it is not in the whitelist."`,
+		},
+		{
+			name:     "Server not serving requests",
+			replies:  map[string]string{"ruok": "imok", "mntr": "This ZooKeeper instance is not currently serving requests\n"},
+			expected: `unexpected reply to mntr: "This ZooKeeper instance is not currently serving requests"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ln := startFakeZooKeeperServer(t, tt.replies)
+
+			mockEnv := func(key string) string {
+				env := map[string]string{
+					envZooKeeperModes: tt.modes,
+				}
+				return env[key]
+			}
+
+			checker, err := NewZooKeeperChecker("example", ln.Addr().String(), 1*time.Second, mockEnv)
+			if err != nil {
+				t.Fatalf("failed to create ZooKeeperChecker: %q", err)
+			}
+
+			err = checker.Check(context.Background())
+			if tt.expected == "" {
+				if err != nil {
+					t.Fatalf("expected no error, got %q", err)
+				}
+				return
+			}
+
+			if err == nil {
+				t.Fatal("expected an error, got none")
+			}
+			if err.Error() != tt.expected {
+				t.Errorf("expected error %q, got %q", tt.expected, err.Error())
+			}
+		})
+	}
+}
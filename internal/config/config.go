@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"net/url"
+	"path"
 	"strconv"
 	"strings"
 	"time"
@@ -17,11 +18,13 @@ const (
 	envCheckInterval   string = "CHECK_INTERVAL"
 	envDialTimeout     string = "DIAL_TIMEOUT"
 	envLogExtraFields  string = "LOG_EXTRA_FIELDS"
+	envTraceAfter      string = "TRACE_AFTER"
 
 	defaultTargetCheckType checker.CheckType = checker.TCP
 	defaultCheckInterval   time.Duration     = 2 * time.Second
 	defaultDialTimeout     time.Duration     = 1 * time.Second
 	defaultLogExtraFields  bool              = false
+	defaultTraceAfter      time.Duration     = 0 // Never trace
 )
 
 // Config holds the required environment variables.
@@ -29,10 +32,11 @@ type Config struct {
 	Version         string            // The version of the application.
 	TargetName      string            // The name of the target.
 	TargetAddress   string            // The address of the target.
-	TargetCheckType checker.CheckType // Type of check: "tcp", "http" or "icmp".
+	TargetCheckType checker.CheckType // Type of check: "tcp", "http", "icmp", "udp", "tls", etc.
 	CheckInterval   time.Duration     // The interval between connection attempts.
 	DialTimeout     time.Duration     // The timeout for dialing the target.
 	LogExtraFields  bool              // Whether to log the fields in the log message.
+	TraceAfter      time.Duration     // The time the target must be unreachable before the path to it is traced. If 0, it is never traced.
 }
 
 // ParseConfig retrieves and parses the required environment variables.
@@ -45,12 +49,36 @@ func ParseConfig(getEnv func(string) string) (Config, error) {
 		CheckInterval:   defaultCheckInterval,
 		DialTimeout:     defaultDialTimeout,
 		LogExtraFields:  defaultLogExtraFields,
+		TraceAfter:      defaultTraceAfter,
 	}
 
 	if cfg.TargetAddress == "" {
 		return Config{}, fmt.Errorf("%s environment variable is required", envTargetAddress)
 	}
 
+	// Resolve TargetCheckType
+	if err := resolveTargetCheckType(&cfg, getEnv); err != nil {
+		return Config{}, err
+	}
+
+	if cfg.TargetName == "" {
+		switch cfg.TargetCheckType {
+		case checker.EXEC:
+			// Commands are not URLs, so the name of the program is used instead, split like the checker does
+			command, err := checker.SplitCommandLine(strings.TrimPrefix(cfg.TargetAddress, "exec://"))
+			if err != nil {
+				return Config{}, fmt.Errorf("could not extract command from target address: %w", err)
+			}
+			if len(command) == 0 {
+				return Config{}, fmt.Errorf("could not extract command from target address: %s", cfg.TargetAddress)
+			}
+			cfg.TargetName = path.Base(command[0])
+		case checker.K8S:
+			// The kind would be taken as hostname, so the whole resource is used instead
+			cfg.TargetName = strings.TrimPrefix(cfg.TargetAddress, "k8s://")
+		}
+	}
+
 	if cfg.TargetName == "" {
 		address := cfg.TargetAddress
 		if !strings.Contains(address, "://") {
@@ -64,6 +92,9 @@ func ParseConfig(getEnv func(string) string) (Config, error) {
 		}
 
 		hostname := parsedURL.Hostname() // Extract the hostname
+		if hostname == "" {
+			hostname = parsedURL.Path // Addresses like "unix:///var/run/app.sock" only have a path
+		}
 		if hostname == "" {
 			return Config{}, fmt.Errorf("could not extract hostname from target address: %s", cfg.TargetAddress)
 		}
@@ -98,9 +129,13 @@ func ParseConfig(getEnv func(string) string) (Config, error) {
 		cfg.LogExtraFields = logExtraFields
 	}
 
-	// Resolve TargetCheckType
-	if err := resolveTargetCheckType(&cfg, getEnv); err != nil {
-		return Config{}, err
+	// Parse the time after which the path to the target is traced
+	if traceAfterStr := getEnv(envTraceAfter); traceAfterStr != "" {
+		traceAfter, err := time.ParseDuration(traceAfterStr)
+		if err != nil || traceAfter < 0 {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envTraceAfter, traceAfterStr)
+		}
+		cfg.TraceAfter = traceAfter
 	}
 
 	return cfg, nil
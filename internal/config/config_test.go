@@ -108,6 +108,7 @@ func TestParseConfig(t *testing.T) {
 				envTargetCheckType: "tcp",
 				envCheckInterval:   "5s",
 				envDialTimeout:     "10s",
+				envTraceAfter:      "1m",
 			}
 			return env[key]
 		}
@@ -123,6 +124,7 @@ func TestParseConfig(t *testing.T) {
 			TargetCheckType: checker.TCP,
 			CheckInterval:   5 * time.Second,
 			DialTimeout:     10 * time.Second,
+			TraceAfter:      1 * time.Minute,
 		}
 		if !reflect.DeepEqual(cfg, expected) {
 			t.Fatalf("expected config %+v, got %+v", expected, cfg)
@@ -212,6 +214,173 @@ func TestParseConfig(t *testing.T) {
 		}
 	})
 
+	t.Run("Invalid trace after (negative)", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envTargetAddress: "tcp://example.com:80",
+				envTraceAfter:    "-1m",
+			}
+			return env[key]
+		}
+
+		_, err := ParseConfig(mockEnv)
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := fmt.Sprintf("invalid %s value: -1m", envTraceAfter)
+		if err.Error() != expected {
+			t.Fatalf("expected error to contain %q, got %q", expected, err)
+		}
+	})
+
+	t.Run("Valid config with unix socket", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envTargetAddress: "unix:///var/run/app.sock",
+			}
+			return env[key]
+		}
+
+		cfg, err := ParseConfig(mockEnv)
+		if err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+
+		expected := Config{
+			TargetName:      "/var/run/app.sock", // Extracted from the path of TargetAddress
+			TargetAddress:   "unix:///var/run/app.sock",
+			TargetCheckType: checker.UNIX,
+			CheckInterval:   2 * time.Second,
+			DialTimeout:     1 * time.Second,
+		}
+		if !reflect.DeepEqual(cfg, expected) {
+			t.Fatalf("expected config %+v, got %+v", expected, cfg)
+		}
+	})
+
+	t.Run("Valid config with command", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envTargetAddress:   "/usr/bin/pg_isready -h db -p 5432",
+				envTargetCheckType: "exec",
+			}
+			return env[key]
+		}
+
+		cfg, err := ParseConfig(mockEnv)
+		if err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+
+		expected := Config{
+			TargetName:      "pg_isready", // Extracted from the program of TargetAddress
+			TargetAddress:   "/usr/bin/pg_isready -h db -p 5432",
+			TargetCheckType: checker.EXEC,
+			CheckInterval:   2 * time.Second,
+			DialTimeout:     1 * time.Second,
+		}
+		if !reflect.DeepEqual(cfg, expected) {
+			t.Fatalf("expected config %+v, got %+v", expected, cfg)
+		}
+	})
+
+	t.Run("Valid config with quoted command", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envTargetAddress: `exec://"/opt/my tool/check" --ready`,
+			}
+			return env[key]
+		}
+
+		cfg, err := ParseConfig(mockEnv)
+		if err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+
+		expected := "check"
+		if cfg.TargetName != expected {
+			t.Fatalf("expected TargetName to be %q, got %q", expected, cfg.TargetName)
+		}
+	})
+
+	t.Run("Valid config with kubernetes resource", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envTargetAddress: "k8s://prod/deployment/api",
+			}
+			return env[key]
+		}
+
+		cfg, err := ParseConfig(mockEnv)
+		if err != nil {
+			t.Fatalf("expected no error, got %q", err)
+		}
+
+		expected := Config{
+			TargetName:      "prod/deployment/api", // Extracted from the resource of TargetAddress
+			TargetAddress:   "k8s://prod/deployment/api",
+			TargetCheckType: checker.K8S,
+			CheckInterval:   2 * time.Second,
+			DialTimeout:     1 * time.Second,
+		}
+		if !reflect.DeepEqual(cfg, expected) {
+			t.Fatalf("expected config %+v, got %+v", expected, cfg)
+		}
+	})
+
+	t.Run("Invalid command (empty command)", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envTargetAddress: "exec://",
+			}
+			return env[key]
+		}
+
+		_, err := ParseConfig(mockEnv)
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := "could not extract command from target address: exec://"
+		if err.Error() != expected {
+			t.Fatalf("expected error to contain %q, got %q", expected, err)
+		}
+	})
+
+	t.Run("Invalid command (unterminated quote)", func(t *testing.T) {
+		t.Parallel()
+
+		mockEnv := func(key string) string {
+			env := map[string]string{
+				envTargetAddress: `exec://"/opt/my tool/check`,
+			}
+			return env[key]
+		}
+
+		_, err := ParseConfig(mockEnv)
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+
+		expected := "could not extract command from target address: unterminated \" quote"
+		if err.Error() != expected {
+			t.Fatalf("expected error to contain %q, got %q", expected, err)
+		}
+	})
+
 	t.Run("Invalid address (invalid address)", func(t *testing.T) {
 		t.Parallel()
 
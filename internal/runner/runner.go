@@ -10,17 +10,25 @@ import (
 )
 
 // LoopUntilReady continuously attempts to connect to the specified target until it becomes available or the context is canceled.
-func LoopUntilReady(ctx context.Context, interval time.Duration, checker checker.Checker, logger *slog.Logger) error {
+// If the target is still not ready after traceAfter, the path to it is traced once, if the checker supports it.
+func LoopUntilReady(ctx context.Context, interval, traceAfter time.Duration, checker checker.Checker, logger *slog.Logger) error {
 	logger.Info(fmt.Sprintf("Waiting for %s to become ready...", checker))
 
+	start := time.Now()
+	traced := false
 	for {
 		err := checker.Check(ctx)
 		if err == nil {
-			logger.Info(fmt.Sprintf("%s is ready ✓", checker))
+			logger.Info(fmt.Sprintf("%s is ready ✓", checker), statsArgs(checker)...)
 			return nil // Successfully connected to the target
 		}
 
-		logger.Warn(fmt.Sprintf("%s is not ready ✗", checker), slog.String("error", err.Error()))
+		logger.Warn(fmt.Sprintf("%s is not ready ✗", checker), append([]any{slog.String("error", err.Error())}, statsArgs(checker)...)...)
+
+		if traceAfter > 0 && !traced && time.Since(start) >= traceAfter {
+			traced = true
+			logTrace(ctx, checker, logger)
+		}
 
 		select {
 		case <-time.After(interval):
@@ -33,3 +41,66 @@ func LoopUntilReady(ctx context.Context, interval time.Duration, checker checker
 		}
 	}
 }
+
+// statsArgs returns the statistics of the last check as logger arguments, if the checker collects any.
+func statsArgs(c checker.Checker) []any {
+	reporter, ok := c.(checker.StatsReporter)
+	if !ok {
+		return nil
+	}
+
+	var args []any
+	for _, attr := range reporter.Stats() {
+		args = append(args, attr)
+	}
+
+	return args
+}
+
+// logTrace traces the path to the target and logs the hosts answering, so it is visible where packets stop.
+func logTrace(ctx context.Context, c checker.Checker, logger *slog.Logger) {
+	tracer, ok := c.(checker.Tracer)
+	if !ok {
+		return
+	}
+
+	logger.Info(fmt.Sprintf("Tracing the path to %s...", c))
+
+	hops, err := tracer.Trace(ctx)
+	for _, hop := range hops {
+		if hop.Address == "" {
+			logger.Info("Hop", slog.Int("ttl", hop.TTL), slog.String("address", "*"))
+			continue
+		}
+
+		args := []any{slog.Int("ttl", hop.TTL), slog.String("address", hop.Address), slog.Duration("rtt", hop.RTT)}
+		if hop.Error != "" {
+			args = append(args, slog.String("reason", hop.Error))
+		}
+		logger.Info("Hop", args...)
+	}
+
+	if err != nil {
+		logger.Warn(fmt.Sprintf("Failed to trace the path to %s", c), slog.String("error", err.Error()))
+		return
+	}
+
+	// Find the last hop which answered
+	var last *checker.Hop
+	for i := range hops {
+		if hops[i].Address != "" {
+			last = &hops[i]
+		}
+	}
+
+	switch {
+	case last == nil:
+		logger.Warn(fmt.Sprintf("No hop on the path to %s answered", c))
+	case last.Reached:
+		logger.Info(fmt.Sprintf("Path to %s reaches the target after %d hops", c, last.TTL))
+	case last.Error != "":
+		logger.Warn(fmt.Sprintf("Path to %s stops at hop %d (%s): %s", c, last.TTL, last.Address, last.Error))
+	default:
+		logger.Warn(fmt.Sprintf("Path to %s stops after hop %d (%s)", c, last.TTL, last.Address))
+	}
+}
@@ -64,7 +64,7 @@ func TestLoopUntilReadyHTTP(t *testing.T) {
 		ctx, cancel := context.WithTimeout(context.Background(), cfg.CheckInterval*4)
 		defer cancel()
 
-		err = LoopUntilReady(ctx, cfg.CheckInterval, checker, logger)
+		err = LoopUntilReady(ctx, cfg.CheckInterval, cfg.TraceAfter, checker, logger)
 		if err != nil {
 			t.Errorf("Unexpected error: %q", err)
 		}
@@ -114,7 +114,7 @@ func TestLoopUntilReadyHTTP(t *testing.T) {
 		ctx, cancel := context.WithTimeout(context.Background(), cfg.CheckInterval*4)
 		defer cancel()
 
-		err = LoopUntilReady(ctx, cfg.CheckInterval, checker, logger)
+		err = LoopUntilReady(ctx, cfg.CheckInterval, cfg.TraceAfter, checker, logger)
 		if err != nil {
 			t.Errorf("Unexpected error: %q", err)
 		}
@@ -196,7 +196,7 @@ func TestLoopUntilReadyHTTP(t *testing.T) {
 		var stdOut strings.Builder
 		logger := logger.SetupLogger(cfg, &stdOut)
 
-		err = LoopUntilReady(ctx, cfg.CheckInterval, checker, logger)
+		err = LoopUntilReady(ctx, cfg.CheckInterval, cfg.TraceAfter, checker, logger)
 		if err != nil {
 			t.Errorf("Unexpected error: %q", err)
 		}
@@ -310,7 +310,7 @@ func TestLoopUntilReadyHTTP(t *testing.T) {
 		var stdOut strings.Builder
 		logger := logger.SetupLogger(cfg, &stdOut)
 
-		err = LoopUntilReady(ctx, cfg.CheckInterval, checker, logger)
+		err = LoopUntilReady(ctx, cfg.CheckInterval, cfg.TraceAfter, checker, logger)
 		if err != nil {
 			t.Errorf("Unexpected error: %q", err)
 		}
@@ -396,7 +396,7 @@ func TestLoopUntilReadyHTTP(t *testing.T) {
 			cancel()
 		}()
 
-		err = LoopUntilReady(ctx, cfg.CheckInterval, checker, logger)
+		err = LoopUntilReady(ctx, cfg.CheckInterval, cfg.TraceAfter, checker, logger)
 		if err != nil && err != context.Canceled {
 			t.Errorf("Expected context canceled error, got %q", err)
 		}
@@ -433,7 +433,7 @@ func TestLoopUntilReadyTCP(t *testing.T) {
 			TargetCheckType: checker.TCP,
 		}
 
-		checker, err := checker.NewTCPChecker(cfg.TargetName, cfg.TargetAddress, cfg.DialTimeout)
+		checker, err := checker.NewTCPChecker(cfg.TargetName, cfg.TargetAddress, cfg.DialTimeout, func(string) string { return "" })
 		if err != nil {
 			t.Fatalf("Failed to create TCPChecker: %q", err)
 		}
@@ -444,7 +444,7 @@ func TestLoopUntilReadyTCP(t *testing.T) {
 		ctx, cancel := context.WithTimeout(context.Background(), cfg.CheckInterval*4)
 		defer cancel()
 
-		err = LoopUntilReady(ctx, cfg.CheckInterval, checker, logger)
+		err = LoopUntilReady(ctx, cfg.CheckInterval, cfg.TraceAfter, checker, logger)
 		if err != nil {
 			t.Errorf("Unexpected error: %q", err)
 		}
@@ -471,7 +471,7 @@ func TestLoopUntilReadyTCP(t *testing.T) {
 			DialTimeout:   50 * time.Millisecond,
 		}
 
-		checker, err := checker.NewTCPChecker(cfg.TargetName, cfg.TargetAddress, cfg.DialTimeout)
+		checker, err := checker.NewTCPChecker(cfg.TargetName, cfg.TargetAddress, cfg.DialTimeout, func(string) string { return "" })
 		if err != nil {
 			t.Fatalf("Failed to create TCPChecker: %q", err)
 		}
@@ -482,7 +482,7 @@ func TestLoopUntilReadyTCP(t *testing.T) {
 		ctx, cancel := context.WithTimeout(context.Background(), cfg.CheckInterval*4)
 		defer cancel()
 
-		err = LoopUntilReady(ctx, cfg.CheckInterval, checker, logger)
+		err = LoopUntilReady(ctx, cfg.CheckInterval, cfg.TraceAfter, checker, logger)
 		if err != nil {
 			t.Errorf("Unexpected error: %q", err)
 		}
@@ -529,7 +529,7 @@ func TestLoopUntilReadyTCP(t *testing.T) {
 		ctx, cancel := context.WithTimeout(context.Background(), cfg.CheckInterval*4)
 		defer cancel()
 
-		checker, err := checker.NewTCPChecker(cfg.TargetName, cfg.TargetAddress, cfg.DialTimeout)
+		checker, err := checker.NewTCPChecker(cfg.TargetName, cfg.TargetAddress, cfg.DialTimeout, func(string) string { return "" })
 		if err != nil {
 			t.Fatalf("Failed to create HTTPChecker: %q", err)
 		}
@@ -537,7 +537,7 @@ func TestLoopUntilReadyTCP(t *testing.T) {
 		var stdOut strings.Builder
 		logger := logger.SetupLogger(cfg, &stdOut)
 
-		err = LoopUntilReady(ctx, cfg.CheckInterval, checker, logger)
+		err = LoopUntilReady(ctx, cfg.CheckInterval, cfg.TraceAfter, checker, logger)
 		if err != nil {
 			t.Errorf("Unexpected error: %q", err)
 		}
@@ -601,7 +601,7 @@ func TestLoopUntilReadyTCP(t *testing.T) {
 			DialTimeout:   50 * time.Millisecond,
 		}
 
-		checker, err := checker.NewTCPChecker(cfg.TargetName, cfg.TargetAddress, cfg.DialTimeout)
+		checker, err := checker.NewTCPChecker(cfg.TargetName, cfg.TargetAddress, cfg.DialTimeout, func(string) string { return "" })
 		if err != nil {
 			t.Fatalf("Failed to create TCPChecker: %q", err)
 		}
@@ -616,7 +616,7 @@ func TestLoopUntilReadyTCP(t *testing.T) {
 			cancel()
 		}()
 
-		err = LoopUntilReady(ctx, cfg.CheckInterval, checker, logger)
+		err = LoopUntilReady(ctx, cfg.CheckInterval, cfg.TraceAfter, checker, logger)
 		if err != nil && err != context.Canceled {
 			t.Errorf("Expected context canceled error, got %q", err)
 		}
@@ -643,7 +643,7 @@ func TestLoopUntilReadyTCP(t *testing.T) {
 			TargetCheckType: checker.TCP,
 		}
 
-		checker, err := checker.NewTCPChecker(cfg.TargetName, cfg.TargetAddress, cfg.DialTimeout)
+		checker, err := checker.NewTCPChecker(cfg.TargetName, cfg.TargetAddress, cfg.DialTimeout, func(string) string { return "" })
 		if err != nil {
 			t.Fatalf("Failed to create TCPChecker: %q", err)
 		}
@@ -654,7 +654,7 @@ func TestLoopUntilReadyTCP(t *testing.T) {
 		ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(50*time.Millisecond))
 		defer cancel() // Ensure cancel is called to free resources
 
-		err = LoopUntilReady(ctx, cfg.CheckInterval, checker, logger)
+		err = LoopUntilReady(ctx, cfg.CheckInterval, cfg.TraceAfter, checker, logger)
 		if err != context.DeadlineExceeded {
 			t.Errorf("Expected context canceled error, got %q", err)
 		}
@@ -753,3 +753,129 @@ func TestICMPChecker_Check_SuccessfulICMPCheck(t *testing.T) {
 		}
 	})
 }
+
+// statsChecker is a checker which fails a given number of times and reports the attempt as statistics.
+type statsChecker struct {
+	failures int
+	attempt  int
+}
+
+func (c *statsChecker) Check(ctx context.Context) error {
+	c.attempt++
+	if c.attempt <= c.failures {
+		return fmt.Errorf("attempt %d failed", c.attempt)
+	}
+	return nil
+}
+
+func (c *statsChecker) String() string {
+	return "StatsChecker"
+}
+
+func (c *statsChecker) Stats() []slog.Attr {
+	return []slog.Attr{slog.Int("attempt", c.attempt)}
+}
+
+func TestLoopUntilReadyStats(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Statistics are logged", func(t *testing.T) {
+		t.Parallel()
+
+		var stdOut strings.Builder
+		logger := slog.New(slog.NewTextHandler(&stdOut, nil))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+
+		err := LoopUntilReady(ctx, 10*time.Millisecond, 0, &statsChecker{failures: 1}, logger)
+		if err != nil {
+			t.Fatalf("Unexpected error: %q", err)
+		}
+
+		expected := `msg="StatsChecker is not ready ✗" error="attempt 1 failed" attempt=1`
+		if !strings.Contains(stdOut.String(), expected) {
+			t.Errorf("Expected output to contain %q but got %q", expected, stdOut.String())
+		}
+
+		expected = `msg="StatsChecker is ready ✓" attempt=2`
+		if !strings.Contains(stdOut.String(), expected) {
+			t.Errorf("Expected output to contain %q but got %q", expected, stdOut.String())
+		}
+	})
+}
+
+// traceChecker is a checker which is never ready and traces a path stopping after the first hop.
+type traceChecker struct {
+	traces int
+}
+
+func (c *traceChecker) Check(ctx context.Context) error {
+	return fmt.Errorf("connection timed out")
+}
+
+func (c *traceChecker) String() string {
+	return "TraceChecker"
+}
+
+func (c *traceChecker) Trace(ctx context.Context) ([]checker.Hop, error) {
+	c.traces++
+	return []checker.Hop{
+		{TTL: 1, Address: "192.0.2.1", RTT: time.Millisecond},
+		{TTL: 2},
+		{TTL: 3},
+		{TTL: 4},
+	}, nil
+}
+
+func TestLoopUntilReadyTrace(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Path is traced once", func(t *testing.T) {
+		t.Parallel()
+
+		var stdOut strings.Builder
+		logger := slog.New(slog.NewTextHandler(&stdOut, nil))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+
+		checker := &traceChecker{}
+		err := LoopUntilReady(ctx, 10*time.Millisecond, 50*time.Millisecond, checker, logger)
+		if err != context.DeadlineExceeded {
+			t.Fatalf("Expected deadline exceeded, got %v", err)
+		}
+
+		if checker.traces != 1 {
+			t.Errorf("Expected the path to be traced once, got %d", checker.traces)
+		}
+
+		for _, expected := range []string{
+			`msg="Tracing the path to TraceChecker..."`,
+			`msg=Hop ttl=1 address=192.0.2.1 rtt=1ms`,
+			`msg=Hop ttl=2 address=*`,
+			`msg="Path to TraceChecker stops after hop 1 (192.0.2.1)"`,
+		} {
+			if !strings.Contains(stdOut.String(), expected) {
+				t.Errorf("Expected output to contain %q but got %q", expected, stdOut.String())
+			}
+		}
+	})
+
+	t.Run("Path is not traced", func(t *testing.T) {
+		t.Parallel()
+
+		var stdOut strings.Builder
+		logger := slog.New(slog.NewTextHandler(&stdOut, nil))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		checker := &traceChecker{}
+		_ = LoopUntilReady(ctx, 10*time.Millisecond, 0, checker, logger)
+
+		if checker.traces != 0 {
+			t.Errorf("Expected the path not to be traced, got %d traces", checker.traces)
+		}
+	})
+}
@@ -13,6 +13,7 @@ type MockProtocol struct {
 	NetworkFunc       func() string
 	ListenPacketFunc  func(ctx context.Context, network, address string) (net.PacketConn, error)
 	SetDeadlineFunc   func(t time.Time) error
+	SetTTLFunc        func(ttl int) error
 }
 
 // MakeRequest is a mock implementation of the Protocol.MakeRequest method.
@@ -55,6 +56,14 @@ func (m *MockProtocol) SetDeadline(t time.Time) error {
 	return nil
 }
 
+// SetTTL is a mock implementation of the Protocol.SetTTL method.
+func (m *MockProtocol) SetTTL(ttl int) error {
+	if m.SetTTLFunc != nil {
+		return m.SetTTLFunc(ttl)
+	}
+	return nil
+}
+
 // MockPacketConn is a mock implementation of net.PacketConn for testing purposes.
 type MockPacketConn struct {
 	SetDeadlineFunc      func(t time.Time) error